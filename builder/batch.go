@@ -0,0 +1,228 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/quailyquaily/bizdocgen/core"
+)
+
+type (
+	// BatchJob is one invoice of a batch. A job with Err set failed to load and is reported as
+	// failed without being rendered.
+	BatchJob struct {
+		// Source describes where the params came from, e.g. "invoices/a.yaml" or "month.yaml#3".
+		Source string
+		Params *core.InvoiceParams
+		Err    error
+	}
+
+	BatchOptions struct {
+		// Workers bounds the number of documents rendered concurrently (default: runtime.NumCPU()).
+		Workers int
+		// OutputDir, when set, receives each PDF as Filename; BatchResult.PDF is then left nil.
+		OutputDir string
+	}
+
+	BatchResult struct {
+		Source string
+		ID     string
+		// Filename is derived from the invoice ID ("<id>.pdf"), made unique within the batch.
+		Filename string
+		PDF      []byte
+		Err      error
+	}
+)
+
+var batchExtensions = map[string]bool{".yaml": true, ".yml": true, ".json": true, ".toml": true}
+
+// LoadInvoiceBatchJobs expands inputs into batch jobs. Each input is a directory (every
+// .yaml/.yml/.json/.toml file in it), a glob pattern, or a file; YAML files may hold several
+// "---" separated invoices. Relative company_seal paths are resolved against each file. Files that
+// fail to load become jobs with Err set, so one bad file does not stop the batch.
+func LoadInvoiceBatchJobs(inputs ...string) ([]BatchJob, error) {
+	var files []string
+	for _, input := range inputs {
+		matches, err := expandBatchInput(input)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+
+	jobs := make([]BatchJob, 0, len(files))
+	for _, filename := range files {
+		jobs = append(jobs, loadBatchFile(filename)...)
+	}
+	return jobs, nil
+}
+
+func expandBatchInput(input string) ([]string, error) {
+	info, err := os.Stat(input)
+	if err == nil && info.IsDir() {
+		entries, err := os.ReadDir(input)
+		if err != nil {
+			return nil, err
+		}
+		var files []string
+		for _, entry := range entries {
+			if entry.IsDir() || !batchExtensions[strings.ToLower(filepath.Ext(entry.Name()))] {
+				continue
+			}
+			files = append(files, filepath.Join(input, entry.Name()))
+		}
+		return files, nil
+	}
+	if err == nil {
+		return []string{input}, nil
+	}
+
+	matches, globErr := filepath.Glob(input)
+	if globErr != nil {
+		return nil, fmt.Errorf("invalid batch input %q: %w", input, globErr)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("batch input %q matches no files", input)
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+func loadBatchFile(filename string) []BatchJob {
+	fd, err := os.Open(filename)
+	if err != nil {
+		return []BatchJob{{Source: filename, Err: err}}
+	}
+	defer fd.Close()
+
+	list, err := core.LoadInvoiceParamsStream(filename, fd)
+	jobs := make([]BatchJob, 0, len(list)+1)
+	for ix, params := range list {
+		source := filename
+		if len(list) > 1 || err != nil {
+			source = fmt.Sprintf("%s#%d", filename, ix+1)
+		}
+		params.CompanySeal = resolveBatchPath(filename, params.CompanySeal)
+		jobs = append(jobs, BatchJob{Source: source, Params: params})
+	}
+	if err != nil {
+		jobs = append(jobs, BatchJob{Source: fmt.Sprintf("%s#%d", filename, len(list)+1), Err: err})
+	}
+	return jobs
+}
+
+func resolveBatchPath(inputPath, maybeRelativePath string) string {
+	if maybeRelativePath == "" || filepath.IsAbs(maybeRelativePath) {
+		return maybeRelativePath
+	}
+	return filepath.Clean(filepath.Join(filepath.Dir(inputPath), maybeRelativePath))
+}
+
+// GenerateInvoiceBatch renders jobs concurrently with at most opts.Workers documents in flight.
+// Results are returned in job order, one per job; a failed document only fails its own result.
+// Jobs not started before ctx is done fail with ctx.Err().
+func GenerateInvoiceBatch(ctx context.Context, cfg Config, jobs []BatchJob, opts BatchOptions) []BatchResult {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	results := make([]BatchResult, len(jobs))
+	filenames := batchFilenames(jobs)
+	for ix, job := range jobs {
+		results[ix] = BatchResult{Source: job.Source, Filename: filenames[ix]}
+		if job.Params != nil {
+			results[ix].ID = job.Params.ID
+		}
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ix := range indexes {
+				if err := ctx.Err(); err != nil {
+					results[ix].Err = err
+					continue
+				}
+				results[ix].PDF, results[ix].Err = renderBatchJob(cfg, jobs[ix], opts.OutputDir, filenames[ix])
+			}
+		}()
+	}
+	for ix := range jobs {
+		indexes <- ix
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+func renderBatchJob(cfg Config, job BatchJob, outputDir, filename string) ([]byte, error) {
+	if job.Err != nil {
+		return nil, job.Err
+	}
+	if job.Params == nil {
+		return nil, fmt.Errorf("invoice params are nil")
+	}
+
+	bd, err := NewInvoiceBuilder(cfg, job.Params)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := bd.GenerateInvoice()
+	if err != nil {
+		return nil, err
+	}
+	if outputDir == "" {
+		return buf, nil
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, filename), buf, 0o666); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// batchFilenames derives "<id>.pdf" for every job, replacing characters that are unsafe in file
+// names and suffixing duplicates ("<id>-2.pdf"). Jobs without an ID use their position.
+func batchFilenames(jobs []BatchJob) []string {
+	used := make(map[string]bool, len(jobs))
+	filenames := make([]string, len(jobs))
+	for ix, job := range jobs {
+		base := ""
+		if job.Params != nil {
+			base = sanitizeBatchFilename(job.Params.ID)
+		}
+		if base == "" {
+			base = fmt.Sprintf("invoice-%d", ix+1)
+		}
+		name := base + ".pdf"
+		for n := 2; used[strings.ToLower(name)]; n++ {
+			name = fmt.Sprintf("%s-%d.pdf", base, n)
+		}
+		used[strings.ToLower(name)] = true
+		filenames[ix] = name
+	}
+	return filenames
+}
+
+func sanitizeBatchFilename(id string) string {
+	var sb strings.Builder
+	for _, r := range strings.TrimSpace(id) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('_')
+		}
+	}
+	return strings.Trim(sb.String(), ".")
+}
@@ -0,0 +1,83 @@
+package builder
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateInvoiceBatchFromStreamAndDir(t *testing.T) {
+	sample, err := os.ReadFile("../samples/invoice-1.yaml")
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	dir := t.TempDir()
+	stream := strings.Join([]string{
+		string(sample),
+		strings.Replace(string(sample), `id: "20240210-SAMPLE"`, `id: "2024/02 #2"`, 1),
+		string(sample),
+	}, "\n---\n")
+	if err := os.WriteFile(filepath.Join(dir, "month.yaml"), []byte(stream), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("id: [\n"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, err := LoadInvoiceBatchJobs(dir)
+	if err != nil {
+		t.Fatalf("LoadInvoiceBatchJobs: %v", err)
+	}
+	if len(jobs) != 4 {
+		t.Fatalf("len(jobs) = %d, want 4", len(jobs))
+	}
+
+	outDir := t.TempDir()
+	results := GenerateInvoiceBatch(context.Background(), Config{}, jobs, BatchOptions{Workers: 2, OutputDir: outDir})
+
+	wantFiles := []string{"", "20240210-SAMPLE.pdf", "2024_02__2.pdf", "20240210-SAMPLE-2.pdf"}
+	for ix, result := range results {
+		if ix == 0 {
+			if result.Err == nil || !strings.HasPrefix(result.Source, filepath.Join(dir, "broken.yaml")) {
+				t.Fatalf("results[0] = %+v, want a load failure for broken.yaml", result)
+			}
+			continue
+		}
+		if result.Err != nil {
+			t.Fatalf("results[%d] (%s): %v", ix, result.Source, result.Err)
+		}
+		if result.Filename != wantFiles[ix] {
+			t.Fatalf("results[%d].Filename = %q, want %q", ix, result.Filename, wantFiles[ix])
+		}
+		if _, err := os.Stat(filepath.Join(outDir, result.Filename)); err != nil {
+			t.Fatalf("output for %s: %v", result.Source, err)
+		}
+	}
+}
+
+func TestGenerateInvoiceBatchHonorsContext(t *testing.T) {
+	jobs, err := LoadInvoiceBatchJobs("../samples/invoice-[12].yaml")
+	if err != nil {
+		t.Fatalf("LoadInvoiceBatchJobs: %v", err)
+	}
+	if len(jobs) != 2 {
+		t.Fatalf("len(jobs) = %d, want 2", len(jobs))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, result := range GenerateInvoiceBatch(ctx, Config{}, jobs, BatchOptions{}) {
+		if result.Err != context.Canceled {
+			t.Fatalf("result.Err = %v, want context.Canceled", result.Err)
+		}
+	}
+
+	if _, err := LoadInvoiceBatchJobs("../samples/nothing-*.yaml"); err == nil {
+		t.Fatal("LoadInvoiceBatchJobs with no matches succeeded")
+	}
+}
@@ -3,12 +3,14 @@ package builder
 import (
 	"log"
 	"log/slog"
+	"time"
 
 	"github.com/johnfercher/maroto/v2/pkg/components/page"
 	marotoCore "github.com/johnfercher/maroto/v2/pkg/core"
 	"github.com/johnfercher/maroto/v2/pkg/props"
 	"github.com/quailyquaily/bizdocgen/core"
 	"github.com/quailyquaily/bizdocgen/i18n"
+	"github.com/shopspring/decimal"
 )
 
 type (
@@ -20,44 +22,121 @@ type (
 		FontBoldItalic string
 
 		Lang string
+		// Locale selects how amounts are written, e.g. "de-DE" for "500.000,00 €". Empty uses Lang.
+		Locale string
 
 		// Layout names: "classic" (default), "modern", "compact".
 		InvoiceLayout             string
 		SettlementStatementLayout string
+		CreditNoteLayout          string
+		QuoteLayout               string
+		PurchaseOrderLayout       string
+		DeliveryNoteLayout        string
+
+		// Compliance enables jurisdiction-specific rules, e.g. ComplianceJPQualifiedInvoice.
+		// Empty disables them.
+		Compliance string
+
+		// ValidateParams makes the Generate* methods refuse params that fail Validate(),
+		// returning the core.ValidationErrors instead of rendering a document.
+		ValidateParams bool
+
+		// OmitRevenueStamp drops the revenue stamp (収入印紙) box from yen receipts over the stamp
+		// duty threshold, e.g. for receipts only delivered electronically, which carry no duty.
+		OmitRevenueStamp bool
+
+		// MarkOverdue shows an overdue marker on invoices rendered after their due date that are not
+		// paid in full. Now is the rendering time it compares with; zero means time.Now().
+		MarkOverdue bool
+		Now         time.Time
+
+		// Rounding is the rounding mode of computed amounts: core.RoundHalfUp (default),
+		// core.RoundHalfEven or core.RoundDown. Qualified invoices round taxes down by default.
+		Rounding string
+		// RoundingLevel is RoundPerDocument (default), taxing each rate's total once, or
+		// RoundPerLine, rounding the tax of every line and adding up the rounded taxes.
+		RoundingLevel string
+		// CurrencyDecimals overrides the decimals amounts are rounded to, keyed by currency code,
+		// e.g. {"CHF": 2, "USDT": 6}. By default the ISO 4217 minor units are used.
+		CurrencyDecimals map[string]int32
+
+		// ExchangeRates computes the reference amounts in a quote currency (total_include_tax_quote_symbol)
+		// entered without an amount, at the rate of the document date, e.g. a *core.ExchangeRateTable.
+		ExchangeRates core.ExchangeRateProvider
+
+		// SummaryCheck cross-checks the summary totals with the detail items billed in the summary
+		// currency: SummaryCheckDerive computes the summary from the items, SummaryCheckWarn logs
+		// totals differing by more than SummaryTolerance, and SummaryCheckFail makes the Generate*
+		// methods refuse them with core.ValidationErrors. Empty uses the summary as entered. When
+		// set, it replaces the exact comparison ValidateParams makes with the detail items.
+		SummaryCheck     string
+		SummaryTolerance decimal.Decimal
+
+		// FacturXProfile makes GenerateInvoice produce a Factur-X / ZUGFeRD PDF/A-3 with the
+		// CII XML embedded: "minimum" or "en16931". Empty produces a plain PDF. PDF/A-3 requires
+		// embedded fonts, so FontNormal and FontBold must be set.
+		FacturXProfile string
 	}
 
 	Builder struct {
 		cfg              Config
 		i18nBundle       *i18n.I18nBundle
 		iParams          *core.InvoiceParams
+		validateParams   func() error
 		Round            int32
-		docLabelSet      string
+		kind             *documentKind
 		fgColor          *props.Color
 		fgSecondaryColor *props.Color
 		fgTertiaryColor  *props.Color
 		borderColor      *props.Color
+
+		// reference, if set, replaces the period line in the header.
+		reference *documentReference
+		// quote is set for quotes only.
+		quote *core.QuoteParams
+		// hidePrices drops amounts and summaries from the layouts, e.g. for delivery notes.
+		hidePrices bool
+		// exchangeRates holds the rates looked up from Config.ExchangeRates; see exchangeRateKey.
+		exchangeRates map[string]core.ExchangeRate
 	}
 )
 
 func NewInvoiceBuilder(cfg Config, params *core.InvoiceParams) (*Builder, error) {
+	if err := validateCompliance(cfg, params); err != nil {
+		return nil, err
+	}
+	if err := validateRounding(cfg); err != nil {
+		return nil, err
+	}
+	if err := validateSummaryCheck(cfg); err != nil {
+		return nil, err
+	}
+	if err := validatePaymentTerms(params); err != nil {
+		return nil, err
+	}
+	exchangeRates, err := resolveExchangeRates(cfg, params)
+	if err != nil {
+		return nil, err
+	}
 	i18nBundle := i18n.New()
 	if cfg.Lang == "" {
 		cfg.Lang = "en"
-	}
-	round := 2
-	if params.Currency == "JPY" || params.Currency == "円" {
-		round = 0
+		if normalizeCompliance(cfg.Compliance) == ComplianceJPQualifiedInvoice {
+			cfg.Lang = "ja"
+		}
 	}
 	return &Builder{
 		cfg:              cfg,
 		i18nBundle:       i18nBundle,
 		iParams:          params,
-		Round:            int32(round),
-		docLabelSet:      labelSetInvoice,
+		validateParams:   paramsValidator(cfg, params.Validate, params.ValidateWithoutSummaryCheck),
+		Round:            currencyDecimals(cfg, params.Currency),
+		kind:             kindInvoice,
 		fgColor:          &props.Color{Red: 50, Green: 50, Blue: 93},
 		fgSecondaryColor: &props.Color{Red: 80, Green: 80, Blue: 123},
 		fgTertiaryColor:  &props.Color{Red: 120, Green: 120, Blue: 153},
 		borderColor:      &props.Color{Red: 210, Green: 210, Blue: 230},
+		exchangeRates:    exchangeRates,
 	}, nil
 }
 
@@ -70,7 +149,19 @@ func NewInvoiceBuilderFromFile(cfg Config, filename string) (*Builder, error) {
 }
 
 func (b *Builder) GenerateInvoice() ([]byte, error) {
-	layout := InvoiceLayoutByName(b.cfg.InvoiceLayout)
+	if err := b.checkParams(); err != nil {
+		return nil, err
+	}
+	layout, err := InvoiceLayoutByName(b.cfg.InvoiceLayout)
+	if err != nil {
+		log.Printf("failed to select invoice layout: %v\n", err)
+		return nil, err
+	}
+	attachment, err := b.facturXAttachment()
+	if err != nil {
+		log.Printf("failed to build Factur-X XML: %v\n", err)
+		return nil, err
+	}
 	headers, body, err := layout.Build(b)
 	if err != nil {
 		log.Printf("failed to build invoice layout %q: %v\n", layout.Name(), err)
@@ -98,7 +189,22 @@ func (b *Builder) GenerateInvoice() ([]byte, error) {
 
 	m.AddPages(newPage)
 
-	return b.getBytesFromMaroto(m)
+	pdf, err := b.getBytesFromMaroto(m)
+	if err != nil || attachment == nil {
+		return pdf, err
+	}
+	return b.embedFacturX(pdf, attachment)
+}
+
+// checkParams validates the source params when Config.ValidateParams is set and cross-checks
+// the summary with the detail items as Config.SummaryCheck asks.
+func (b *Builder) checkParams() error {
+	if b.cfg.ValidateParams && b.validateParams != nil {
+		if err := b.validateParams(); err != nil {
+			return err
+		}
+	}
+	return b.checkInvoiceSummary()
 }
 
 func (b *Builder) getBytesFromMaroto(maroto marotoCore.Maroto) ([]byte, error) {
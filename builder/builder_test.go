@@ -1,10 +1,46 @@
 package builder
 
 import (
+	"fmt"
 	"os"
 	"testing"
+	"time"
+
+	"github.com/johnfercher/go-tree/node"
+	marotoCore "github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/quailyquaily/bizdocgen/core"
 )
 
+// testInvoiceParams returns a USD invoice of 2024-05-10 with a copy of items and a summary that
+// only has a title; tests set the amounts they check.
+func testInvoiceParams(items ...core.InvoiceDetailItem) *core.InvoiceParams {
+	return &core.InvoiceParams{
+		ID:          "INV-1",
+		Date:        time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC),
+		Currency:    "USD",
+		Summary:     core.InvoiceSummary{Title: "Test"},
+		DetailItems: append([]core.InvoiceDetailItem(nil), items...),
+	}
+}
+
+// rowTexts returns the values of the text components in rows, in order.
+func rowTexts(rows []marotoCore.Row) []string {
+	var texts []string
+	var walk func(n *node.Node[marotoCore.Structure])
+	walk = func(n *node.Node[marotoCore.Structure]) {
+		if data := n.GetData(); data.Type == "text" {
+			texts = append(texts, fmt.Sprint(data.Value))
+		}
+		for _, next := range n.GetNexts() {
+			walk(next)
+		}
+	}
+	for _, r := range rows {
+		walk(r.GetStructure())
+	}
+	return texts
+}
+
 // TestHelloName calls greetings.Hello with a name, checking
 // for a valid return value.
 func TestGenerateInvoice(t *testing.T) {
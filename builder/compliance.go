@@ -0,0 +1,75 @@
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	marotoCore "github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+	"github.com/quailyquaily/bizdocgen/core"
+)
+
+const (
+	// ComplianceJPQualifiedInvoice renders Japanese Qualified Invoices (適格請求書, インボイス制度):
+	// the "T" + 13 digit registration number is required, consumption tax is computed once per rate
+	// (rounded down) instead of per line, and the per-rate totals and reduced-rate marks are always shown.
+	ComplianceJPQualifiedInvoice = "jp_qualified_invoice"
+
+	jpReducedRateMark = "※"
+)
+
+func validateCompliance(cfg Config, params *core.InvoiceParams) error {
+	switch normalizeCompliance(cfg.Compliance) {
+	case "":
+		return nil
+	case ComplianceJPQualifiedInvoice:
+		if err := core.ValidateJPRegistrationNumber(params.TaxNumber); err != nil {
+			return fmt.Errorf("jp qualified invoice: tax_number: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown compliance mode %q", cfg.Compliance)
+	}
+}
+
+func normalizeCompliance(name string) string {
+	return strings.ReplaceAll(strings.TrimSpace(strings.ToLower(name)), "-", "_")
+}
+
+func (b *Builder) jpQualifiedInvoice() bool {
+	return normalizeCompliance(b.cfg.Compliance) == ComplianceJPQualifiedInvoice
+}
+
+// isJPReducedRateItem reports whether a detail item is taxed below the highest rate on the document,
+// i.e. it needs the reduced-rate mark on a qualified invoice.
+func (b *Builder) isJPReducedRateItem(item core.InvoiceDetailItem, breakdown []invoiceTaxBreakdown) bool {
+	if !b.jpQualifiedInvoice() || len(breakdown) < 2 {
+		return false
+	}
+	category, rate := b.invoiceItemTax(item)
+	return category == core.TaxCategoryStandard && rate.IsPositive() && rate.LessThan(breakdown[0].Rate)
+}
+
+func (b *Builder) buildJPReducedRateNoteRows(breakdown []invoiceTaxBreakdown) []marotoCore.Row {
+	hasReduced := false
+	for _, item := range b.iParams.DetailItems {
+		if b.isJPReducedRateItem(item, breakdown) {
+			hasReduced = true
+			break
+		}
+	}
+	if !hasReduced {
+		return nil
+	}
+	tNote := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceReducedRateNote", map[string]any{"Mark": jpReducedRateMark})
+	return []marotoCore.Row{
+		row.New(6).Add(
+			col.New(2),
+			text.NewCol(10, tNote, props.Text{Size: 8, Top: 0, Align: align.Left, Color: b.fgSecondaryColor}),
+		),
+	}
+}
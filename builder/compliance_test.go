@@ -0,0 +1,108 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+func TestJPQualifiedInvoiceRequiresRegistrationNumber(t *testing.T) {
+	cfg := Config{Compliance: ComplianceJPQualifiedInvoice}
+	for _, number := range []string{"", "1234567890123", "T123456789012", "T12345678901234", "T123456789012X"} {
+		if _, err := NewInvoiceBuilder(cfg, &core.InvoiceParams{TaxNumber: number}); err == nil {
+			t.Fatalf("NewInvoiceBuilder(%q) succeeded, want error", number)
+		}
+	}
+	b, err := NewInvoiceBuilder(cfg, &core.InvoiceParams{TaxNumber: "T1234567890123"})
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+	if b.cfg.Lang != "ja" {
+		t.Fatalf("Lang = %q, want %q", b.cfg.Lang, "ja")
+	}
+	if _, err := NewInvoiceBuilder(Config{Compliance: "nope"}, &core.InvoiceParams{}); err == nil {
+		t.Fatal("unknown compliance mode accepted")
+	}
+}
+
+func TestJPQualifiedInvoiceRoundsOncePerRate(t *testing.T) {
+	item := core.InvoiceDetailItem{
+		UnitPrice: decimal.NewFromInt(333),
+		TaxRate:   decimal.New(8, -2),
+		Tax:       decimal.NewFromInt(27), // per-line rounding, ignored in compliance mode
+	}
+	params := &core.InvoiceParams{
+		TaxNumber:   "T1234567890123",
+		Currency:    "JPY",
+		DetailItems: []core.InvoiceDetailItem{item, item, item},
+	}
+
+	b, err := NewInvoiceBuilder(Config{}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+	if nums := b.invoiceSummaryNumbers(); !nums.Tax.Equal(decimal.NewFromInt(81)) {
+		t.Fatalf("default mode Tax = %s, want 81", nums.Tax)
+	}
+
+	b, err = NewInvoiceBuilder(Config{Compliance: ComplianceJPQualifiedInvoice}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+	nums := b.invoiceSummaryNumbers()
+	if !nums.Subtotal.Equal(decimal.NewFromInt(999)) || !nums.Tax.Equal(decimal.NewFromInt(79)) || !nums.Total.Equal(decimal.NewFromInt(1078)) {
+		t.Fatalf("summary = %s + %s = %s, want 999 + 79 = 1078", nums.Subtotal, nums.Tax, nums.Total)
+	}
+}
+
+func TestJPQualifiedInvoiceSingleSummaryRate(t *testing.T) {
+	params := &core.InvoiceParams{}
+	if err := params.Load("../samples/invoice-2.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	params.TaxNumber = "T1234567890123"
+	params.CompanySeal = "../sample-seal.png"
+
+	b, err := NewInvoiceBuilder(Config{Compliance: ComplianceJPQualifiedInvoice}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+	nums := b.invoiceSummaryNumbers()
+	if len(nums.TaxBreakdown) != 1 || !nums.TaxBreakdown[0].Tax.Equal(decimal.NewFromInt(50000)) {
+		t.Fatalf("TaxBreakdown = %+v, want a single 10%% group with tax 50000", nums.TaxBreakdown)
+	}
+	if got := b.invoiceTaxRateLabel(nums.TaxBreakdown[0]); got != "10%対象" {
+		t.Fatalf("rate label = %q, want %q", got, "10%対象")
+	}
+	if _, err := b.GenerateInvoice(); err != nil {
+		t.Fatalf("GenerateInvoice: %v", err)
+	}
+}
+
+func TestJPQualifiedInvoiceMarksReducedRateItems(t *testing.T) {
+	params := &core.InvoiceParams{}
+	if err := params.Load("../samples/invoice-4.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	b, err := NewInvoiceBuilder(Config{Compliance: ComplianceJPQualifiedInvoice}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+	breakdown := b.invoiceSummaryNumbers().TaxBreakdown
+	if !b.isJPReducedRateItem(params.DetailItems[0], breakdown) {
+		t.Fatal("8% item not marked as reduced rate")
+	}
+	if b.isJPReducedRateItem(params.DetailItems[2], breakdown) {
+		t.Fatal("10% item marked as reduced rate")
+	}
+	if rows := b.buildJPReducedRateNoteRows(breakdown); len(rows) != 1 {
+		t.Fatalf("len(note rows) = %d, want 1", len(rows))
+	}
+	for _, layout := range BuiltinLayoutNames() {
+		b.cfg.InvoiceLayout = layout
+		if _, err := b.GenerateInvoice(); err != nil {
+			t.Fatalf("GenerateInvoice(%s): %v", layout, err)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package builder
+
+import (
+	"log"
+
+	"github.com/johnfercher/maroto/v2/pkg/components/page"
+	"github.com/quailyquaily/bizdocgen/core"
+)
+
+func NewCreditNoteBuilder(cfg Config, params *core.CreditNoteParams) (*Builder, error) {
+	builder, err := NewInvoiceBuilder(cfg, invoiceParamsFromCreditNote(params))
+	if err != nil {
+		return nil, err
+	}
+	builder.kind = kindCreditNote
+	builder.reference = &documentReference{
+		labelKey: "CreditNoteOriginalInvoice",
+		value:    dateReference(params.OriginalInvoice.ID, params.OriginalInvoice.Date),
+	}
+	builder.validateParams = paramsValidator(cfg, params.Validate, params.ValidateWithoutSummaryCheck)
+	return builder, nil
+}
+
+func NewCreditNoteBuilderFromFile(cfg Config, filename string) (*Builder, error) {
+	params := &core.CreditNoteParams{}
+	if err := params.Load(filename); err != nil {
+		return nil, err
+	}
+	return NewCreditNoteBuilder(cfg, params)
+}
+
+func (b *Builder) GenerateCreditNote() ([]byte, error) {
+	if err := b.checkParams(); err != nil {
+		return nil, err
+	}
+	layout, err := InvoiceLayoutByName(b.cfg.CreditNoteLayout)
+	if err != nil {
+		log.Printf("failed to select credit note layout: %v\n", err)
+		return nil, err
+	}
+	headers, body, err := layout.Build(b)
+	if err != nil {
+		log.Printf("failed to build credit note layout %q: %v\n", layout.Name(), err)
+		return nil, err
+	}
+
+	m, err := b.CreateMetricsDecorator(headers)
+	if err != nil {
+		log.Printf("failed to register header: %v\n", err)
+		return nil, err
+	}
+
+	footer, err := b.BuildInvoiceFooter()
+	if err != nil {
+		log.Printf("failed to build credit note footer: %v\n", err)
+		return nil, err
+	}
+	if err := m.RegisterFooter(footer...); err != nil {
+		log.Printf("failed to register footer: %v\n", err)
+		return nil, err
+	}
+
+	newPage := page.New()
+	newPage.Add(body...)
+
+	m.AddPages(newPage)
+
+	return b.getBytesFromMaroto(m)
+}
+
+// invoiceParamsFromCreditNote maps a credit note onto invoice params with the credited amounts
+// negated, so the invoice layouts render them as reversals. Quantities stay positive; the unit
+// price carries the sign. The refund record is kept as entered and the reason becomes the hint.
+func invoiceParamsFromCreditNote(params *core.CreditNoteParams) *core.InvoiceParams {
+	summary := params.Summary
+	summary.TotalExcludeTax = summary.TotalExcludeTax.Neg()
+	summary.TotalIncludeTax = summary.TotalIncludeTax.Neg()
+	summary.TotalIncludeTaxQuoteAmount = summary.TotalIncludeTaxQuoteAmount.Neg()
+	summary.TotalIncludeTaxJPY = summary.TotalIncludeTaxJPY.Neg()
+	summary.Tax = summary.Tax.Neg()
+	summary.Adjustments = negateAdjustments(summary.Adjustments)
+	summary.Withholding.Amount = summary.Withholding.Amount.Neg()
+
+	items := make([]core.InvoiceDetailItem, len(params.DetailItems))
+	for ix, item := range params.DetailItems {
+		item.UnitPrice = item.UnitPrice.Neg()
+		item.Discount = item.Discount.Neg()
+		item.TotalExcludeTax = item.TotalExcludeTax.Neg()
+		item.TotalIncludeTax = item.TotalIncludeTax.Neg()
+		item.TotalIncludeTaxQuoteAmount = item.TotalIncludeTaxQuoteAmount.Neg()
+		item.Tax = item.Tax.Neg()
+		item.Adjustments = negateAdjustments(item.Adjustments)
+		items[ix] = item
+	}
+
+	doc := params.Doc
+	if doc.Description == "" {
+		doc.Description = params.Reason
+	}
+	result := core.InvoicePaymentResult(params.Payment.CreditNotePaymentResult)
+	result.Disabled = !shouldShowSettlementPaymentResult(result)
+	return &core.InvoiceParams{
+		ID:              params.ID,
+		TaxNumber:       params.TaxNumber,
+		Date:            params.Date,
+		Currency:        params.Currency,
+		CompanyName:     params.CompanyName,
+		CompanyAddr:     params.CompanyAddr,
+		CompanyEmail:    params.CompanyEmail,
+		CompanySeal:     params.CompanySeal,
+		CompanyCountry:  params.CompanyCountry,
+		CompanyEndpoint: params.CompanyEndpoint,
+		BillToCompany:   params.BillToCompany,
+		BillToAddress:   params.BillToAddress,
+		BillToCountry:   params.BillToCountry,
+		BillToEndpoint:  params.BillToEndpoint,
+		BillToTaxNumber: params.BillToTaxNumber,
+		BuyerReference:  params.BuyerReference,
+		Summary:         summary,
+		DetailItems:     items,
+		Payment: core.InvoicePayment{
+			InvoicePaymentInstruction: core.InvoicePaymentInstruction{Disabled: true},
+			InvoicePaymentResult:      result,
+		},
+		Doc: doc,
+	}
+}
+
+// negateAdjustments copies adjustments with fixed amounts negated. Percentages keep their sign, as
+// they apply to the negated amounts.
+func negateAdjustments(adjustments []core.InvoiceAdjustment) []core.InvoiceAdjustment {
+	if len(adjustments) == 0 {
+		return nil
+	}
+	negated := make([]core.InvoiceAdjustment, len(adjustments))
+	for ix, adj := range adjustments {
+		adj.Amount = adj.Amount.Neg()
+		negated[ix] = adj
+	}
+	return negated
+}
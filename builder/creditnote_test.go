@@ -0,0 +1,81 @@
+package builder
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+func TestGenerateCreditNote(t *testing.T) {
+	for _, layout := range BuiltinLayoutNames() {
+		b, err := NewCreditNoteBuilderFromFile(Config{CreditNoteLayout: layout, ValidateParams: true}, "../samples/creditnote-1.yaml")
+		if err != nil {
+			t.Fatalf("NewCreditNoteBuilderFromFile: %v", err)
+		}
+		buf, err := b.GenerateCreditNote()
+		if err != nil || !bytes.HasPrefix(buf, []byte("%PDF")) {
+			t.Fatalf("GenerateCreditNote(%s) = %v", layout, err)
+		}
+	}
+}
+
+func TestCreditNoteRendersNegativeAmounts(t *testing.T) {
+	params := &core.CreditNoteParams{}
+	if err := params.Load("../samples/creditnote-1.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	b, err := NewCreditNoteBuilder(Config{}, params)
+	if err != nil {
+		t.Fatalf("NewCreditNoteBuilder: %v", err)
+	}
+
+	nums := b.invoiceSummaryNumbers()
+	if !nums.Subtotal.Equal(decimal.NewFromInt(-450)) || !nums.Tax.Equal(decimal.NewFromFloat(-85.5)) || !nums.Total.Equal(decimal.NewFromFloat(-535.5)) {
+		t.Fatalf("summary = %s + %s = %s, want -450 + -85.5 = -535.5", nums.Subtotal, nums.Tax, nums.Total)
+	}
+	if got := b.iParams.DetailItems[0]; !got.Quantity.Equal(decimal.NewFromInt(1)) || !got.NetAmount().Equal(decimal.NewFromInt(-450)) {
+		t.Fatalf("detail item = %s × %s, want 1 × -450", got.Quantity, got.UnitPrice)
+	}
+	if params.Summary.TotalExcludeTax.IsNegative() || params.DetailItems[0].UnitPrice.IsNegative() {
+		t.Fatal("NewCreditNoteBuilder modified the credit note params")
+	}
+
+	if got := b.invoiceDocTitle(); got != "Credit Note" {
+		t.Fatalf("title = %q, want Credit Note", got)
+	}
+	if got := b.invoiceDocHint(); got != params.Reason {
+		t.Fatalf("hint = %q, want the reason %q", got, params.Reason)
+	}
+	if got := b.invoiceHeaderLastLine(""); got != "Original Invoice: 20240415-EU (2024/04/15)" {
+		t.Fatalf("header reference = %q", got)
+	}
+	if got := b.i18nBundle.MusT("en", b.labelKey("InvoicePaymentResult"), nil); got != "Refund" {
+		t.Fatalf("payment result label = %q, want Refund", got)
+	}
+
+	b.cfg.Lang = "ja"
+	if got := b.invoiceDocTitle(); got != "クレジットノート" {
+		t.Fatalf("ja title = %q, want クレジットノート", got)
+	}
+}
+
+func TestGenerateCreditNoteRejectsInvalidParams(t *testing.T) {
+	params := &core.CreditNoteParams{}
+	if err := params.Load("../samples/creditnote-1.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	params.OriginalInvoice.ID = ""
+	b, err := NewCreditNoteBuilder(Config{ValidateParams: true}, params)
+	if err != nil {
+		t.Fatalf("NewCreditNoteBuilder: %v", err)
+	}
+	_, err = b.GenerateCreditNote()
+	var errs core.ValidationErrors
+	if !errors.As(err, &errs) || !strings.Contains(err.Error(), "original_invoice.id") {
+		t.Fatalf("GenerateCreditNote() = %v, want a validation error for original_invoice.id", err)
+	}
+}
@@ -0,0 +1,95 @@
+package builder
+
+import (
+	"log"
+
+	"github.com/johnfercher/maroto/v2/pkg/components/page"
+	"github.com/quailyquaily/bizdocgen/core"
+)
+
+func NewDeliveryNoteBuilder(cfg Config, params *core.DeliveryNoteParams) (*Builder, error) {
+	builder, err := NewInvoiceBuilder(cfg, invoiceParamsFromDeliveryNote(params))
+	if err != nil {
+		return nil, err
+	}
+	builder.kind = kindDeliveryNote
+	builder.reference = &documentReference{labelKey: "DeliveryNoteOrderReference", value: params.OrderReference}
+	builder.hidePrices = params.HidePrices
+	builder.validateParams = paramsValidator(cfg, params.Validate, params.ValidateWithoutSummaryCheck)
+	return builder, nil
+}
+
+func NewDeliveryNoteBuilderFromFile(cfg Config, filename string) (*Builder, error) {
+	params := &core.DeliveryNoteParams{}
+	if err := params.Load(filename); err != nil {
+		return nil, err
+	}
+	return NewDeliveryNoteBuilder(cfg, params)
+}
+
+func (b *Builder) GenerateDeliveryNote() ([]byte, error) {
+	if err := b.checkParams(); err != nil {
+		return nil, err
+	}
+	layout, err := InvoiceLayoutByName(b.cfg.DeliveryNoteLayout)
+	if err != nil {
+		log.Printf("failed to select delivery note layout: %v\n", err)
+		return nil, err
+	}
+	headers, body, err := layout.Build(b)
+	if err != nil {
+		log.Printf("failed to build delivery note layout %q: %v\n", layout.Name(), err)
+		return nil, err
+	}
+
+	m, err := b.CreateMetricsDecorator(headers)
+	if err != nil {
+		log.Printf("failed to register header: %v\n", err)
+		return nil, err
+	}
+
+	footer, err := b.BuildInvoiceFooter()
+	if err != nil {
+		log.Printf("failed to build delivery note footer: %v\n", err)
+		return nil, err
+	}
+	if err := m.RegisterFooter(footer...); err != nil {
+		log.Printf("failed to register footer: %v\n", err)
+		return nil, err
+	}
+
+	newPage := page.New()
+	newPage.Add(body...)
+
+	m.AddPages(newPage)
+
+	return b.getBytesFromMaroto(m)
+}
+
+// invoiceParamsFromDeliveryNote maps a delivery note onto invoice params: the deliver-to party
+// takes the bill-to party's place and there are no payment blocks.
+func invoiceParamsFromDeliveryNote(params *core.DeliveryNoteParams) *core.InvoiceParams {
+	return &core.InvoiceParams{
+		ID:              params.ID,
+		TaxNumber:       params.TaxNumber,
+		Date:            params.Date,
+		Currency:        params.Currency,
+		CompanyName:     params.CompanyName,
+		CompanyAddr:     params.CompanyAddr,
+		CompanyEmail:    params.CompanyEmail,
+		CompanySeal:     params.CompanySeal,
+		CompanyCountry:  params.CompanyCountry,
+		CompanyEndpoint: params.CompanyEndpoint,
+		BillToCompany:   params.DeliverToCompany,
+		BillToAddress:   params.DeliverToAddress,
+		BillToCountry:   params.DeliverToCountry,
+		BuyerReference:  params.OrderReference,
+		Summary:         params.Summary,
+		DetailItems:     params.DetailItems,
+		Payment: core.InvoicePayment{
+			InvoicePaymentInstruction: core.InvoicePaymentInstruction{Disabled: true},
+			InvoicePaymentResult:      core.InvoicePaymentResult{Disabled: true},
+		},
+		Doc: params.Doc,
+	}
+}
@@ -0,0 +1,69 @@
+package builder
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+func TestGenerateDeliveryNote(t *testing.T) {
+	for _, hidePrices := range []bool{true, false} {
+		for _, layout := range BuiltinLayoutNames() {
+			params := &core.DeliveryNoteParams{}
+			if err := params.Load("../samples/deliverynote-1.yaml"); err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if !hidePrices {
+				params.HidePrices = false
+				params.Currency = "JPY"
+				params.DetailItems[0].UnitPrice = decimal.RequireFromString("38000")
+				params.DetailItems[1].UnitPrice = decimal.RequireFromString("7500")
+			}
+			b, err := NewDeliveryNoteBuilder(Config{DeliveryNoteLayout: layout, ValidateParams: true}, params)
+			if err != nil {
+				t.Fatalf("NewDeliveryNoteBuilder: %v", err)
+			}
+			buf, err := b.GenerateDeliveryNote()
+			if err != nil || !bytes.HasPrefix(buf, []byte("%PDF")) {
+				t.Fatalf("GenerateDeliveryNote(%s, hide_prices=%v) = %v", layout, hidePrices, err)
+			}
+		}
+	}
+}
+
+func TestDeliveryNoteHidesPrices(t *testing.T) {
+	b, err := NewDeliveryNoteBuilderFromFile(Config{}, "../samples/deliverynote-1.yaml")
+	if err != nil {
+		t.Fatalf("NewDeliveryNoteBuilderFromFile: %v", err)
+	}
+	if got := b.invoiceDocTitle(); got != "Delivery Note" {
+		t.Fatalf("title = %q, want Delivery Note", got)
+	}
+	if got := b.invoiceHeaderLastLine(""); got != "Your Order: PO-20240405-01" {
+		t.Fatalf("header order reference = %q", got)
+	}
+	if rows := b.BuildInvoiceSummaryRows(); len(rows) != 0 {
+		t.Fatalf("summary rendered %d rows with hidden prices", len(rows))
+	}
+	if rows := b.BuildInvoiceTaxBreakdownRows(); len(rows) != 0 {
+		t.Fatalf("tax breakdown rendered %d rows with hidden prices", len(rows))
+	}
+	if b.showInvoicePaymentInstructions() || b.showInvoicePaymentResult() {
+		t.Fatal("delivery note shows payment blocks")
+	}
+
+	// A line giving only a unit price counts as one unit, as in the totals.
+	b.iParams.DetailItems[1].Quantity = decimal.Zero
+	b.iParams.DetailItems[1].UnitPrice = decimal.NewFromInt(7500)
+	if texts := rowTexts(b.BuildInvoiceDetailsRows()); !slices.Contains(texts, "1 pcs") {
+		t.Fatalf("detail texts = %q, want the quantity 1 pcs", texts)
+	}
+
+	b.reference.value = ""
+	if got := b.invoiceHeaderLastLine(""); got != "" {
+		t.Fatalf("header without order reference = %q, want empty", got)
+	}
+}
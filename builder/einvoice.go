@@ -0,0 +1,307 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/quailyquaily/bizdocgen/einvoice"
+	"github.com/quailyquaily/bizdocgen/facturx"
+	"github.com/quailyquaily/bizdocgen/ubl"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	vatIDPattern = regexp.MustCompile(`^[A-Z]{2}[0-9A-Za-z+*.]{2,13}$`)
+	ibanPattern  = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}$`)
+)
+
+// EInvoice maps the invoice onto the EN 16931 model using the same totals, tax breakdown and
+// rounding as the rendered PDF. The result is not validated; see einvoice.Invoice.Validate.
+// Other documents, credit notes included, are refused.
+func (b *Builder) EInvoice() (*einvoice.Invoice, error) {
+	if b.iParams == nil {
+		return nil, fmt.Errorf("invoice params are nil")
+	}
+	if b.kind != kindInvoice {
+		return nil, fmt.Errorf("e-invoice export supports invoices only, not %s documents", b.kind.name)
+	}
+	params := b.iParams
+	nums := b.invoiceSummaryNumbers()
+	currency := nums.BaseCurrency
+	if cur, ok := core.LookupCurrency(currency); ok {
+		currency = cur.Code
+	}
+
+	inv := &einvoice.Invoice{
+		ID:             params.ID,
+		IssueDate:      params.Date,
+		TypeCode:       einvoice.TypeCodeInvoice,
+		Currency:       currency,
+		BuyerReference: strings.TrimSpace(params.BuyerReference),
+		PeriodStart:    params.Summary.PeriodStart,
+		PeriodEnd:      params.Summary.PeriodEnd,
+		Seller: einvoice.Party{
+			Name:    params.CompanyName,
+			Address: einvoiceAddress(params.CompanyAddr, params.CompanyCountry),
+			Email:   params.CompanyEmail,
+		},
+		Buyer: einvoice.Party{
+			Name:    params.BillToCompany,
+			Address: einvoiceAddress(params.BillToAddress, params.BillToCountry),
+		},
+		Payment:      b.einvoicePaymentMeans(),
+		PaymentTerms: strings.TrimSpace(params.PaymentTerms),
+	}
+	dueDate, err := params.EffectiveDueDate()
+	if err != nil {
+		return nil, err
+	}
+	inv.DueDate = dueDate
+	// Without an explicit endpoint the seller is reachable by e-mail (EAS code EM).
+	inv.Seller.EndpointScheme, inv.Seller.EndpointID = einvoiceEndpoint(params.CompanyEndpoint)
+	if inv.Seller.EndpointID == "" && params.CompanyEmail != "" {
+		inv.Seller.EndpointScheme, inv.Seller.EndpointID = "EM", params.CompanyEmail
+	}
+	inv.Buyer.EndpointScheme, inv.Buyer.EndpointID = einvoiceEndpoint(params.BillToEndpoint)
+	if taxNumber := strings.TrimSpace(params.TaxNumber); vatIDPattern.MatchString(taxNumber) {
+		inv.Seller.VATID = taxNumber
+	} else {
+		inv.Seller.TaxRegistrationID = taxNumber
+	}
+	inv.Buyer.VATID = strings.TrimSpace(params.BillToTaxNumber)
+	for _, note := range []string{params.Summary.Title, params.Doc.Description} {
+		if note = strings.TrimSpace(note); note != "" {
+			inv.Notes = append(inv.Notes, note)
+		}
+	}
+
+	lineTotal := decimal.Zero
+	for ix, item := range params.DetailItems {
+		if b.invoiceItemCurrency(item) != nums.BaseCurrency {
+			return nil, fmt.Errorf("detail_items[%d]: currency %q differs from the invoice currency %q; e-invoices use a single currency",
+				ix, b.invoiceItemCurrency(item), nums.BaseCurrency)
+		}
+		line := b.einvoiceLine(ix, item)
+		lineTotal = lineTotal.Add(line.NetAmount)
+		inv.Lines = append(inv.Lines, line)
+	}
+
+	if !nums.Withholding.IsZero() {
+		return nil, fmt.Errorf("withholding tax cannot be expressed in an EN 16931 e-invoice")
+	}
+	allowances, charges := decimal.Zero, decimal.Zero
+	for _, adj := range nums.Adjustments {
+		if adj.Untaxed {
+			return nil, fmt.Errorf("summary adjustment %q is untaxed; e-invoices only carry taxed allowances and charges", adj.Label)
+		}
+		if adj.Amount.IsZero() {
+			continue
+		}
+		inv.AllowanceCharges = append(inv.AllowanceCharges, einvoice.AllowanceCharge{
+			Charge:      !adj.Discount,
+			Amount:      adj.Amount.Abs(),
+			Reason:      adj.Label,
+			TaxCategory: adj.Category,
+			TaxRate:     adj.Rate,
+		})
+		if adj.Discount {
+			allowances = allowances.Add(adj.Amount.Abs())
+		} else {
+			charges = charges.Add(adj.Amount.Abs())
+		}
+	}
+
+	breakdown := nums.TaxBreakdown
+	if len(breakdown) == 0 {
+		category, rate := b.invoiceItemTax(core.InvoiceDetailItem{})
+		breakdown = []invoiceTaxBreakdown{{Category: category, Rate: rate, Base: nums.Subtotal, Tax: nums.Tax}}
+	}
+	for _, group := range breakdown {
+		inv.TaxBreakdown = append(inv.TaxBreakdown, einvoice.TaxSubtotal{
+			Category:        group.Category,
+			Rate:            group.Rate,
+			Base:            group.Base,
+			Tax:             group.Tax,
+			ExemptionReason: b.invoiceTaxExemptionReason(group),
+		})
+	}
+
+	prepaid := decimal.Zero
+	if paid, _, ok := b.invoiceBalance(); ok && paid.IsPositive() {
+		prepaid = paid
+	}
+	inv.Totals = einvoice.Totals{
+		LineTotal:      lineTotal,
+		AllowanceTotal: allowances,
+		ChargeTotal:    charges,
+		TaxBasis:       nums.Subtotal,
+		TaxTotal:       nums.Tax,
+		GrandTotal:     nums.Total,
+		Prepaid:        prepaid,
+		DuePayable:     nums.Total.Sub(prepaid),
+	}
+	return inv, nil
+}
+
+// GenerateInvoiceUBL returns the invoice as UBL 2.1 XML following PEPPOL BIS Billing 3.0.
+// Invoices violating the EN 16931 or PEPPOL rules are refused with einvoice.RuleErrors.
+func (b *Builder) GenerateInvoiceUBL() ([]byte, error) {
+	if err := b.checkParams(); err != nil {
+		return nil, err
+	}
+	inv, err := b.EInvoice()
+	if err != nil {
+		log.Printf("failed to map invoice: %v\n", err)
+		return nil, err
+	}
+	return ubl.Marshal(inv)
+}
+
+// errFacturXFonts refuses Factur-X output with the PDF core fonts: PDF/A-3 requires every font
+// to be embedded, which only the TTF fonts of Config are.
+var errFacturXFonts = errors.New("factur-x: PDF/A-3 requires embedded fonts; set FontNormal and FontBold to TTF files")
+
+// facturXData is the validated CII XML GenerateInvoice embeds into the rendered PDF.
+type facturXData struct {
+	profile facturx.Profile
+	xml     []byte
+}
+
+// facturXAttachment builds the CII XML for Config.FacturXProfile before the PDF is rendered, so
+// that invoices failing the profile's business rules are rejected early. It returns nil when
+// Factur-X output is disabled.
+func (b *Builder) facturXAttachment() (*facturXData, error) {
+	if b.cfg.FacturXProfile == "" {
+		return nil, nil
+	}
+	profile, err := facturx.ParseProfile(b.cfg.FacturXProfile)
+	if err != nil {
+		return nil, err
+	}
+	if b.cfg.FontNormal == "" || b.cfg.FontBold == "" {
+		return nil, errFacturXFonts
+	}
+	inv, err := b.EInvoice()
+	if err != nil {
+		return nil, err
+	}
+	xml, err := facturx.CII(inv, profile)
+	if err != nil {
+		return nil, err
+	}
+	return &facturXData{profile: profile, xml: xml}, nil
+}
+
+func (b *Builder) embedFacturX(pdf []byte, data *facturXData) ([]byte, error) {
+	// A zero date is the rendering time; Config.Now makes the output reproducible.
+	meta := facturx.Metadata{
+		Title:   strings.TrimSpace(b.invoiceDocTitle() + " " + b.iParams.ID),
+		Author:  b.iParams.CompanyName,
+		Subject: b.iParams.Summary.Title,
+		Date:    b.cfg.Now,
+	}
+	out, err := facturx.Embed(pdf, data.xml, data.profile, meta)
+	if err != nil {
+		log.Printf("failed to embed Factur-X XML: %v\n", err)
+		return nil, err
+	}
+	return out, nil
+}
+
+func (b *Builder) einvoiceLine(ix int, item core.InvoiceDetailItem) einvoice.Line {
+	amounts := b.invoiceLineAmounts(item)
+	category, rate := b.invoiceItemTax(item)
+
+	quantity := amounts.Quantity
+	if quantity.IsZero() {
+		quantity = decimal.NewFromInt(1)
+	}
+	price := item.UnitPrice
+	if price.IsZero() {
+		price = amounts.ExcludeTax.Add(item.Discount).Div(quantity).Round(4)
+	}
+	name := strings.TrimSpace(item.Title)
+	if name == "" {
+		name = strings.TrimSpace(item.Desc)
+	}
+	allowance, charge := item.Discount, decimal.Zero
+	var chargeReasons []string
+	for ix, amount := range item.AdjustmentAmounts(b.rounding(b.invoiceItemCurrency(item))) {
+		switch {
+		case amount.IsNegative():
+			allowance = allowance.Sub(amount)
+		case amount.IsPositive():
+			charge = charge.Add(amount)
+			chargeReasons = append(chargeReasons, b.invoiceAdjustmentLabel(item.Adjustments[ix]))
+		}
+	}
+
+	return einvoice.Line{
+		ID:           fmt.Sprint(ix + 1),
+		Name:         name,
+		Description:  strings.TrimSpace(item.Desc),
+		Quantity:     quantity,
+		UnitCode:     einvoice.UnitCode(item.Unit),
+		NetPrice:     price,
+		Allowance:    allowance,
+		Charge:       charge,
+		ChargeReason: strings.Join(chargeReasons, "; "),
+		NetAmount:    amounts.ExcludeTax,
+		TaxCategory:  category,
+		TaxRate:      rate,
+	}
+}
+
+func (b *Builder) einvoicePaymentMeans() *einvoice.PaymentMeans {
+	if !b.showInvoicePaymentInstructions() {
+		return nil
+	}
+	instruction := b.iParams.Payment.InvoicePaymentInstruction
+	account := strings.ReplaceAll(strings.TrimSpace(instruction.ReceiveAccountNumber), " ", "")
+	switch {
+	case account != "":
+		means := &einvoice.PaymentMeans{
+			TypeCode:    einvoice.PaymentMeansCreditTransfer,
+			AccountID:   account,
+			AccountName: strings.TrimSpace(instruction.ReceiveAccountName),
+			BIC:         strings.TrimSpace(instruction.ReceiveAccountSwift),
+		}
+		if ibanPattern.MatchString(account) {
+			means.TypeCode = einvoice.PaymentMeansSEPACreditTransfer
+		}
+		return means
+	case instruction.ReceiveCryptoAddress != "":
+		info := strings.TrimSpace(strings.Join([]string{
+			instruction.ReceiveCryptoCurrency, instruction.ReceiveCryptoNetwork, instruction.ReceiveCryptoAddress,
+		}, " "))
+		if instruction.ReceiveCryptoMemo != "" {
+			info += " memo " + instruction.ReceiveCryptoMemo
+		}
+		return &einvoice.PaymentMeans{TypeCode: einvoice.PaymentMeansMutuallyDefined, Information: info}
+	default:
+		return nil
+	}
+}
+
+// einvoiceEndpoint splits a "<scheme>:<identifier>" electronic address.
+func einvoiceEndpoint(endpoint string) (scheme, id string) {
+	scheme, id, found := strings.Cut(strings.TrimSpace(endpoint), ":")
+	if !found {
+		return "", ""
+	}
+	return scheme, id
+}
+
+func einvoiceAddress(address, country string) einvoice.Address {
+	addr := einvoice.Address{CountryCode: strings.ToUpper(strings.TrimSpace(country))}
+	for _, line := range strings.Split(address, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			addr.Lines = append(addr.Lines, line)
+		}
+	}
+	return addr
+}
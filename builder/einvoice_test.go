@@ -0,0 +1,224 @@
+package builder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/validate"
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/quailyquaily/bizdocgen/einvoice"
+	"github.com/quailyquaily/bizdocgen/facturx"
+	"github.com/shopspring/decimal"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// facturXTestConfig renders with the Go fonts, as PDF/A-3 requires embedded fonts.
+func facturXTestConfig(t *testing.T, profile string) Config {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := Config{FacturXProfile: profile, FontName: "go"}
+	for path, ttf := range map[*string][]byte{&cfg.FontNormal: goregular.TTF, &cfg.FontBold: gobold.TTF} {
+		*path = filepath.Join(dir, fmt.Sprintf("font-%d.ttf", len(ttf)))
+		if err := os.WriteFile(*path, ttf, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return cfg
+}
+
+func TestEInvoice(t *testing.T) {
+	b, err := NewInvoiceBuilderFromFile(Config{}, "../samples/invoice-5.yaml")
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilderFromFile: %v", err)
+	}
+	inv, err := b.EInvoice()
+	if err != nil {
+		t.Fatalf("EInvoice: %v", err)
+	}
+	if err := inv.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if inv.Currency != "EUR" || inv.Seller.VATID != "DE123456789" || inv.Buyer.Address.CountryCode != "FR" {
+		t.Fatalf("header = %s %q %q", inv.Currency, inv.Seller.VATID, inv.Buyer.Address.CountryCode)
+	}
+	if len(inv.Lines) != 2 || inv.Lines[0].UnitCode != "HUR" || !inv.Lines[1].NetAmount.Equal(decimal.NewFromInt(800)) {
+		t.Fatalf("lines = %+v", inv.Lines)
+	}
+	if !inv.Totals.TaxTotal.Equal(decimal.NewFromInt(874)) || !inv.Totals.DuePayable.Equal(decimal.NewFromInt(5474)) {
+		t.Fatalf("totals = %+v", inv.Totals)
+	}
+	if inv.Seller.EndpointScheme != "0088" || inv.Buyer.EndpointID != "FR32123456789" || inv.BuyerReference != "PO-2024-0415" {
+		t.Fatalf("endpoints = %+v / %+v, buyer reference %q", inv.Seller, inv.Buyer, inv.BuyerReference)
+	}
+	if inv.Payment == nil || inv.Payment.TypeCode != einvoice.PaymentMeansSEPACreditTransfer || inv.Payment.AccountID != "DE89370400440532013000" {
+		t.Fatalf("payment = %+v", inv.Payment)
+	}
+}
+
+func TestGenerateInvoiceUBL(t *testing.T) {
+	b, err := NewInvoiceBuilderFromFile(Config{}, "../samples/invoice-5.yaml")
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilderFromFile: %v", err)
+	}
+	xml, err := b.GenerateInvoiceUBL()
+	if err != nil {
+		t.Fatalf("GenerateInvoiceUBL: %v", err)
+	}
+	if !bytes.Contains(xml, []byte("<cbc:BuyerReference>PO-2024-0415</cbc:BuyerReference>")) {
+		t.Fatalf("XML lacks the buyer reference:\n%s", xml)
+	}
+
+	// The Japanese sample has no electronic addresses.
+	b, err = NewInvoiceBuilderFromFile(Config{}, "../samples/invoice-1.yaml")
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilderFromFile: %v", err)
+	}
+	_, err = b.GenerateInvoiceUBL()
+	if err == nil || !strings.Contains(err.Error(), "PEPPOL-EN16931-R010") {
+		t.Fatalf("err = %v, want PEPPOL-EN16931-R010", err)
+	}
+}
+
+func TestEInvoiceRefusesOtherDocuments(t *testing.T) {
+	creditNote, err := NewCreditNoteBuilderFromFile(Config{}, "../samples/creditnote-1.yaml")
+	if err != nil {
+		t.Fatalf("NewCreditNoteBuilderFromFile: %v", err)
+	}
+	purchaseOrder, err := NewPurchaseOrderBuilderFromFile(Config{}, "../samples/purchaseorder-1.yaml")
+	if err != nil {
+		t.Fatalf("NewPurchaseOrderBuilderFromFile: %v", err)
+	}
+	for _, b := range []*Builder{creditNote, purchaseOrder} {
+		if _, err := b.EInvoice(); err == nil || !strings.Contains(err.Error(), b.kind.name) {
+			t.Errorf("EInvoice on a %s: err = %v, want a refusal", b.kind.name, err)
+		}
+	}
+}
+
+func TestGenerateInvoiceFacturX(t *testing.T) {
+	for _, profile := range []string{"minimum", "en16931"} {
+		t.Run(profile, func(t *testing.T) {
+			b, err := NewInvoiceBuilderFromFile(facturXTestConfig(t, profile), "../samples/invoice-5.yaml")
+			if err != nil {
+				t.Fatalf("NewInvoiceBuilderFromFile: %v", err)
+			}
+			pdf, err := b.GenerateInvoice()
+			if err != nil {
+				t.Fatalf("GenerateInvoice: %v", err)
+			}
+			if !bytes.HasPrefix(pdf, []byte("%PDF-1.7\n")) {
+				t.Fatalf("header = %q", pdf[:10])
+			}
+
+			ctx, err := pdfcpu.Read(bytes.NewReader(pdf), &model.Configuration{Reader15: true, ValidationMode: model.ValidationRelaxed})
+			if err != nil {
+				t.Fatalf("pdfcpu.Read: %v", err)
+			}
+			// Validation also binds the name trees the attachment lookup below relies on.
+			if err := validate.XRefTable(ctx.XRefTable); err != nil {
+				t.Fatalf("validate: %v", err)
+			}
+			catalog, err := ctx.Catalog()
+			if err != nil {
+				t.Fatalf("Catalog: %v", err)
+			}
+			for _, key := range []string{"AF", "Metadata", "OutputIntents", "Pages"} {
+				if _, ok := catalog.Find(key); !ok {
+					t.Fatalf("catalog has no /%s: %s", key, catalog)
+				}
+			}
+
+			attachments, err := ctx.ExtractAttachments(nil)
+			if err != nil || len(attachments) != 1 {
+				t.Fatalf("attachments = %v, %v", attachments, err)
+			}
+			if attachments[0].FileName != facturx.XMLFilename {
+				t.Fatalf("attachment name = %q", attachments[0].FileName)
+			}
+			xml, err := io.ReadAll(attachments[0])
+			if err != nil {
+				t.Fatalf("read attachment: %v", err)
+			}
+			if !bytes.Contains(xml, []byte("<ram:ID>20240415-EU</ram:ID>")) {
+				t.Fatalf("attachment does not carry the invoice:\n%s", xml)
+			}
+			hasLines := bytes.Contains(xml, []byte("IncludedSupplyChainTradeLineItem"))
+			if hasLines != (profile == "en16931") {
+				t.Fatalf("%s XML has lines = %v", profile, hasLines)
+			}
+		})
+	}
+}
+
+func TestGenerateInvoiceFacturXRejectsInvalidInvoices(t *testing.T) {
+	params := &core.InvoiceParams{}
+	if err := params.Load("../samples/invoice-5.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	params.CompanyCountry = ""
+	b, err := NewInvoiceBuilder(facturXTestConfig(t, "en16931"), params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+	_, err = b.GenerateInvoice()
+	var ruleErrs einvoice.RuleErrors
+	if !errors.As(err, &ruleErrs) || !strings.Contains(err.Error(), "BR-9") {
+		t.Fatalf("err = %v, want BR-9 violation", err)
+	}
+
+	b, err = NewInvoiceBuilder(Config{FacturXProfile: "extended"}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+	if _, err := b.GenerateInvoice(); err == nil {
+		t.Fatal("unknown profile was accepted")
+	}
+}
+
+func TestGenerateInvoiceFacturXRequiresEmbeddedFonts(t *testing.T) {
+	b, err := NewInvoiceBuilderFromFile(Config{FacturXProfile: "en16931"}, "../samples/invoice-5.yaml")
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilderFromFile: %v", err)
+	}
+	if _, err := b.GenerateInvoice(); !errors.Is(err, errFacturXFonts) {
+		t.Fatalf("GenerateInvoice with core fonts: err = %v, want errFacturXFonts", err)
+	}
+
+	cfg := facturXTestConfig(t, "en16931")
+	cfg.FontBold = filepath.Join(t.TempDir(), "missing.ttf")
+	b, err = NewInvoiceBuilderFromFile(cfg, "../samples/invoice-5.yaml")
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilderFromFile: %v", err)
+	}
+	if _, err := b.GenerateInvoice(); !errors.Is(err, errFacturXFonts) {
+		t.Fatalf("GenerateInvoice with a missing font: err = %v, want errFacturXFonts", err)
+	}
+}
+
+func TestGenerateInvoiceFacturXDatesFollowConfigNow(t *testing.T) {
+	cfg := facturXTestConfig(t, "minimum")
+	cfg.Now = time.Date(2024, 4, 15, 9, 0, 0, 0, time.UTC)
+	b, err := NewInvoiceBuilderFromFile(cfg, "../samples/invoice-5.yaml")
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilderFromFile: %v", err)
+	}
+	pdf, err := b.GenerateInvoice()
+	if err != nil {
+		t.Fatalf("GenerateInvoice: %v", err)
+	}
+	for _, want := range []string{"/CreationDate (D:20240415090000+00'00')", "<xmp:CreateDate>2024-04-15T09:00:00Z"} {
+		if !bytes.Contains(pdf, []byte(want)) {
+			t.Errorf("output has no %s", want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+// maxRateDecimals bounds the decimals of a looked-up rate in the reference amount note.
+const maxRateDecimals = 6
+
+// resolveExchangeRates looks up, on the document date, the rates of the quote currencies whose
+// reference amounts are left to Config.ExchangeRates: the summary's and the detail items'.
+func resolveExchangeRates(cfg Config, params *core.InvoiceParams) (map[string]core.ExchangeRate, error) {
+	if cfg.ExchangeRates == nil || params == nil {
+		return nil, nil
+	}
+	rates := make(map[string]core.ExchangeRate)
+	lookup := func(base, quote string) error {
+		base, quote = strings.TrimSpace(base), strings.TrimSpace(quote)
+		key := exchangeRateKey(base, quote)
+		if base == "" || quote == "" || sameCurrency(base, quote) {
+			return nil
+		}
+		if _, ok := rates[key]; ok {
+			return nil
+		}
+		rate, err := cfg.ExchangeRates.ExchangeRate(base, quote, params.Date)
+		if err != nil {
+			return fmt.Errorf("exchange rate: %w", err)
+		}
+		rates[key] = rate
+		return nil
+	}
+
+	summary := params.Summary
+	if summary.TotalIncludeTaxQuoteAmount.IsZero() && summary.TotalIncludeTaxJPY.IsZero() {
+		base := summary.Currency
+		if strings.TrimSpace(base) == "" {
+			base = params.Currency
+		}
+		if err := lookup(base, invoiceQuoteSymbol(summary)); err != nil {
+			return nil, err
+		}
+	}
+	for _, item := range params.DetailItems {
+		if !item.TotalIncludeTaxQuoteAmount.IsZero() {
+			continue
+		}
+		base := item.Currency
+		if strings.TrimSpace(base) == "" {
+			base = params.Currency
+		}
+		if err := lookup(base, item.TotalIncludeTaxQuoteSymbol); err != nil {
+			return nil, err
+		}
+	}
+	return rates, nil
+}
+
+// exchangeRateKey identifies a currency pair, resolving aliases such as "円".
+func exchangeRateKey(base, quote string) string {
+	return core.CurrencyCode(base) + "/" + core.CurrencyCode(quote)
+}
+
+// invoiceQuoteSymbol returns the summary's quote currency, accepting the misspelled alias.
+func invoiceQuoteSymbol(summary core.InvoiceSummary) string {
+	if symbol := strings.TrimSpace(summary.TotalIncludeTaxQuoteSymbol); symbol != "" {
+		return symbol
+	}
+	return strings.TrimSpace(summary.TotalIncludeTaxQuotaSymbol)
+}
+
+// invoiceQuote returns the reference amount of baseAmount in quoteSymbol: quoteAmount when entered,
+// otherwise converted at the looked-up rate, which is returned along.
+func (b *Builder) invoiceQuote(baseAmount decimal.Decimal, baseSymbol string, quoteAmount decimal.Decimal, quoteSymbol string) (decimal.Decimal, core.ExchangeRate) {
+	if !quoteAmount.IsZero() {
+		return quoteAmount, core.ExchangeRate{}
+	}
+	rate, ok := b.exchangeRates[exchangeRateKey(baseSymbol, quoteSymbol)]
+	if !ok || strings.TrimSpace(quoteSymbol) == "" {
+		return quoteAmount, core.ExchangeRate{}
+	}
+	return b.round(baseAmount.Mul(rate.Rate), quoteSymbol), rate
+}
+
+// invoiceQuoteRateSource returns the "(rate: MUFG TTM, 2024-04-30)" suffix of the reference
+// amount note, or an empty string when neither is known.
+func (b *Builder) invoiceQuoteRateSource(source string, date string) string {
+	parts := make([]string, 0, 2)
+	for _, part := range []string{source, date} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return b.i18nBundle.MusT(b.cfg.Lang, "InvoiceQuoteRateSource", map[string]any{"Source": strings.Join(parts, ", ")})
+}
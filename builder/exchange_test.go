@@ -0,0 +1,79 @@
+package builder
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+func TestInvoiceQuoteAmountFromExchangeRates(t *testing.T) {
+	table := &core.ExchangeRateTable{}
+	if err := table.Load("../samples/rates.csv"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	params := testInvoiceParams(
+		core.InvoiceDetailItem{Title: "Hosting", TotalIncludeTax: decimal.RequireFromString("40.5"), TotalIncludeTaxQuoteSymbol: "円"},
+		core.InvoiceDetailItem{Title: "Support", TotalIncludeTax: decimal.RequireFromString("59.5")},
+	)
+	params.Summary.TotalIncludeTax = decimal.NewFromInt(100)
+	params.Summary.TotalIncludeTaxQuoteSymbol = "JPY"
+	b, err := NewInvoiceBuilder(Config{Lang: "en", ExchangeRates: table}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+
+	nums := b.invoiceSummaryNumbers()
+	want := "※ Reference Amount: ¥15,125, 1 USD = 151.25 JPY (rate: MUFG TTM, 2024-04-30)"
+	if !nums.QuoteAmount.Equal(decimal.NewFromInt(15125)) || nums.QuoteText != want {
+		t.Fatalf("QuoteAmount = %s, QuoteText = %q; want 15125, %q", nums.QuoteAmount, nums.QuoteText, want)
+	}
+
+	item := b.iParams.DetailItems[0]
+	amount, rate := b.invoiceQuote(decimal.RequireFromString("40.5"), "USD", item.TotalIncludeTaxQuoteAmount, item.TotalIncludeTaxQuoteSymbol)
+	if !amount.Equal(decimal.NewFromInt(6126)) || rate.Source != "MUFG TTM" {
+		t.Fatalf("item quote = %s at %+v, want 6126 from MUFG TTM", amount, rate)
+	}
+}
+
+func TestInvoiceQuoteRateSourceFromParams(t *testing.T) {
+	params := testInvoiceParams()
+	params.Summary.TotalIncludeTax = decimal.NewFromInt(100)
+	params.Summary.TotalIncludeTaxQuoteSymbol = "JPY"
+	params.Summary.TotalIncludeTaxQuoteAmount = decimal.NewFromInt(15000)
+	params.Summary.TotalIncludeTaxQuoteRateSource = "Agreed rate"
+	params.Summary.TotalIncludeTaxQuoteRateDate = time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	b, err := NewInvoiceBuilder(Config{Lang: "ja"}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+	want := "※ 参考金額：約¥15,000。1 USD=150JPYで換算（レート：Agreed rate, 2024-05-01）"
+	if got := b.invoiceSummaryNumbers().QuoteText; got != want {
+		t.Fatalf("QuoteText = %q, want %q", got, want)
+	}
+}
+
+func TestInvoiceExchangeRateErrors(t *testing.T) {
+	var calls int
+	provider := core.ExchangeRateFunc(func(base, quote string, date time.Time) (core.ExchangeRate, error) {
+		calls++
+		return core.ExchangeRate{}, core.ErrNoExchangeRate
+	})
+	params := testInvoiceParams(core.InvoiceDetailItem{Title: "Hosting", TotalIncludeTax: decimal.NewFromInt(100), TotalIncludeTaxQuoteSymbol: "円"})
+	params.Summary.TotalIncludeTax = decimal.NewFromInt(100)
+	params.Summary.TotalIncludeTaxQuoteSymbol = "JPY"
+	_, err := NewInvoiceBuilder(Config{ExchangeRates: provider}, params)
+	if !errors.Is(err, core.ErrNoExchangeRate) {
+		t.Fatalf("NewInvoiceBuilder error = %v, want ErrNoExchangeRate", err)
+	}
+
+	// Entered amounts are not looked up.
+	calls = 0
+	params.Summary.TotalIncludeTaxQuoteAmount = decimal.NewFromInt(15000)
+	params.DetailItems[0].TotalIncludeTaxQuoteAmount = decimal.NewFromInt(6000)
+	if _, err := NewInvoiceBuilder(Config{ExchangeRates: provider}, params); err != nil || calls != 0 {
+		t.Fatalf("NewInvoiceBuilder = %v after %d lookups, want no lookup", err, calls)
+	}
+}
@@ -0,0 +1,46 @@
+package builder
+
+import (
+	"strings"
+
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/quailyquaily/bizdocgen/money"
+	"github.com/shopspring/decimal"
+)
+
+// maxPriceDecimals bounds the decimals of unit prices finer than the currency's minor units.
+const maxPriceDecimals = 4
+
+func (b *Builder) moneyLocale() money.Locale {
+	if locale := strings.TrimSpace(b.cfg.Locale); locale != "" {
+		return money.LookupLocale(locale)
+	}
+	return money.LookupLocale(b.cfg.Lang)
+}
+
+// formatMoney rounds an amount under the rounding policy and formats it for display. Amounts in
+// currencies with unknown decimals, other than the document currency, keep their significant
+// decimals; see money.Decimals.
+func (b *Builder) formatMoney(amount decimal.Decimal, currency string) string {
+	r := b.displayRounding(amount, currency)
+	return b.moneyLocale().FormatDecimals(r.Round(amount), currency, r.Decimals)
+}
+
+// formatUnitPrice formats a unit price, keeping up to four decimals of prices finer than the
+// currency allows, e.g. 0.125 USD per API call.
+func (b *Builder) formatUnitPrice(price decimal.Decimal, currency string) string {
+	r := b.displayRounding(price, currency)
+	if exact := -price.Exponent(); exact > r.Decimals && !price.Equal(r.Round(price)) {
+		r.Decimals = min(exact, maxPriceDecimals)
+	}
+	return b.moneyLocale().FormatDecimals(r.Round(price), currency, r.Decimals)
+}
+
+func (b *Builder) displayRounding(amount decimal.Decimal, currency string) core.Rounding {
+	r := b.rounding(currency)
+	_, known := lookupCurrencyDecimals(b.cfg, currency)
+	if !known && (b.iParams == nil || !sameCurrency(currency, b.iParams.Currency)) {
+		r.Decimals = money.Decimals(amount, currency)
+	}
+	return r
+}
@@ -0,0 +1,70 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+func TestFormatMoneyFollowsLocaleAndCurrency(t *testing.T) {
+	cases := []struct {
+		cfg      Config
+		currency string
+		amount   string
+		want     string
+	}{
+		{Config{Lang: "en"}, "USD", "500000", "$500,000.00"},
+		{Config{Lang: "en"}, "USD", "-432.505", "-$432.51"},
+		{Config{Lang: "ja"}, "JPY", "550000", "¥550,000"},
+		{Config{Lang: "ja"}, "円", "550000.4", "¥550,000"},
+		{Config{Lang: "en", Locale: "de-DE"}, "EUR", "500000", "500.000,00 €"},
+		{Config{Lang: "en", Locale: "de-CH"}, "CHF", "1250", "CHF 1'250.00"},
+		{Config{Lang: "zh_cn"}, "CNY", "88.8", "¥88.80"},
+	}
+	for _, tc := range cases {
+		b, err := NewInvoiceBuilder(tc.cfg, &core.InvoiceParams{Currency: tc.currency})
+		if err != nil {
+			t.Fatalf("NewInvoiceBuilder: %v", err)
+		}
+		if got := b.formatMoney(decimal.RequireFromString(tc.amount), tc.currency); got != tc.want {
+			t.Errorf("formatMoney(%s %s, %+v) = %q, want %q", tc.amount, tc.currency, tc.cfg, got, tc.want)
+		}
+	}
+}
+
+func TestFormatUnitPriceKeepsSubunitPrices(t *testing.T) {
+	b, err := NewInvoiceBuilder(Config{Lang: "en"}, &core.InvoiceParams{Currency: "USD"})
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+	for price, want := range map[string]string{
+		"12":      "$12.00",
+		"0.125":   "$0.125",
+		"0.00015": "$0.0002",
+	} {
+		if got := b.formatUnitPrice(decimal.RequireFromString(price), "USD"); got != want {
+			t.Errorf("formatUnitPrice(%s) = %q, want %q", price, got, want)
+		}
+	}
+}
+
+func TestInvoiceQuoteTextFormatsReferenceAmount(t *testing.T) {
+	params := &core.InvoiceParams{
+		Currency: "USD",
+		Summary: core.InvoiceSummary{
+			Title:                      "Test",
+			TotalIncludeTax:            decimal.NewFromInt(100),
+			TotalIncludeTaxQuoteAmount: decimal.NewFromInt(15050),
+			TotalIncludeTaxQuoteSymbol: "JPY",
+		},
+	}
+	b, err := NewInvoiceBuilder(Config{Lang: "en"}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+	want := "※ Reference Amount: ¥15,050, 1 USD = 151 JPY"
+	if got := b.invoiceSummaryNumbers().QuoteText; got != want {
+		t.Fatalf("QuoteText = %q, want %q", got, want)
+	}
+}
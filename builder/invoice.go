@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/johnfercher/maroto/v2/pkg/components/col"
 	"github.com/johnfercher/maroto/v2/pkg/components/image"
@@ -17,6 +18,7 @@ import (
 	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
 	marotoCore "github.com/johnfercher/maroto/v2/pkg/core"
 	"github.com/johnfercher/maroto/v2/pkg/props"
+	"github.com/quailyquaily/bizdocgen/core"
 	"github.com/shopspring/decimal"
 )
 
@@ -27,37 +29,29 @@ const (
 	defaultStatementDocHint      = ""
 )
 
+// overdueColor is the color of the overdue marker next to the title.
+var overdueColor = &props.Color{Red: 200, Green: 30, Blue: 30}
+
 func (b *Builder) BuildInvoiceHeader() ([]marotoCore.Row, error) {
 	return b.buildInvoiceHeader(6)
 }
 
-func (b *Builder) invoiceDocTitle() string {
-	if b.iParams.Doc.Title != "" {
-		return b.iParams.Doc.Title
-	}
-	if b.docLabelSet == labelSetStatement {
-		return defaultStatementDocTitle
-	}
-	return defaultInvoiceDocTitle
-}
-
-func (b *Builder) invoiceDocHint() string {
-	if b.iParams.Doc.Description != "" {
-		return b.iParams.Doc.Description
-	}
-	if b.docLabelSet == labelSetStatement {
-		return defaultStatementDocHint
-	}
-	return defaultInvoiceDocDescription
-}
-
 func (b *Builder) buildInvoiceTitleRows() []marotoCore.Row {
 	title := b.invoiceDocTitle()
 	hint := b.invoiceDocHint()
 
-	ret := []marotoCore.Row{
-		row.New(10).Add(text.NewCol(12, title, props.Text{Size: 20, Top: 0, Align: align.Left, Style: fontstyle.Bold, Color: b.fgColor})),
+	overdue := b.invoiceOverdue()
+	titleWidth := 12
+	if overdue {
+		// The overdue marker takes the right quarter of the title row.
+		titleWidth = 9
+	}
+	titleRow := row.New(10).Add(text.NewCol(titleWidth, title, props.Text{Size: 20, Top: 0, Align: align.Left, Style: fontstyle.Bold, Color: b.fgColor}))
+	if overdue {
+		tOverdue := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceOverdue", nil)
+		titleRow.Add(text.NewCol(3, tOverdue, props.Text{Size: 14, Top: 2, Align: align.Right, Style: fontstyle.Bold, Color: overdueColor}))
 	}
+	ret := []marotoCore.Row{titleRow}
 
 	if hint != "" {
 		ret = append(ret, row.New(4).Add(text.NewCol(12, hint, props.Text{Size: 9, Top: 0, Align: align.Left, Color: b.fgTertiaryColor})))
@@ -68,6 +62,9 @@ func (b *Builder) buildInvoiceTitleRows() []marotoCore.Row {
 func (b *Builder) buildInvoiceHeader(spacerHeight float64) ([]marotoCore.Row, error) {
 	tInvoiceID := b.i18nBundle.MusT(b.cfg.Lang, b.labelKey("InvoiceID"), nil)
 	tTaxID := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceTaxID", nil)
+	if b.jpQualifiedInvoice() {
+		tTaxID = b.i18nBundle.MusT(b.cfg.Lang, "InvoiceRegistrationNumber", nil)
+	}
 	tIssueDate := b.i18nBundle.MusT(b.cfg.Lang, b.labelKey("InvoiceIssueDate"), nil)
 	tPeriod := b.i18nBundle.MusT(b.cfg.Lang, b.labelKey("InvoicePeriod"), nil)
 
@@ -105,28 +102,82 @@ func (b *Builder) buildInvoiceHeader(spacerHeight float64) ([]marotoCore.Row, er
 	}
 	leftCol.Add(text.New(b.iParams.CompanyEmail, props.Text{Size: 9, Top: float64(6*(len(lines)) + 16), Align: align.Left, Color: b.fgColor}))
 
-	rs := row.New(42).WithStyle(borderBottomStyle).Add(
-		leftCol,
-		col.New(6).Add(
-			text.New(fmt.Sprintf("%s: %s", tInvoiceID, b.iParams.ID), props.Text{Size: 9, Top: 16, Align: align.Right, Color: b.fgColor}),
-			text.New(fmt.Sprintf("%s: %s", tTaxID, b.iParams.TaxNumber), props.Text{Size: 9, Top: 22, Align: align.Right, Color: b.fgColor}),
-			text.New(fmt.Sprintf("%s: %s", tIssueDate, b.iParams.Date.Format("2006/01/02")), props.Text{Size: 9, Top: 28, Align: align.Right, Color: b.fgColor}),
-			text.New(fmt.Sprintf("%s: %s - %s", tPeriod,
-				b.iParams.Summary.PeriodStart.Format("2006/01/02"),
-				b.iParams.Summary.PeriodEnd.Format("2006/01/02"),
-			), props.Text{Size: 9, Top: 34, Align: align.Right, Color: b.fgColor}),
-		),
+	rightCol := col.New(6).Add(
+		text.New(fmt.Sprintf("%s: %s", tInvoiceID, b.iParams.ID), props.Text{Size: 9, Top: 16, Align: align.Right, Color: b.fgColor}),
+		text.New(fmt.Sprintf("%s: %s", tTaxID, b.iParams.TaxNumber), props.Text{Size: 9, Top: 22, Align: align.Right, Color: b.fgColor}),
+		text.New(fmt.Sprintf("%s: %s", tIssueDate, b.iParams.Date.Format("2006/01/02")), props.Text{Size: 9, Top: 28, Align: align.Right, Color: b.fgColor}),
+		text.New(b.invoiceHeaderLastLine(tPeriod), props.Text{Size: 9, Top: 34, Align: align.Right, Color: b.fgColor}),
 	)
+	headerHeight := float64(42)
+	if dueLine := b.invoiceDueDateLine(); dueLine != "" {
+		rightCol.Add(text.New(dueLine, props.Text{Size: 9, Top: 40, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}))
+		headerHeight = 48
+	}
+	rs := row.New(headerHeight).WithStyle(borderBottomStyle).Add(leftCol, rightCol)
 
-	rows := make([]marotoCore.Row, 0, 4)
+	rows := make([]marotoCore.Row, 0, 5)
 	rows = append(rows, b.buildInvoiceTitleRows()...)
 	rows = append(rows, rs)
+	if b.iParams.LateFee != "" {
+		rows = append(rows, row.New(8).Add(
+			text.NewCol(12, b.iParams.LateFee, props.Text{Size: 8, Top: 2, Align: align.Right, Color: b.fgSecondaryColor}),
+		))
+	}
 	if spacerHeight > 0 {
 		rows = append(rows, row.New(spacerHeight))
 	}
 	return rows, nil
 }
 
+// validatePaymentTerms refuses payment terms that do not parse, which would otherwise leave the
+// header without a due date and the invoice without an overdue marker.
+func validatePaymentTerms(params *core.InvoiceParams) error {
+	if strings.TrimSpace(params.PaymentTerms) == "" {
+		return nil
+	}
+	if _, err := core.ParsePaymentTerms(params.PaymentTerms); err != nil {
+		return fmt.Errorf("payment_terms: %w", err)
+	}
+	return nil
+}
+
+// invoiceDueDateLine returns the due date line of the header, followed by the payment terms when
+// given, e.g. "Due Date: 2024/04/30 (Net 30)". It is empty when the invoice has no due date.
+func (b *Builder) invoiceDueDateLine() string {
+	due, err := b.iParams.EffectiveDueDate()
+	if err != nil || due.IsZero() {
+		return ""
+	}
+	tDueDate := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceDueDate", nil)
+	line := fmt.Sprintf("%s: %s", tDueDate, due.Format("2006/01/02"))
+	if terms := strings.TrimSpace(b.iParams.PaymentTerms); terms != "" {
+		line = fmt.Sprintf("%s (%s)", line, terms)
+	}
+	return line
+}
+
+// invoiceOverdue reports whether Config.MarkOverdue is set and the invoice is rendered after
+// its due date with a balance due.
+func (b *Builder) invoiceOverdue() bool {
+	if !b.cfg.MarkOverdue {
+		return false
+	}
+	due, err := b.iParams.EffectiveDueDate()
+	if err != nil || due.IsZero() {
+		return false
+	}
+	now := b.cfg.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	// The invoice is due until the end of the due date.
+	if !now.After(due.AddDate(0, 0, 1)) {
+		return false
+	}
+	_, balance, ok := b.invoiceBalance()
+	return !ok || balance.IsPositive()
+}
+
 func (b *Builder) BuildInvoiceFooter() ([]marotoCore.Row, error) {
 	if b.iParams == nil {
 		return nil, fmt.Errorf("invoice params are nil")
@@ -152,11 +203,25 @@ func (b *Builder) BuildInvoiceBillTo() []marotoCore.Row {
 	billTo := col.New(8)
 	billTo.Add(text.New(b.iParams.BillToCompany, props.Text{Size: 9, Top: float64(0), Style: fontstyle.Bold, Color: b.fgColor}))
 	billTo.Add(text.New(b.iParams.BillToAddress, props.Text{Size: 9, Top: float64(6), Color: b.fgColor}))
+	height := float64(12)
+	if taxLine := b.invoiceBillToTaxLine(); taxLine != "" {
+		billTo.Add(text.New(taxLine, props.Text{Size: 9, Top: float64(12), Color: b.fgColor}))
+		height = 18
+	}
 
 	return []marotoCore.Row{
 		text.NewRow(8, tBillTo, props.Text{Size: 10, Top: 0, Style: fontstyle.Bold, Color: b.fgColor}),
-		row.New(12).Add(billTo),
+		row.New(height).Add(billTo),
+	}
+}
+
+// invoiceBillToTaxLine returns the buyer's tax ID line of the bill-to block, or an empty string.
+func (b *Builder) invoiceBillToTaxLine() string {
+	taxNumber := strings.TrimSpace(b.iParams.BillToTaxNumber)
+	if taxNumber == "" {
+		return ""
 	}
+	return fmt.Sprintf("%s: %s", b.i18nBundle.MusT(b.cfg.Lang, "InvoiceTaxID", nil), taxNumber)
 }
 
 func (b *Builder) BuildInvoicePaymentRows() []marotoCore.Row {
@@ -322,10 +387,10 @@ func (b *Builder) BuildInvoicePaymentRows() []marotoCore.Row {
 }
 
 func (b *Builder) BuildInvoicePaymentResultRows() []marotoCore.Row {
-	tPaymentResult := b.i18nBundle.MusT(b.cfg.Lang, "InvoicePaymentResult", nil)
+	tPaymentResult := b.i18nBundle.MusT(b.cfg.Lang, b.labelKey("InvoicePaymentResult"), nil)
 	tMethod := b.i18nBundle.MusT(b.cfg.Lang, "InvoicePaymentResultMethod", nil)
-	tAmount := b.i18nBundle.MusT(b.cfg.Lang, "InvoicePaymentResultAmount", nil)
-	tPaidDate := b.i18nBundle.MusT(b.cfg.Lang, "InvoicePaymentResultPaidDate", nil)
+	tAmount := b.i18nBundle.MusT(b.cfg.Lang, b.labelKey("InvoicePaymentResultAmount"), nil)
+	tPaidDate := b.i18nBundle.MusT(b.cfg.Lang, b.labelKey("InvoicePaymentResultPaidDate"), nil)
 	tTxID := b.i18nBundle.MusT(b.cfg.Lang, "InvoicePaymentResultTxID", nil)
 
 	borderBottomStyle := &props.Cell{
@@ -340,6 +405,10 @@ func (b *Builder) BuildInvoicePaymentResultRows() []marotoCore.Row {
 		),
 	}
 
+	if len(b.iParams.Payment.Results) > 0 {
+		return append(rows, b.buildInvoicePaymentsTableRows()...)
+	}
+
 	result := b.iParams.Payment.InvoicePaymentResult
 	firstLine := true
 	addLine := func(label, value string) {
@@ -369,7 +438,7 @@ func (b *Builder) BuildInvoicePaymentResultRows() []marotoCore.Row {
 		if currency == "" {
 			currency = b.iParams.Currency
 		}
-		addLine(tAmount, fmt.Sprintf("%s %s", result.Amount.RoundDown(2), currency))
+		addLine(tAmount, b.formatMoney(result.Amount, currency))
 	}
 	if !result.PaidDate.IsZero() {
 		addLine(tPaidDate, result.PaidDate.Format("2006-01-02"))
@@ -390,7 +459,7 @@ func (b *Builder) showInvoicePaymentResult() bool {
 	if b.iParams == nil {
 		return false
 	}
-	return !b.iParams.Payment.InvoicePaymentResult.Disabled
+	return !b.iParams.Payment.InvoicePaymentResult.Disabled || len(b.iParams.Payment.Results) > 0
 }
 
 func (b *Builder) defaultInvoicePaymentMethod() string {
@@ -435,20 +504,35 @@ func (b *Builder) BuildInvoiceDetailsRows() []marotoCore.Row {
 		),
 	}
 
-	for ix, item := range b.iParams.DetailItems {
-		itemCurrency := strings.TrimSpace(item.Currency)
-		if itemCurrency == "" {
-			itemCurrency = strings.TrimSpace(b.iParams.Currency)
-		}
+	priced := !b.hidePrices && b.hasInvoiceUnitPrices()
+	if b.hidePrices {
+		tQuantity := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceDetailsQuantity", nil)
+		rows = append(rows, row.New(8).WithStyle(borderBottomStyle).Add(
+			col.New(10),
+			text.NewCol(2, tQuantity, props.Text{Size: 8, Top: 2, Align: align.Right, Color: b.fgSecondaryColor}),
+		))
+	} else if priced {
+		tQuantity := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceDetailsQuantity", nil)
+		tUnitPrice := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceDetailsUnitPrice", nil)
+		tAmount := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceSummaryAmount", nil)
+		rows = append(rows, row.New(8).WithStyle(borderBottomStyle).Add(
+			col.New(6),
+			text.NewCol(2, tQuantity, props.Text{Size: 8, Top: 2, Align: align.Right, Color: b.fgSecondaryColor}),
+			text.NewCol(2, tUnitPrice, props.Text{Size: 8, Top: 2, Align: align.Right, Color: b.fgSecondaryColor}),
+			text.NewCol(2, tAmount, props.Text{Size: 8, Top: 2, Align: align.Right, Color: b.fgSecondaryColor}),
+		))
+	}
 
-		displayAmount := item.TotalIncludeTax
-		if displayAmount.IsZero() {
-			displayAmount = item.TotalExcludeTax
-			if !item.TotalExcludeTax.IsZero() && !item.Tax.IsZero() {
-				displayAmount = item.TotalExcludeTax.Add(item.Tax)
-			}
-		}
-		quoteText := b.invoiceReferenceQuoteText(displayAmount, itemCurrency, item.TotalIncludeTaxQuoteAmount, item.TotalIncludeTaxQuoteSymbol)
+	var breakdown []invoiceTaxBreakdown
+	if b.jpQualifiedInvoice() {
+		breakdown = b.invoiceSummaryNumbers().TaxBreakdown
+	}
+
+	for ix, item := range b.iParams.DetailItems {
+		itemCurrency := b.invoiceItemCurrency(item)
+		amounts := b.invoiceLineAmounts(item)
+		quoteAmount, quoteRate := b.invoiceQuote(amounts.IncludeTax, itemCurrency, item.TotalIncludeTaxQuoteAmount, item.TotalIncludeTaxQuoteSymbol)
+		quoteText := b.invoiceReferenceQuoteText(amounts.IncludeTax, itemCurrency, quoteAmount, item.TotalIncludeTaxQuoteSymbol, quoteRate)
 
 		paddingTop := float64(0)
 		rowHeight := float64(6)
@@ -456,44 +540,91 @@ func (b *Builder) BuildInvoiceDetailsRows() []marotoCore.Row {
 			paddingTop = float64(4)
 			rowHeight = float64(10)
 		}
+		titleWidth := 6
+		if priced {
+			titleWidth = 4
+		} else if b.hidePrices {
+			titleWidth = 8
+		}
+		title := item.Title
+		if b.isJPReducedRateItem(item, breakdown) {
+			title = fmt.Sprintf("%s %s", title, jpReducedRateMark)
+		}
 		r := row.New(rowHeight)
 		r.Add(
 			col.New(2).Add(
 				text.New(item.Date.Format("2006/01/02"), props.Text{Size: 9, Top: paddingTop, Align: align.Left, Color: b.fgColor}),
 			),
-			col.New(6).Add(
-				text.New(item.Title, props.Text{Size: 9, Top: paddingTop, Align: align.Left, Color: b.fgColor}),
+			col.New(titleWidth).Add(
+				text.New(title, props.Text{Size: 9, Top: paddingTop, Align: align.Left, Color: b.fgColor}),
 			),
 		)
-		if !item.TotalExcludeTax.IsZero() || !item.TotalIncludeTax.IsZero() {
+		if b.hidePrices {
+			quantityText := strings.TrimSpace(fmt.Sprintf("%s %s", item.EffectiveQuantity(), item.Unit))
+			r.Add(
+				col.New(2).Add(
+					text.New(quantityText, props.Text{Size: 9, Top: paddingTop, Align: align.Right, Color: b.fgColor}),
+				),
+			)
+		}
+		amountWidth := 4
+		if priced {
+			amountWidth = 2
+			quantityText, unitPriceText := "", ""
+			if hasInvoiceUnitPrice(item) {
+				quantityText = strings.TrimSpace(fmt.Sprintf("%s %s", amounts.Quantity, item.Unit))
+				unitPriceText = b.formatUnitPrice(item.UnitPrice, itemCurrency)
+			}
+			r.Add(
+				col.New(2).Add(
+					text.New(quantityText, props.Text{Size: 9, Top: paddingTop, Align: align.Right, Color: b.fgColor}),
+				),
+				col.New(2).Add(
+					text.New(unitPriceText, props.Text{Size: 9, Top: paddingTop, Align: align.Right, Color: b.fgColor}),
+				),
+			)
+		}
+		if !b.hidePrices && (!amounts.ExcludeTax.IsZero() || !item.TotalIncludeTax.IsZero()) {
+			displayAmount := amounts.ExcludeTax
 			if !item.TotalIncludeTax.IsZero() {
-				r.Add(
-					col.New(4).Add(
-						text.New(fmt.Sprintf("%s %s", item.TotalIncludeTax.RoundDown(2), itemCurrency), props.Text{Size: 9, Top: paddingTop, Align: align.Right, Color: b.fgColor}),
-					),
-				)
-			} else {
-				r.Add(
-					col.New(4).Add(
-						text.New(fmt.Sprintf("%s %s", item.TotalExcludeTax.RoundDown(2), itemCurrency), props.Text{Size: 9, Top: paddingTop, Align: align.Right, Color: b.fgColor}),
-					),
-				)
+				displayAmount = item.TotalIncludeTax
 			}
+			r.Add(
+				col.New(amountWidth).Add(
+					text.New(b.formatMoney(displayAmount, itemCurrency), props.Text{Size: 9, Top: paddingTop, Align: align.Right, Color: b.fgColor}),
+				),
+			)
 		}
 		rows = append(rows, r)
 
+		if !b.hidePrices && item.Priced() && !item.Discount.IsZero() {
+			tDiscount := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceDetailsDiscount", nil)
+			rows = append(rows, row.New(6).Add(
+				col.New(2),
+				col.New(6).Add(
+					text.New(tDiscount, props.Text{Size: 8, Top: 0, Align: align.Left, Color: b.fgSecondaryColor}),
+				),
+				col.New(4).Add(
+					text.New(b.formatMoney(item.Discount.Neg(), itemCurrency), props.Text{Size: 8, Top: 0, Align: align.Right, Color: b.fgSecondaryColor}),
+				),
+			))
+		}
+		if !b.hidePrices {
+			rows = append(rows, b.buildInvoiceLineAdjustmentRows(item, itemCurrency)...)
+		}
+
 		if item.Desc != "" {
 			r := row.New(6)
 			r.Add(
 				col.New(2),
 			)
-			if !item.TotalExcludeTax.IsZero() && !item.Tax.IsZero() {
+			if !b.hidePrices && !amounts.ExcludeTax.IsZero() && !amounts.Tax.IsZero() {
 				r.Add(
 					col.New(6).Add(
 						text.New(item.Desc, props.Text{Size: 8, Top: 0, Align: align.Left, Color: b.fgSecondaryColor}),
 					),
 					col.New(4).Add(
-						text.New(fmt.Sprintf("VAT: %s %s", item.Tax.RoundDown(2), itemCurrency), props.Text{Size: 8, Top: 0, Align: align.Right, Color: b.fgSecondaryColor}),
+						text.New(fmt.Sprintf("VAT: %s", b.formatMoney(amounts.Tax, itemCurrency)), props.Text{Size: 8, Top: 0, Align: align.Right, Color: b.fgSecondaryColor}),
 					),
 				)
 			} else {
@@ -505,7 +636,7 @@ func (b *Builder) BuildInvoiceDetailsRows() []marotoCore.Row {
 			}
 			rows = append(rows, r)
 		}
-		if quoteText != "" {
+		if !b.hidePrices && quoteText != "" {
 			rows = append(rows, row.New(6).Add(
 				col.New(2),
 				col.New(10).Add(
@@ -533,10 +664,69 @@ func (b *Builder) BuildInvoiceDetailsRows() []marotoCore.Row {
 		}
 		rows = append(rows, row.New(2))
 	}
+	rows = append(rows, b.buildJPReducedRateNoteRows(breakdown)...)
+	rows = append(rows, b.buildInvoiceTaxNoteRows()...)
 	return rows
 }
 
+type invoiceLineAmounts struct {
+	Quantity   decimal.Decimal
+	ExcludeTax decimal.Decimal
+	Tax        decimal.Decimal
+	IncludeTax decimal.Decimal
+}
+
+// invoiceLineAmounts resolves the totals of a detail item. Explicit totals win; otherwise a priced
+// line is computed as Quantity × UnitPrice − Discount plus its adjustments, with a missing quantity
+// counting as one unit. A line without an explicit tax is taxed at its own TaxRate, if any. The
+// line total and tax are rounded to the item's currency.
+func (b *Builder) invoiceLineAmounts(item core.InvoiceDetailItem) invoiceLineAmounts {
+	currency := b.invoiceItemCurrency(item)
+	quantity := item.EffectiveQuantity()
+	excludeTax := b.round(item.NetAmountRounded(b.rounding(currency)), currency)
+
+	tax := item.Tax
+	if tax.IsZero() && !item.TaxRate.IsZero() {
+		tax = b.roundTax(excludeTax.Mul(item.TaxRate), currency)
+	}
+
+	includeTax := item.TotalIncludeTax
+	if includeTax.IsZero() {
+		includeTax = excludeTax
+		if !excludeTax.IsZero() && !tax.IsZero() {
+			includeTax = excludeTax.Add(tax)
+		}
+	}
+
+	return invoiceLineAmounts{
+		Quantity:   quantity,
+		ExcludeTax: excludeTax,
+		Tax:        tax,
+		IncludeTax: includeTax,
+	}
+}
+
+func (b *Builder) hasInvoiceUnitPrices() bool {
+	if b.iParams == nil {
+		return false
+	}
+	for _, item := range b.iParams.DetailItems {
+		if hasInvoiceUnitPrice(item) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasInvoiceUnitPrice(item core.InvoiceDetailItem) bool {
+	return !item.UnitPrice.IsZero()
+}
+
+// BuildInvoiceSummaryRows renders the summary block. It is empty when the document hides prices.
 func (b *Builder) BuildInvoiceSummaryRows() []marotoCore.Row {
+	if b.hidePrices {
+		return nil
+	}
 	tSummary := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceSummary", nil)
 	tAmount := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceSummaryAmount", nil)
 	tVAT := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceSummaryVAT", nil)
@@ -557,18 +747,40 @@ func (b *Builder) BuildInvoiceSummaryRows() []marotoCore.Row {
 		),
 		row.New(12).Add(
 			text.NewCol(8, b.iParams.Summary.Title, props.Text{Size: 9, Top: 4, Align: align.Left, Color: b.fgColor}),
-			text.NewCol(4, fmt.Sprintf("%s %s", summary.Subtotal.RoundDown(2), summaryCurrency), props.Text{Size: 9, Top: 4, Align: align.Right, Color: b.fgColor}),
-		),
-		row.New(8).WithStyle(borderBottomStyle).Add(
-			text.NewCol(6, tVAT, props.Text{Size: 9, Top: 0, Align: align.Left, Color: b.fgColor}),
-			text.NewCol(6, fmt.Sprintf("%s %s", summary.Tax, summaryCurrency), props.Text{Size: 9, Top: 0, Align: align.Right, Color: b.fgColor}),
+			text.NewCol(4, b.formatMoney(summary.ItemsTotal, summaryCurrency), props.Text{Size: 9, Top: 4, Align: align.Right, Color: b.fgColor}),
 		),
+	}
+	// Taxed adjustments lead to the subtotal the tax is computed on; untaxed ones follow the tax.
+	if taxed := b.buildInvoiceAdjustmentRows(summary, false); len(taxed) > 0 {
+		tSubtotal := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceSummarySubtotal", nil)
+		ret = append(ret, taxed...)
+		ret = append(ret, row.New(8).Add(
+			text.NewCol(8, tSubtotal, props.Text{Size: 9, Top: 0, Align: align.Left, Color: b.fgColor}),
+			text.NewCol(4, b.formatMoney(summary.Subtotal, summaryCurrency), props.Text{Size: 9, Top: 0, Align: align.Right, Color: b.fgColor}),
+		))
+	}
+	ret = append(ret, b.buildInvoiceTaxBreakdownRows(summary)...)
+	untaxed := b.buildInvoiceAdjustmentRows(summary, true)
+	vatRow := row.New(8).Add(
+		text.NewCol(6, tVAT, props.Text{Size: 9, Top: 0, Align: align.Left, Color: b.fgColor}),
+		text.NewCol(6, b.formatMoney(summary.Tax, summaryCurrency), props.Text{Size: 9, Top: 0, Align: align.Right, Color: b.fgColor}),
+	)
+	if len(untaxed) == 0 {
+		vatRow.WithStyle(borderBottomStyle)
+	} else {
+		untaxed[len(untaxed)-1].WithStyle(borderBottomStyle)
+	}
+	ret = append(ret, vatRow)
+	ret = append(ret, untaxed...)
+	ret = append(ret,
 		row.New(10).Add(
 			text.NewCol(6, tTotal, props.Text{Size: 10, Top: 4, Align: align.Left, Style: fontstyle.Bold, Color: b.fgColor}),
-			text.NewCol(6, fmt.Sprintf("%s %s", summary.Total, summaryCurrency), props.Text{Size: 10, Top: 4, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
+			text.NewCol(6, b.formatMoney(summary.Total, summaryCurrency), props.Text{Size: 10, Top: 4, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
 		),
-	}
-	if summary.QuoteAmount.IsPositive() && summary.QuoteText != "" {
+	)
+	ret = append(ret, b.buildInvoiceWithholdingRows(summary)...)
+	ret = append(ret, b.buildInvoiceBalanceRows()...)
+	if !summary.QuoteAmount.IsZero() && summary.QuoteText != "" {
 		ret = append(ret, row.New(8).Add(
 			text.NewCol(12, summary.QuoteText, props.Text{Size: 8, Top: 2, Align: align.Left, Color: b.fgColor}),
 		))
@@ -577,84 +789,186 @@ func (b *Builder) BuildInvoiceSummaryRows() []marotoCore.Row {
 }
 
 type invoiceSummaryNumbers struct {
+	// ItemsTotal is the net total before the summary adjustments; Subtotal is the taxable amount
+	// after the taxed ones. Total includes the untaxed ones. Payable is Total less Withholding.
+	ItemsTotal   decimal.Decimal
 	Subtotal     decimal.Decimal
 	Tax          decimal.Decimal
 	Total        decimal.Decimal
+	Withholding  decimal.Decimal
+	Payable      decimal.Decimal
 	QuoteAmount  decimal.Decimal
 	QuoteSymbol  string
 	QuoteText    string
 	BaseCurrency string
+	TaxBreakdown []invoiceTaxBreakdown
+	Adjustments  []invoiceAdjustment
 }
 
 func (b *Builder) invoiceSummaryNumbers() invoiceSummaryNumbers {
+	summary := b.iParams.Summary
+	if normalizeSummaryCheck(b.cfg.SummaryCheck) == SummaryCheckDerive {
+		summary, _ = b.derivedInvoiceSummary(b.invoiceBaseCurrency())
+	}
+	return b.invoiceSummaryNumbersOf(summary)
+}
+
+// invoiceSummaryNumbersOf computes the summary numbers from summary, which is the document's
+// summary or one derived from its detail items.
+func (b *Builder) invoiceSummaryNumbersOf(summary core.InvoiceSummary) invoiceSummaryNumbers {
 	var total, tax, subtotal, totalJPY decimal.Decimal
 	var quoteAmount decimal.Decimal
 	var quoteSymbol string
 	var quoteText string
 
-	baseCurrency := strings.TrimSpace(b.iParams.Summary.Currency)
-	if baseCurrency == "" {
-		baseCurrency = strings.TrimSpace(b.iParams.Currency)
-	}
-
-	if b.iParams.Summary.TotalExcludeTax.IsPositive() {
-		subtotal = b.iParams.Summary.TotalExcludeTax
-		if b.iParams.Summary.Tax.IsPositive() {
-			tax = b.iParams.Summary.Tax.Round(2)
-		} else if b.iParams.Summary.TaxRate.IsPositive() {
-			tax = subtotal.Mul(b.iParams.Summary.TaxRate).Round(2)
+	baseCurrency := b.invoiceBaseCurrency()
+
+	adjustments := b.invoiceAdjustments(baseCurrency)
+	taxedAdjustments, untaxedAdjustments := sumInvoiceAdjustments(adjustments)
+	breakdown := b.invoiceTaxBreakdown(baseCurrency, adjustments)
+	breakdownBase, breakdownTax := sumInvoiceTaxBreakdown(breakdown)
+
+	if !summary.TotalExcludeTax.IsZero() {
+		subtotal = b.round(summary.TotalExcludeTax, baseCurrency)
+		if len(breakdown) > 0 && b.jpQualifiedInvoice() {
+			tax = breakdownTax
+		} else if !summary.Tax.IsZero() {
+			tax = b.round(summary.Tax, baseCurrency)
+		} else if len(breakdown) > 0 {
+			tax = breakdownTax
+		} else if summary.TaxRate.IsPositive() {
+			tax = b.invoiceSummaryRateTax(baseCurrency, subtotal, adjustments)
 		}
-		total = subtotal.Add(tax).Round(2)
+		total = subtotal.Add(tax).Add(untaxedAdjustments)
+	} else if len(breakdown) > 0 && summary.TotalIncludeTax.IsZero() {
+		// Nothing entered on the summary: the per-rate groups are the totals.
+		subtotal = breakdownBase
+		tax = breakdownTax
+		total = subtotal.Add(tax).Add(untaxedAdjustments)
+	} else if len(breakdown) > 0 && (summary.Tax.IsZero() || b.jpQualifiedInvoice()) {
+		total = b.round(summary.TotalIncludeTax, baseCurrency)
+		tax = breakdownTax
+		subtotal = total.Sub(untaxedAdjustments).Sub(tax)
 	} else {
-		total = b.iParams.Summary.TotalIncludeTax
-		subtotal = total.Div(decimal.NewFromFloat(1).Add(b.iParams.Summary.TaxRate)).Round(2)
-		tax = total.Sub(subtotal).Round(2)
-	}
-
-	quoteSymbol = strings.TrimSpace(b.iParams.Summary.TotalIncludeTaxQuoteSymbol)
-	if quoteSymbol == "" {
-		quoteSymbol = strings.TrimSpace(b.iParams.Summary.TotalIncludeTaxQuotaSymbol)
+		total = b.round(summary.TotalIncludeTax, baseCurrency)
+		taxed := total.Sub(untaxedAdjustments)
+		subtotal = b.round(taxed.Div(decimal.NewFromFloat(1).Add(summary.TaxRate)), baseCurrency)
+		tax = taxed.Sub(subtotal)
+	}
+
+	if len(breakdown) == 0 && b.jpQualifiedInvoice() {
+		// Qualified invoices always state the per-rate totals, even for a single summary rate.
+		rate := summary.TaxRate
+		if !summary.TotalExcludeTax.IsZero() {
+			tax = b.roundTax(subtotal.Mul(rate), baseCurrency)
+			total = subtotal.Add(tax).Add(untaxedAdjustments)
+		} else {
+			taxed := total.Sub(untaxedAdjustments)
+			tax = b.roundTax(taxed.Mul(rate).Div(decimal.NewFromInt(1).Add(rate)), baseCurrency)
+			subtotal = taxed.Sub(tax)
+		}
+		category := core.TaxCategoryStandard
+		if rate.IsZero() {
+			category = core.TaxCategoryZeroRated
+		}
+		breakdown = []invoiceTaxBreakdown{{Category: category, Rate: rate, Base: subtotal, Tax: tax}}
 	}
 
-	if b.iParams.Summary.TotalIncludeTaxQuoteAmount.IsPositive() && quoteSymbol != "" {
-		quoteAmount = b.iParams.Summary.TotalIncludeTaxQuoteAmount
-	} else if b.iParams.Summary.TotalIncludeTaxJPY.IsPositive() {
-		totalJPY = b.iParams.Summary.TotalIncludeTaxJPY
+	quoteSymbol = invoiceQuoteSymbol(summary)
+	var quoteRate core.ExchangeRate
+	if !summary.TotalIncludeTaxQuoteAmount.IsZero() && quoteSymbol != "" {
+		quoteAmount = summary.TotalIncludeTaxQuoteAmount
+	} else if !summary.TotalIncludeTaxJPY.IsZero() {
+		totalJPY = summary.TotalIncludeTaxJPY
 		quoteAmount = totalJPY
 		quoteSymbol = "JPY"
+	} else {
+		quoteAmount, quoteRate = b.invoiceQuote(total, baseCurrency, decimal.Zero, quoteSymbol)
+	}
+	if quoteRate.Rate.IsZero() {
+		quoteRate.Source = summary.TotalIncludeTaxQuoteRateSource
+		quoteRate.Date = summary.TotalIncludeTaxQuoteRateDate
 	}
 
-	quoteText = b.invoiceReferenceQuoteText(total, baseCurrency, quoteAmount, quoteSymbol)
+	quoteText = b.invoiceReferenceQuoteText(total, baseCurrency, quoteAmount, quoteSymbol, quoteRate)
+	withholding := b.invoiceWithholding(subtotal, total)
 
 	return invoiceSummaryNumbers{
+		ItemsTotal:   subtotal.Sub(taxedAdjustments),
 		Subtotal:     subtotal,
 		Tax:          tax,
 		Total:        total,
+		Withholding:  withholding,
+		Payable:      total.Sub(withholding),
 		QuoteAmount:  quoteAmount,
 		QuoteSymbol:  quoteSymbol,
 		QuoteText:    quoteText,
 		BaseCurrency: baseCurrency,
+		TaxBreakdown: breakdown,
+		Adjustments:  adjustments,
+	}
+}
+
+// invoiceSummaryRateTax taxes subtotal at Summary.TaxRate. With RoundPerLine the tax of every
+// detail item and taxed adjustment is rounded on its own, as long as they add up to subtotal.
+func (b *Builder) invoiceSummaryRateTax(baseCurrency string, subtotal decimal.Decimal, adjustments []invoiceAdjustment) decimal.Decimal {
+	rate := b.iParams.Summary.TaxRate
+	if !b.roundPerLine() {
+		return b.roundTax(subtotal.Mul(rate), baseCurrency)
+	}
+	var bases []decimal.Decimal
+	for _, item := range b.iParams.DetailItems {
+		if b.invoiceItemCurrency(item) == baseCurrency {
+			bases = append(bases, b.invoiceLineAmounts(item).ExcludeTax)
+		}
+	}
+	for _, adj := range adjustments {
+		if !adj.Untaxed {
+			bases = append(bases, adj.Amount)
+		}
+	}
+	sum, tax := decimal.Zero, decimal.Zero
+	for _, base := range bases {
+		sum = sum.Add(base)
+		tax = tax.Add(b.roundTax(base.Mul(rate), baseCurrency))
 	}
+	if !sum.Equal(subtotal) {
+		return b.roundTax(subtotal.Mul(rate), baseCurrency)
+	}
+	return tax
 }
 
-func (b *Builder) invoiceReferenceQuoteText(baseAmount decimal.Decimal, baseSymbol string, quoteAmount decimal.Decimal, quoteSymbol string) string {
+// invoiceReferenceQuoteText returns the reference amount note. The rate is the looked-up one,
+// if any, otherwise the one implied by the amounts; its source and date follow when known.
+func (b *Builder) invoiceReferenceQuoteText(baseAmount decimal.Decimal, baseSymbol string, quoteAmount decimal.Decimal, quoteSymbol string, rate core.ExchangeRate) string {
 	quoteSymbol = strings.TrimSpace(quoteSymbol)
 	baseSymbol = strings.TrimSpace(baseSymbol)
-	if baseSymbol == "" || quoteSymbol == "" || !baseAmount.IsPositive() || !quoteAmount.IsPositive() {
+	if baseSymbol == "" || quoteSymbol == "" || baseAmount.IsZero() || quoteAmount.IsZero() {
 		return ""
 	}
 
-	quoteAmountRounded := quoteAmount.Round(2)
-	quotePerBaseRounded := quoteAmount.Div(baseAmount).Round(4)
-	if quoteSymbol == "JPY" || quoteSymbol == "円" {
-		quoteAmountRounded = quoteAmount.Round(0)
-		quotePerBaseRounded = quoteAmount.Div(baseAmount).Round(0)
+	perBase := quoteAmount.Div(baseAmount)
+	perBaseDecimals := int32(4)
+	if core.AmountDecimals(quoteSymbol) == 0 {
+		perBaseDecimals = 0
+	}
+	if !rate.Rate.IsZero() {
+		perBase = rate.Rate
+		perBaseDecimals = max(0, min(-rate.Rate.Exponent(), maxRateDecimals))
 	}
 
-	return b.i18nBundle.MusT(b.cfg.Lang, "InvoiceSummaryTotalWithTaxQuote", map[string]any{
-		"QuoteAmount":  quoteAmountRounded.String(),
+	text := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceSummaryTotalWithTaxQuote", map[string]any{
+		"QuoteAmount":  b.formatMoney(quoteAmount, quoteSymbol),
 		"QuoteSymbol":  quoteSymbol,
 		"BaseSymbol":   baseSymbol,
-		"QuotePerBase": quotePerBaseRounded.String(),
+		"QuotePerBase": b.moneyLocale().FormatNumber(perBase, perBaseDecimals),
 	})
+	date := ""
+	if !rate.Date.IsZero() {
+		date = rate.Date.Format("2006-01-02")
+	}
+	if source := b.invoiceQuoteRateSource(rate.Source, date); source != "" {
+		text += source
+	}
+	return text
 }
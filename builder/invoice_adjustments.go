@@ -0,0 +1,119 @@
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	marotoCore "github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+// invoiceAdjustment is a summary discount or surcharge with its signed amount and tax group.
+type invoiceAdjustment struct {
+	Label    string
+	Discount bool
+	Untaxed  bool
+	Amount   decimal.Decimal
+	Category string
+	Rate     decimal.Decimal
+}
+
+// invoiceAdjustments applies the summary adjustments in order to the net total of the detail
+// items billed in baseCurrency. Taxed adjustments belong to their own tax group or, without a
+// tax category or rate, to the one of Summary.TaxRate.
+func (b *Builder) invoiceAdjustments(baseCurrency string) []invoiceAdjustment {
+	adjustments := b.iParams.Summary.Adjustments
+	if len(adjustments) == 0 {
+		return nil
+	}
+	net := decimal.Zero
+	for _, item := range b.iParams.DetailItems {
+		if b.invoiceItemCurrency(item) == baseCurrency {
+			net = net.Add(b.invoiceLineAmounts(item).ExcludeTax)
+		}
+	}
+	amounts := core.ApplyAdjustments(net, adjustments, b.rounding(baseCurrency))
+
+	ret := make([]invoiceAdjustment, 0, len(adjustments))
+	for ix, adj := range adjustments {
+		a := invoiceAdjustment{
+			Label:    b.invoiceAdjustmentLabel(adj),
+			Discount: adj.IsDiscount(),
+			Untaxed:  adj.Untaxed,
+			Amount:   amounts[ix],
+		}
+		if !adj.Untaxed {
+			a.Category, a.Rate = b.invoiceItemTax(core.InvoiceDetailItem{TaxCategory: adj.TaxCategory, TaxRate: adj.TaxRate})
+		}
+		ret = append(ret, a)
+	}
+	return ret
+}
+
+// sumInvoiceAdjustments returns the total of the taxed and of the untaxed adjustments.
+func sumInvoiceAdjustments(adjustments []invoiceAdjustment) (taxed, untaxed decimal.Decimal) {
+	for _, adj := range adjustments {
+		if adj.Untaxed {
+			untaxed = untaxed.Add(adj.Amount)
+		} else {
+			taxed = taxed.Add(adj.Amount)
+		}
+	}
+	return taxed, untaxed
+}
+
+// invoiceAdjustmentLabel returns the adjustment's title, or "Discount"/"Surcharge", followed by
+// the percentage for percentage adjustments.
+func (b *Builder) invoiceAdjustmentLabel(adj core.InvoiceAdjustment) string {
+	label := strings.TrimSpace(adj.Title)
+	if label == "" {
+		key := "InvoiceSummarySurcharge"
+		if adj.IsDiscount() {
+			key = "InvoiceSummaryDiscount"
+		}
+		label = b.i18nBundle.MusT(b.cfg.Lang, key, nil)
+	}
+	if adj.Amount.IsZero() && !adj.Percent.IsZero() {
+		label = fmt.Sprintf("%s (%s%%)", label, adj.Percent)
+	}
+	return label
+}
+
+// buildInvoiceLineAdjustmentRows renders the adjustments of a priced detail item below the line.
+func (b *Builder) buildInvoiceLineAdjustmentRows(item core.InvoiceDetailItem, currency string) []marotoCore.Row {
+	amounts := item.AdjustmentAmounts(b.rounding(currency))
+	rows := make([]marotoCore.Row, 0, len(amounts))
+	for ix, amount := range amounts {
+		rows = append(rows, row.New(6).Add(
+			col.New(2),
+			col.New(6).Add(
+				text.New(b.invoiceAdjustmentLabel(item.Adjustments[ix]), props.Text{Size: 8, Top: 0, Align: align.Left, Color: b.fgSecondaryColor}),
+			),
+			col.New(4).Add(
+				text.New(b.formatMoney(amount, currency), props.Text{Size: 8, Top: 0, Align: align.Right, Color: b.fgSecondaryColor}),
+			),
+		))
+	}
+	return rows
+}
+
+// buildInvoiceAdjustmentRows renders one summary line per adjustment, taxed or untaxed.
+func (b *Builder) buildInvoiceAdjustmentRows(summary invoiceSummaryNumbers, untaxed bool) []marotoCore.Row {
+	var rows []marotoCore.Row
+	for _, adj := range summary.Adjustments {
+		if adj.Untaxed != untaxed {
+			continue
+		}
+		rows = append(rows, row.New(8).Add(
+			text.NewCol(8, adj.Label, props.Text{Size: 9, Top: 0, Align: align.Left, Color: b.fgColor}),
+			text.NewCol(4, b.formatMoney(adj.Amount, summary.BaseCurrency), props.Text{Size: 9, Top: 0, Align: align.Right, Color: b.fgColor}),
+		))
+	}
+	return rows
+}
@@ -0,0 +1,149 @@
+package builder
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+func TestInvoiceAdjustments(t *testing.T) {
+	params := &core.InvoiceParams{}
+	if err := params.Load("../samples/invoice-7.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	b, err := NewInvoiceBuilder(Config{ValidateParams: true}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+
+	// 20 × 250 − 10% and 10 × 400 + 150.
+	if got := b.invoiceLineAmounts(params.DetailItems[0]).ExcludeTax; got.String() != "4500" {
+		t.Fatalf("chair line = %s, want 4500", got)
+	}
+	if got := b.invoiceLineAmounts(params.DetailItems[1]).ExcludeTax; got.String() != "4150" {
+		t.Fatalf("desk line = %s, want 4150", got)
+	}
+
+	// 8650 − 5% + 120 is taxed at 19%; the 35 disbursement is added after tax.
+	nums := b.invoiceSummaryNumbers()
+	want := map[string]string{
+		"items":    "8650",
+		"subtotal": "8337.5",
+		"tax":      "1584.13",
+		"total":    "9956.63",
+	}
+	got := map[string]string{
+		"items":    nums.ItemsTotal.String(),
+		"subtotal": nums.Subtotal.String(),
+		"tax":      nums.Tax.String(),
+		"total":    nums.Total.String(),
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %s, want %s", key, got[key], value)
+		}
+	}
+	if len(nums.Adjustments) != 3 || nums.Adjustments[0].Amount.String() != "-432.5" || nums.Adjustments[0].Label != "Loyalty discount (5%)" {
+		t.Fatalf("adjustments = %+v", nums.Adjustments)
+	}
+
+	// Header, items, two taxed adjustments, subtotal, VAT, one untaxed adjustment and the total.
+	if rows := b.BuildInvoiceSummaryRows(); len(rows) != 8 {
+		t.Fatalf("len(summary rows) = %d, want 8", len(rows))
+	}
+	for _, layout := range BuiltinLayoutNames() {
+		b.cfg.InvoiceLayout = layout
+		buf, err := b.GenerateInvoice()
+		if err != nil || !bytes.HasPrefix(buf, []byte("%PDF")) {
+			t.Fatalf("GenerateInvoice(%s) = %v", layout, err)
+		}
+	}
+
+	if _, err := b.EInvoice(); err == nil {
+		t.Fatal("EInvoice accepted an untaxed adjustment")
+	}
+	params.Summary.Adjustments = params.Summary.Adjustments[:2]
+	params.Summary.TotalExcludeTax = decimal.RequireFromString("8337.50")
+	inv, err := b.EInvoice()
+	if err != nil {
+		t.Fatalf("EInvoice: %v", err)
+	}
+	if err := inv.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if inv.Totals.AllowanceTotal.String() != "432.5" || inv.Totals.ChargeTotal.String() != "120" {
+		t.Fatalf("allowance/charge totals = %s/%s", inv.Totals.AllowanceTotal, inv.Totals.ChargeTotal)
+	}
+	if line := inv.Lines[0]; line.Allowance.String() != "500" || !line.Charge.IsZero() {
+		t.Fatalf("chair line allowance/charge = %s/%s", line.Allowance, line.Charge)
+	}
+	if line := inv.Lines[1]; line.Charge.String() != "150" || line.ChargeReason != "Assembly" {
+		t.Fatalf("desk line charge = %s %q", line.Charge, line.ChargeReason)
+	}
+}
+
+func TestInvoiceAdjustmentsWithTaxRates(t *testing.T) {
+	params := &core.InvoiceParams{
+		Currency: "JPY",
+		Summary: core.InvoiceSummary{
+			TaxRate: decimal.RequireFromString("0.1"),
+			Adjustments: []core.InvoiceAdjustment{
+				{Kind: core.AdjustmentDiscount, Percent: decimal.NewFromInt(3)},
+				{Kind: core.AdjustmentSurcharge, Title: "Cool delivery", Amount: decimal.NewFromInt(1000), TaxRate: decimal.RequireFromString("0.08")},
+			},
+		},
+		DetailItems: []core.InvoiceDetailItem{
+			{Title: "Rice", TotalExcludeTax: decimal.NewFromInt(10000), TaxRate: decimal.RequireFromString("0.08")},
+			{Title: "Kitchen towels", TotalExcludeTax: decimal.NewFromInt(5555), TaxRate: decimal.RequireFromString("0.1")},
+		},
+	}
+	b, err := NewInvoiceBuilder(Config{}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+	nums := b.invoiceSummaryNumbers()
+	// 3% of 15555 rounds to 467 yen; the untagged discount falls into the summary's 10% group.
+	if nums.Adjustments[0].Amount.String() != "-467" || nums.Adjustments[0].Label != "Discount (3%)" {
+		t.Fatalf("discount = %+v", nums.Adjustments[0])
+	}
+	if len(nums.TaxBreakdown) != 2 {
+		t.Fatalf("breakdown = %+v", nums.TaxBreakdown)
+	}
+	standard, reduced := nums.TaxBreakdown[0], nums.TaxBreakdown[1]
+	if standard.Base.String() != "5088" || standard.Tax.String() != "509" {
+		t.Fatalf("10%% group = %s/%s, want 5088/509", standard.Base, standard.Tax)
+	}
+	if reduced.Base.String() != "11000" || reduced.Tax.String() != "880" {
+		t.Fatalf("8%% group = %s/%s, want 11000/880", reduced.Base, reduced.Tax)
+	}
+	if nums.Subtotal.String() != "16088" || nums.Total.String() != "17477" {
+		t.Fatalf("subtotal/total = %s/%s, want 16088/17477", nums.Subtotal, nums.Total)
+	}
+}
+
+func TestCreditNoteNegatesAdjustments(t *testing.T) {
+	params := &core.CreditNoteParams{
+		Currency: "USD",
+		Summary: core.InvoiceSummary{
+			TotalExcludeTax: decimal.NewFromInt(950),
+			Adjustments:     []core.InvoiceAdjustment{{Kind: core.AdjustmentDiscount, Amount: decimal.NewFromInt(50)}},
+		},
+		DetailItems: []core.InvoiceDetailItem{
+			{Title: "Refund", Quantity: decimal.NewFromInt(1), UnitPrice: decimal.NewFromInt(1100),
+				Adjustments: []core.InvoiceAdjustment{{Kind: core.AdjustmentDiscount, Percent: decimal.NewFromInt(10)}}},
+		},
+	}
+	b, err := NewCreditNoteBuilder(Config{}, params)
+	if err != nil {
+		t.Fatalf("NewCreditNoteBuilder: %v", err)
+	}
+	nums := b.invoiceSummaryNumbers()
+	if nums.ItemsTotal.String() != "-1000" || nums.Subtotal.String() != "-950" {
+		t.Fatalf("items/subtotal = %s/%s, want -1000/-950", nums.ItemsTotal, nums.Subtotal)
+	}
+	if params.Summary.Adjustments[0].Amount.String() != "50" {
+		t.Fatal("credit note params were modified")
+	}
+}
@@ -0,0 +1,79 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+func TestInvoiceLineAmountsFromUnitPrice(t *testing.T) {
+	b, err := NewInvoiceBuilder(Config{}, &core.InvoiceParams{Currency: "JPY"})
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+
+	amounts := b.invoiceLineAmounts(core.InvoiceDetailItem{
+		Quantity:  decimal.NewFromInt(3),
+		UnitPrice: decimal.NewFromInt(15000),
+		Discount:  decimal.NewFromInt(8000),
+		Tax:       decimal.NewFromInt(3700),
+	})
+	if !amounts.ExcludeTax.Equal(decimal.NewFromInt(37000)) {
+		t.Fatalf("ExcludeTax = %s, want 37000", amounts.ExcludeTax)
+	}
+	if !amounts.IncludeTax.Equal(decimal.NewFromInt(40700)) {
+		t.Fatalf("IncludeTax = %s, want 40700", amounts.IncludeTax)
+	}
+
+	amounts = b.invoiceLineAmounts(core.InvoiceDetailItem{UnitPrice: decimal.NewFromInt(99)})
+	if !amounts.Quantity.Equal(decimal.NewFromInt(1)) || !amounts.ExcludeTax.Equal(decimal.NewFromInt(99)) {
+		t.Fatalf("missing quantity: got %s × = %s, want 1 × = 99", amounts.Quantity, amounts.ExcludeTax)
+	}
+
+	amounts = b.invoiceLineAmounts(core.InvoiceDetailItem{
+		Quantity:        decimal.NewFromInt(2),
+		UnitPrice:       decimal.NewFromInt(10),
+		TotalExcludeTax: decimal.NewFromInt(25),
+	})
+	if !amounts.ExcludeTax.Equal(decimal.NewFromInt(25)) {
+		t.Fatalf("explicit total: ExcludeTax = %s, want 25", amounts.ExcludeTax)
+	}
+}
+
+func TestInvoiceDiscountRowOnlyForPricedLines(t *testing.T) {
+	params := &core.InvoiceParams{Currency: "JPY", DetailItems: []core.InvoiceDetailItem{{
+		Title:     "Seat License",
+		Quantity:  decimal.NewFromInt(3),
+		UnitPrice: decimal.NewFromInt(15000),
+		Discount:  decimal.NewFromInt(8000),
+	}}}
+	b, err := NewInvoiceBuilder(Config{}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+	priced := len(b.BuildInvoiceDetailsRows())
+
+	// An explicit total is not reduced by the discount, so the discount row is left out.
+	params.DetailItems[0].TotalExcludeTax = decimal.NewFromInt(45000)
+	if got := len(b.BuildInvoiceDetailsRows()); got != priced-1 {
+		t.Fatalf("len(detail rows) = %d with an explicit total, want %d", got, priced-1)
+	}
+}
+
+func TestGenerateInvoicePricedLinesAllLayouts(t *testing.T) {
+	params := &core.InvoiceParams{}
+	if err := params.Load("../samples/invoice-3.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	for _, layout := range BuiltinLayoutNames() {
+		b, err := NewInvoiceBuilder(Config{InvoiceLayout: layout}, params)
+		if err != nil {
+			t.Fatalf("NewInvoiceBuilder(%s): %v", layout, err)
+		}
+		buf, err := b.GenerateInvoice()
+		if err != nil || len(buf) == 0 {
+			t.Fatalf("GenerateInvoice(%s): %v", layout, err)
+		}
+	}
+}
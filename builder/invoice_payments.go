@@ -0,0 +1,135 @@
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/border"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	marotoCore "github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+// invoiceBalance returns the amount paid and the balance due against the amount payable. ok is
+// false when the document does not track a balance, no payment is recorded, or a payment is in
+// another currency than the summary, so the two cannot be netted.
+func (b *Builder) invoiceBalance() (paid, balance decimal.Decimal, ok bool) {
+	if !b.kind.tracksBalance {
+		return decimal.Zero, decimal.Zero, false
+	}
+	results := b.iParams.Payment.PaymentResults()
+	if len(results) == 0 {
+		return decimal.Zero, decimal.Zero, false
+	}
+	summary := b.invoiceSummaryNumbers()
+	for _, result := range results {
+		if !sameCurrency(b.paymentResultCurrency(result), summary.BaseCurrency) {
+			return decimal.Zero, decimal.Zero, false
+		}
+		paid = paid.Add(result.Amount)
+	}
+	return paid, summary.Payable.Sub(paid), true
+}
+
+// invoiceBalanceLines returns the "Amount Paid" and "Balance Due" lines for the inline summaries
+// of the layouts, or empty strings when there is no balance to show.
+func (b *Builder) invoiceBalanceLines() (paidLine, balanceLine string) {
+	paid, balance, ok := b.invoiceBalance()
+	if !ok {
+		return "", ""
+	}
+	currency := b.invoiceSummaryNumbers().BaseCurrency
+	tPaid := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceAmountPaid", nil)
+	tBalance := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceBalanceDue", nil)
+	return fmt.Sprintf("%s: %s", tPaid, b.formatMoney(paid, currency)),
+		fmt.Sprintf("%s: %s", tBalance, b.formatMoney(balance, currency))
+}
+
+// buildInvoiceBalanceRows renders the amount paid and balance due below the summary total.
+func (b *Builder) buildInvoiceBalanceRows() []marotoCore.Row {
+	paid, balance, ok := b.invoiceBalance()
+	if !ok {
+		return nil
+	}
+	currency := b.invoiceSummaryNumbers().BaseCurrency
+	tPaid := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceAmountPaid", nil)
+	tBalance := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceBalanceDue", nil)
+	return []marotoCore.Row{
+		row.New(8).Add(
+			text.NewCol(6, tPaid, props.Text{Size: 9, Top: 2, Align: align.Left, Color: b.fgColor}),
+			text.NewCol(6, b.formatMoney(paid.Neg(), currency), props.Text{Size: 9, Top: 2, Align: align.Right, Color: b.fgColor}),
+		),
+		row.New(10).Add(
+			text.NewCol(6, tBalance, props.Text{Size: 10, Top: 4, Align: align.Left, Style: fontstyle.Bold, Color: b.fgColor}),
+			text.NewCol(6, b.formatMoney(balance, currency), props.Text{Size: 10, Top: 4, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
+		),
+	}
+}
+
+// buildInvoicePaymentsTableRows renders one line per recorded payment: date, method, transaction
+// ID and amount. The balance due is part of the summary.
+func (b *Builder) buildInvoicePaymentsTableRows() []marotoCore.Row {
+	tPaidDate := b.i18nBundle.MusT(b.cfg.Lang, b.labelKey("InvoicePaymentResultPaidDate"), nil)
+	tMethod := b.i18nBundle.MusT(b.cfg.Lang, "InvoicePaymentResultMethod", nil)
+	tTxID := b.i18nBundle.MusT(b.cfg.Lang, "InvoicePaymentResultTxID", nil)
+	tAmount := b.i18nBundle.MusT(b.cfg.Lang, b.labelKey("InvoicePaymentResultAmount"), nil)
+
+	borderBottomStyle := &props.Cell{
+		BorderType:  border.Bottom,
+		BorderColor: b.borderColor,
+	}
+	headerProps := props.Text{Size: 8, Top: 2, Align: align.Left, Color: b.fgSecondaryColor}
+	rows := []marotoCore.Row{
+		row.New(8).WithStyle(borderBottomStyle).Add(
+			text.NewCol(2, tPaidDate, headerProps),
+			text.NewCol(3, tMethod, headerProps),
+			text.NewCol(4, tTxID, headerProps),
+			text.NewCol(3, tAmount, props.Text{Size: 8, Top: 2, Align: align.Right, Color: b.fgSecondaryColor}),
+		),
+	}
+
+	for ix, result := range b.iParams.Payment.PaymentResults() {
+		top := 1.0
+		height := 6.0
+		if ix == 0 {
+			top, height = 3, 8
+		}
+		paidDate := ""
+		if !result.PaidDate.IsZero() {
+			paidDate = result.PaidDate.Format("2006-01-02")
+		}
+		cellProps := props.Text{Size: 9, Top: top, Align: align.Left, Color: b.fgColor}
+		rows = append(rows, row.New(height).Add(
+			text.NewCol(2, paidDate, cellProps),
+			text.NewCol(3, result.PaymentMethod, cellProps),
+			text.NewCol(4, result.TxID, props.Text{Size: 8, Top: top, Align: align.Left, Color: b.fgSecondaryColor}),
+			text.NewCol(3, b.formatMoney(result.Amount, b.paymentResultCurrency(result)), props.Text{Size: 9, Top: top, Align: align.Right, Color: b.fgColor}),
+		))
+	}
+
+	return append(rows, row.New(2).WithStyle(borderBottomStyle).Add(col.New(12)))
+}
+
+func (b *Builder) paymentResultCurrency(result core.InvoicePaymentResult) string {
+	if currency := strings.TrimSpace(result.Currency); currency != "" {
+		return currency
+	}
+	return strings.TrimSpace(b.iParams.Currency)
+}
+
+// sameCurrency compares currency codes or symbols, e.g. "JPY" and "円".
+func sameCurrency(a, b string) bool {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	if a == b {
+		return true
+	}
+	curA, okA := core.LookupCurrency(a)
+	curB, okB := core.LookupCurrency(b)
+	return okA && okB && curA.Code == curB.Code
+}
@@ -0,0 +1,85 @@
+package builder
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+func TestInvoiceBalanceDue(t *testing.T) {
+	params := &core.InvoiceParams{}
+	if err := params.Load("../samples/invoice-6.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	b, err := NewInvoiceBuilder(Config{ValidateParams: true}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+	paid, balance, ok := b.invoiceBalance()
+	if !ok || paid.String() != "22000" || balance.String() != "11000" {
+		t.Fatalf("invoiceBalance() = %s, %s, %v; want 22000, 11000, true", paid, balance, ok)
+	}
+	labels := map[string][2]string{
+		"en":    {"Amount Paid", "Balance Due"},
+		"ja":    {"お支払済額", "残額"},
+		"zh_cn": {"已付金额", "应付余额"},
+		"zh_tw": {"已付金額", "應付餘額"},
+	}
+	for lang, want := range labels {
+		b.cfg.Lang = lang
+		texts := rowTexts(b.buildInvoiceBalanceRows())
+		if len(texts) != 4 || texts[0] != want[0] || texts[2] != want[1] {
+			t.Errorf("%s balance rows = %q, want the labels %q", lang, texts, want)
+		}
+	}
+	b.cfg.Lang = "en"
+	paidLine, balanceLine := b.invoiceBalanceLines()
+	if paidLine != "Amount Paid: $22,000.00" || balanceLine != "Balance Due: $11,000.00" {
+		t.Fatalf("balance lines = %q, %q", paidLine, balanceLine)
+	}
+	if !b.showInvoicePaymentResult() {
+		t.Fatal("payments table hidden")
+	}
+	// Title, column header, two payments and the closing rule.
+	if rows := b.BuildInvoicePaymentResultRows(); len(rows) != 5 {
+		t.Fatalf("len(payment rows) = %d, want 5", len(rows))
+	}
+	for _, layout := range BuiltinLayoutNames() {
+		b.cfg.InvoiceLayout = layout
+		buf, err := b.GenerateInvoice()
+		if err != nil || !bytes.HasPrefix(buf, []byte("%PDF")) {
+			t.Fatalf("GenerateInvoice(%s) = %v", layout, err)
+		}
+	}
+
+	inv, err := b.EInvoice()
+	if err != nil {
+		t.Fatalf("EInvoice: %v", err)
+	}
+	if inv.Totals.Prepaid.String() != "22000" || inv.Totals.DuePayable.String() != "11000" {
+		t.Fatalf("e-invoice prepaid/due = %s/%s", inv.Totals.Prepaid, inv.Totals.DuePayable)
+	}
+
+	params.Payment.Results[1].Currency = "EUR"
+	if _, _, ok := b.invoiceBalance(); ok {
+		t.Fatal("balance netted across currencies")
+	}
+
+	params.Payment.Results = nil
+	params.Payment.InvoicePaymentResult = core.InvoicePaymentResult{Amount: decimal.NewFromInt(33000)}
+	if _, balance, ok := b.invoiceBalance(); !ok || !balance.IsZero() {
+		t.Fatalf("single payment balance = %s, %v", balance, ok)
+	}
+}
+
+func TestStatementDoesNotTrackBalance(t *testing.T) {
+	b, err := NewSettlementStatementBuilderFromFile(Config{}, "../samples/settlementstatement-1.yaml")
+	if err != nil {
+		t.Fatalf("NewSettlementStatementBuilderFromFile: %v", err)
+	}
+	if _, _, ok := b.invoiceBalance(); ok {
+		t.Fatal("settlement statement nets its payout against the total")
+	}
+}
@@ -0,0 +1,139 @@
+package builder
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+// Summary checks; see Config.SummaryCheck.
+const (
+	SummaryCheckDerive = "derive"
+	SummaryCheckWarn   = "warn"
+	SummaryCheckFail   = "fail"
+)
+
+func validateSummaryCheck(cfg Config) error {
+	switch normalizeSummaryCheck(cfg.SummaryCheck) {
+	case "", SummaryCheckDerive, SummaryCheckWarn, SummaryCheckFail:
+	default:
+		return fmt.Errorf("unknown summary check %q; use %s, %s or %s", cfg.SummaryCheck, SummaryCheckDerive, SummaryCheckWarn, SummaryCheckFail)
+	}
+	if cfg.SummaryTolerance.IsNegative() {
+		return fmt.Errorf("summary tolerance %s is negative", cfg.SummaryTolerance)
+	}
+	return nil
+}
+
+func normalizeSummaryCheck(check string) string {
+	return strings.ToLower(strings.TrimSpace(check))
+}
+
+// paramsValidator returns the Validate method the Generate* methods run under
+// Config.ValidateParams: without its exact summary comparison when Config.SummaryCheck compares
+// the summary with the detail items instead.
+func paramsValidator(cfg Config, validate, validateWithoutSummaryCheck func() error) func() error {
+	if normalizeSummaryCheck(cfg.SummaryCheck) != "" {
+		return validateWithoutSummaryCheck
+	}
+	return validate
+}
+
+// derivedInvoiceSummary returns the summary with its totals computed from the detail items billed
+// in baseCurrency and the summary adjustments: the total excluding tax when every item has one,
+// otherwise the total including tax. Explicit item taxes make up the tax when every item has one
+// and nothing else is taxed. It returns the summary as entered and false when no item carries an
+// amount, or when the items only have totals including tax and taxed adjustments apply.
+func (b *Builder) derivedInvoiceSummary(baseCurrency string) (core.InvoiceSummary, bool) {
+	summary := b.iParams.Summary
+	var net, gross, itemTax decimal.Decimal
+	found, allNet, allTaxed := false, true, true
+	for _, item := range b.iParams.DetailItems {
+		if b.invoiceItemCurrency(item) != baseCurrency {
+			continue
+		}
+		amounts := b.invoiceLineAmounts(item)
+		if amounts.ExcludeTax.IsZero() && amounts.IncludeTax.IsZero() {
+			continue
+		}
+		found = true
+		net = net.Add(amounts.ExcludeTax)
+		gross = gross.Add(amounts.IncludeTax)
+		itemTax = itemTax.Add(item.Tax)
+		allNet = allNet && !amounts.ExcludeTax.IsZero()
+		allTaxed = allTaxed && !item.Tax.IsZero()
+	}
+	if !found {
+		return summary, false
+	}
+
+	taxed, untaxed := sumInvoiceAdjustments(b.invoiceAdjustments(baseCurrency))
+	summary.TotalExcludeTax, summary.TotalIncludeTax, summary.Tax = decimal.Zero, decimal.Zero, decimal.Zero
+	switch {
+	case allNet:
+		summary.TotalExcludeTax = net.Add(taxed)
+		if allTaxed && taxed.IsZero() && !b.hasInvoiceItemTaxRates() {
+			summary.Tax = itemTax
+		}
+	case taxed.IsZero():
+		summary.TotalIncludeTax = gross.Add(untaxed)
+	default:
+		return b.iParams.Summary, false
+	}
+	return summary, true
+}
+
+// invoiceSummaryMismatches compares the entered summary totals with the ones derived from the
+// detail items and returns those differing by more than Config.SummaryTolerance. Items in other
+// currencies than the summary are not part of it and are not compared.
+func (b *Builder) invoiceSummaryMismatches() core.ValidationErrors {
+	baseCurrency := b.invoiceBaseCurrency()
+	derived, ok := b.derivedInvoiceSummary(baseCurrency)
+	if !ok {
+		return nil
+	}
+	summary := b.iParams.Summary
+	entered := b.invoiceSummaryNumbersOf(summary)
+	computed := b.invoiceSummaryNumbersOf(derived)
+
+	var errs core.ValidationErrors
+	compare := func(name string, value, enteredAmount, computedAmount decimal.Decimal) {
+		if value.IsZero() || enteredAmount.Sub(computedAmount).Abs().LessThanOrEqual(b.cfg.SummaryTolerance) {
+			return
+		}
+		errs = append(errs, core.FieldError{
+			Field: "summary." + name,
+			Message: fmt.Sprintf("is %s but the detail items in %s add up to %s",
+				b.formatMoney(enteredAmount, baseCurrency), baseCurrency, b.formatMoney(computedAmount, baseCurrency)),
+		})
+	}
+	if !derived.TotalExcludeTax.IsZero() {
+		compare("total_exclude_tax", summary.TotalExcludeTax, entered.Subtotal, computed.Subtotal)
+	}
+	compare("tax", summary.Tax, entered.Tax, computed.Tax)
+	compare("total_include_tax", summary.TotalIncludeTax, entered.Total, computed.Total)
+	return errs
+}
+
+// checkInvoiceSummary runs Config.SummaryCheck's warn and fail checks before rendering.
+func (b *Builder) checkInvoiceSummary() error {
+	check := normalizeSummaryCheck(b.cfg.SummaryCheck)
+	if (check != SummaryCheckWarn && check != SummaryCheckFail) || b.iParams == nil || b.hidePrices {
+		return nil
+	}
+	errs := b.invoiceSummaryMismatches()
+	if len(errs) == 0 {
+		return nil
+	}
+	if check == SummaryCheckWarn {
+		for _, err := range errs {
+			log.Printf("warning: %s %s: %v\n", b.invoiceDocTitle(), b.iParams.ID, err)
+		}
+		return nil
+	}
+	log.Printf("failed to check the summary of %s %s: %v\n", b.invoiceDocTitle(), b.iParams.ID, errs)
+	return errs
+}
@@ -0,0 +1,242 @@
+package builder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+// summaryCheckTestParams returns an invoice whose summary states 90 USD plus 10% tax, while its
+// detail items add up to 100 USD next to an item in EUR, which the USD summary leaves out.
+func summaryCheckTestParams() *core.InvoiceParams {
+	params := testInvoiceParams(
+		core.InvoiceDetailItem{Title: "Hosting", TotalExcludeTax: decimal.RequireFromString("40.5")},
+		core.InvoiceDetailItem{Title: "Support", TotalExcludeTax: decimal.RequireFromString("59.5")},
+		core.InvoiceDetailItem{Title: "Travel", TotalExcludeTax: decimal.NewFromInt(500), Currency: "EUR"},
+	)
+	params.Summary.TotalExcludeTax = decimal.NewFromInt(90)
+	params.Summary.TaxRate = decimal.RequireFromString("0.1")
+	return params
+}
+
+func TestInvoiceSummaryCheckDerive(t *testing.T) {
+	params := summaryCheckTestParams()
+	b, err := NewInvoiceBuilder(Config{SummaryCheck: SummaryCheckDerive}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+	nums := b.invoiceSummaryNumbers()
+	if !nums.Subtotal.Equal(decimal.NewFromInt(100)) || !nums.Tax.Equal(decimal.NewFromInt(10)) || !nums.Total.Equal(decimal.NewFromInt(110)) {
+		t.Fatalf("summary = %s + %s = %s, want 100 + 10 = 110", nums.Subtotal, nums.Tax, nums.Total)
+	}
+	if err := b.checkParams(); err != nil {
+		t.Fatalf("checkParams: %v", err)
+	}
+}
+
+func TestInvoiceSummaryCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		check     string
+		tolerance string
+		mutate    func(*core.InvoiceParams)
+		want      []string
+	}{
+		{name: "fail", check: SummaryCheckFail, want: []string{"summary.total_exclude_tax"}},
+		{name: "warn", check: SummaryCheckWarn},
+		{name: "off", check: ""},
+		{name: "tolerance", check: "FAIL", tolerance: "10"},
+		{name: "below tolerance", check: SummaryCheckFail, tolerance: "9.99", want: []string{"summary.total_exclude_tax"}},
+		{
+			name:  "matching",
+			check: SummaryCheckFail,
+			mutate: func(p *core.InvoiceParams) {
+				p.Summary.TotalExcludeTax = decimal.NewFromInt(100)
+			},
+		},
+		{
+			name:  "total including tax",
+			check: SummaryCheckFail,
+			mutate: func(p *core.InvoiceParams) {
+				p.Summary = core.InvoiceSummary{Title: "Test", TotalIncludeTax: decimal.NewFromInt(108)}
+				for ix := range p.DetailItems {
+					item := &p.DetailItems[ix]
+					item.TotalIncludeTax, item.TotalExcludeTax = item.TotalExcludeTax.Mul(decimal.RequireFromString("1.1")), decimal.Zero
+				}
+			},
+			want: []string{"summary.total_include_tax"},
+		},
+		{
+			name:  "other currency only",
+			check: SummaryCheckFail,
+			mutate: func(p *core.InvoiceParams) {
+				p.DetailItems = p.DetailItems[2:]
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := summaryCheckTestParams()
+			if tt.mutate != nil {
+				tt.mutate(params)
+			}
+			cfg := Config{SummaryCheck: tt.check}
+			if tt.tolerance != "" {
+				cfg.SummaryTolerance = decimal.RequireFromString(tt.tolerance)
+			}
+			b, err := NewInvoiceBuilder(cfg, params)
+			if err != nil {
+				t.Fatalf("NewInvoiceBuilder: %v", err)
+			}
+			err = b.checkParams()
+			var errs core.ValidationErrors
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("checkParams: %v", err)
+				}
+				return
+			}
+			if !errors.As(err, &errs) || len(errs) != len(tt.want) {
+				t.Fatalf("checkParams = %v, want errors for %v", err, tt.want)
+			}
+			for ix, field := range tt.want {
+				if errs[ix].Field != field {
+					t.Errorf("error %d = %v, want %s", ix, errs[ix], field)
+				}
+			}
+		})
+	}
+
+	b, _ := NewInvoiceBuilder(Config{SummaryCheck: SummaryCheckFail}, summaryCheckTestParams())
+	want := "invalid params: summary.total_exclude_tax: is $90.00 but the detail items in USD add up to $100.00"
+	if err := b.checkParams(); err == nil || err.Error() != want {
+		t.Fatalf("checkParams = %v, want %q", err, want)
+	}
+}
+
+func TestInvoiceSummaryCheckReplacesValidateComparison(t *testing.T) {
+	params := &core.InvoiceParams{}
+	if err := params.Load("../samples/invoice-1.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	params.Summary.TotalExcludeTax = decimal.RequireFromString("100.01")
+	params.DetailItems[0].TotalExcludeTax = decimal.NewFromInt(60)
+	params.DetailItems[1].TotalExcludeTax = decimal.NewFromInt(40)
+
+	b, err := NewInvoiceBuilder(Config{ValidateParams: true}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+	if err := b.checkParams(); err == nil {
+		t.Fatal("checkParams without a summary check succeeded, want the exact comparison of Validate")
+	}
+
+	for _, cfg := range []Config{
+		{ValidateParams: true, SummaryCheck: SummaryCheckWarn},
+		{ValidateParams: true, SummaryCheck: SummaryCheckFail, SummaryTolerance: decimal.RequireFromString("0.05")},
+		{ValidateParams: true, SummaryCheck: SummaryCheckWarn, SummaryTolerance: decimal.RequireFromString("0.05")},
+	} {
+		b, err := NewInvoiceBuilder(cfg, params)
+		if err != nil {
+			t.Fatalf("NewInvoiceBuilder: %v", err)
+		}
+		if _, err := b.GenerateInvoice(); err != nil {
+			t.Errorf("GenerateInvoice(%s, tolerance %s) = %v, want the invoice", cfg.SummaryCheck, cfg.SummaryTolerance, err)
+		}
+	}
+}
+
+// TestDocumentSummaryCheckReplacesValidateComparison puts the summary of every other document type
+// one minor unit off its detail items, within a tolerance of 1.
+func TestDocumentSummaryCheckReplacesValidateComparison(t *testing.T) {
+	load := func(t *testing.T, filename string, params interface{ Load(string) error }) {
+		t.Helper()
+		if err := params.Load(filename); err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+	}
+	tests := []struct {
+		name  string
+		build func(t *testing.T, cfg Config) (*Builder, error)
+	}{
+		{"receipt", func(t *testing.T, cfg Config) (*Builder, error) {
+			params := &core.InvoiceParams{}
+			load(t, "../samples/invoice-6.yaml", params)
+			params.Summary.TotalExcludeTax = decimal.RequireFromString("30000.01")
+			return NewReceiptBuilder(cfg, params)
+		}},
+		{"credit note", func(t *testing.T, cfg Config) (*Builder, error) {
+			params := &core.CreditNoteParams{}
+			load(t, "../samples/creditnote-1.yaml", params)
+			params.Summary.TotalExcludeTax = decimal.RequireFromString("450.01")
+			return NewCreditNoteBuilder(cfg, params)
+		}},
+		{"quote", func(t *testing.T, cfg Config) (*Builder, error) {
+			params := &core.QuoteParams{}
+			load(t, "../samples/quote-1.yaml", params)
+			params.Summary.TotalExcludeTax = decimal.NewFromInt(517001)
+			return NewQuoteBuilder(cfg, params)
+		}},
+		{"purchase order", func(t *testing.T, cfg Config) (*Builder, error) {
+			params := &core.PurchaseOrderParams{}
+			load(t, "../samples/purchaseorder-1.yaml", params)
+			params.Summary.TotalExcludeTax = decimal.NewFromInt(182001)
+			return NewPurchaseOrderBuilder(cfg, params)
+		}},
+		{"settlement statement", func(t *testing.T, cfg Config) (*Builder, error) {
+			params := &core.SettlementStatementParams{}
+			load(t, "../samples/settlementstatement-1.yaml", params)
+			params.Summary.TotalExcludeTax = decimal.RequireFromString("500000.01")
+			params.DetailItems[0].TotalExcludeTax = decimal.NewFromInt(300000)
+			params.DetailItems[1].TotalExcludeTax = decimal.NewFromInt(200000)
+			return NewSettlementStatementBuilder(cfg, params)
+		}},
+		{"delivery note", func(t *testing.T, cfg Config) (*Builder, error) {
+			params := &core.DeliveryNoteParams{}
+			load(t, "../samples/deliverynote-1.yaml", params)
+			params.HidePrices = false
+			params.Currency = "JPY"
+			params.Summary = core.InvoiceSummary{Title: "Office furniture", TotalExcludeTax: decimal.NewFromInt(182001), TaxRate: decimal.RequireFromString("0.1")}
+			params.DetailItems[0].UnitPrice = decimal.NewFromInt(38000)
+			params.DetailItems[1].UnitPrice = decimal.NewFromInt(7500)
+			return NewDeliveryNoteBuilder(cfg, params)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := tt.build(t, Config{ValidateParams: true})
+			if err != nil {
+				t.Fatalf("build: %v", err)
+			}
+			if err := b.checkParams(); err == nil {
+				t.Fatal("checkParams without a summary check succeeded, want the exact comparison of Validate")
+			}
+			for _, cfg := range []Config{
+				{ValidateParams: true, SummaryCheck: SummaryCheckWarn},
+				{ValidateParams: true, SummaryCheck: SummaryCheckFail, SummaryTolerance: decimal.NewFromInt(1)},
+				{ValidateParams: true, SummaryCheck: SummaryCheckDerive},
+			} {
+				b, err := tt.build(t, cfg)
+				if err != nil {
+					t.Fatalf("build: %v", err)
+				}
+				if err := b.checkParams(); err != nil {
+					t.Errorf("checkParams(%s, tolerance %s) = %v, want no error", cfg.SummaryCheck, cfg.SummaryTolerance, err)
+				}
+			}
+		})
+	}
+}
+
+func TestInvoiceSummaryCheckConfigErrors(t *testing.T) {
+	for _, cfg := range []Config{
+		{SummaryCheck: "strict"},
+		{SummaryCheck: SummaryCheckFail, SummaryTolerance: decimal.NewFromInt(-1)},
+	} {
+		if _, err := NewInvoiceBuilder(cfg, testInvoiceParams()); err == nil {
+			t.Errorf("NewInvoiceBuilder(%+v) succeeded, want an error", cfg)
+		}
+	}
+}
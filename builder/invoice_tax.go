@@ -0,0 +1,271 @@
+package builder
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	marotoCore "github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+type invoiceTaxBreakdown struct {
+	Category string
+	Rate     decimal.Decimal
+	Base     decimal.Decimal
+	Tax      decimal.Decimal
+	// Reason joins the distinct exemption reasons stated by the group's items.
+	Reason string
+}
+
+// invoiceTaxBreakdown groups the detail items billed in baseCurrency and the taxed summary
+// adjustments by tax category and rate. Explicit item taxes are summed as-is; the remaining taxable
+// base of each group is taxed once, or line by line with RoundPerLine. Qualified invoices ignore
+// explicit item taxes so that every group is rounded exactly once. It returns nil unless at least
+// one item or adjustment carries its own tax category or rate.
+func (b *Builder) invoiceTaxBreakdown(baseCurrency string, adjustments []invoiceAdjustment) []invoiceTaxBreakdown {
+	if !b.hasInvoiceItemTaxRates() {
+		return nil
+	}
+
+	groups := make([]invoiceTaxBreakdown, 0, 2)
+	untaxedBases := make([]decimal.Decimal, 0, 2)
+	index := make(map[string]int)
+	group := func(category string, rate decimal.Decimal) int {
+		key := category + "|" + rate.String()
+		ix, ok := index[key]
+		if !ok {
+			ix = len(groups)
+			index[key] = ix
+			groups = append(groups, invoiceTaxBreakdown{Category: category, Rate: rate})
+			untaxedBases = append(untaxedBases, decimal.Zero)
+		}
+		return ix
+	}
+	for _, item := range b.iParams.DetailItems {
+		if b.invoiceItemCurrency(item) != baseCurrency {
+			continue
+		}
+		amounts := b.invoiceLineAmounts(item)
+		if amounts.ExcludeTax.IsZero() {
+			continue
+		}
+
+		ix := group(b.invoiceItemTax(item))
+		groups[ix].Base = groups[ix].Base.Add(amounts.ExcludeTax)
+		groups[ix].Reason = joinTaxExemptionReason(groups[ix].Reason, item.TaxExemptionReason)
+		switch {
+		case !item.Tax.IsZero() && !b.jpQualifiedInvoice():
+			groups[ix].Tax = groups[ix].Tax.Add(item.Tax)
+		case b.roundPerLine():
+			groups[ix].Tax = groups[ix].Tax.Add(b.roundTax(amounts.ExcludeTax.Mul(groups[ix].Rate), baseCurrency))
+		default:
+			untaxedBases[ix] = untaxedBases[ix].Add(amounts.ExcludeTax)
+		}
+	}
+	for _, adj := range adjustments {
+		if adj.Untaxed || adj.Amount.IsZero() {
+			continue
+		}
+		ix := group(adj.Category, adj.Rate)
+		groups[ix].Base = groups[ix].Base.Add(adj.Amount)
+		if b.roundPerLine() {
+			groups[ix].Tax = groups[ix].Tax.Add(b.roundTax(adj.Amount.Mul(adj.Rate), baseCurrency))
+		} else {
+			untaxedBases[ix] = untaxedBases[ix].Add(adj.Amount)
+		}
+	}
+
+	for ix := range groups {
+		groups[ix].Tax = groups[ix].Tax.Add(b.roundTax(untaxedBases[ix].Mul(groups[ix].Rate), baseCurrency))
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Rate.GreaterThan(groups[j].Rate)
+	})
+	return groups
+}
+
+// invoiceItemTax resolves the tax category and rate of a detail item.
+func (b *Builder) invoiceItemTax(item core.InvoiceDetailItem) (string, decimal.Decimal) {
+	category := strings.ToUpper(strings.TrimSpace(item.TaxCategory))
+	rate := item.TaxRate
+	if rate.IsZero() && category == "" {
+		rate = b.iParams.Summary.TaxRate
+	}
+	if category == core.TaxCategoryExempt || category == core.TaxCategoryZeroRated || category == core.TaxCategoryReverseCharge {
+		rate = decimal.Zero
+	}
+	if category == "" {
+		category = core.TaxCategoryStandard
+		if rate.IsZero() {
+			category = core.TaxCategoryZeroRated
+		}
+	}
+	return category, rate
+}
+
+// joinTaxExemptionReason appends reason to the "; "-separated reasons unless it is already listed.
+func joinTaxExemptionReason(reasons, reason string) string {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return reasons
+	}
+	if reasons == "" {
+		return reason
+	}
+	for _, r := range strings.Split(reasons, "; ") {
+		if r == reason {
+			return reasons
+		}
+	}
+	return reasons + "; " + reason
+}
+
+func (b *Builder) hasInvoiceItemTaxRates() bool {
+	if b.iParams == nil {
+		return false
+	}
+	for _, item := range b.iParams.DetailItems {
+		if strings.TrimSpace(item.TaxCategory) != "" || !item.TaxRate.IsZero() {
+			return true
+		}
+	}
+	for _, adj := range b.iParams.Summary.Adjustments {
+		if !adj.Untaxed && (strings.TrimSpace(adj.TaxCategory) != "" || !adj.TaxRate.IsZero()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Builder) invoiceItemCurrency(item core.InvoiceDetailItem) string {
+	currency := strings.TrimSpace(item.Currency)
+	if currency == "" {
+		currency = strings.TrimSpace(b.iParams.Currency)
+	}
+	return currency
+}
+
+// invoiceBaseCurrency returns the currency of the summary, by default the document currency.
+func (b *Builder) invoiceBaseCurrency() string {
+	if currency := strings.TrimSpace(b.iParams.Summary.Currency); currency != "" {
+		return currency
+	}
+	return strings.TrimSpace(b.iParams.Currency)
+}
+
+func sumInvoiceTaxBreakdown(groups []invoiceTaxBreakdown) (base, tax decimal.Decimal) {
+	for _, group := range groups {
+		base = base.Add(group.Base)
+		tax = tax.Add(group.Tax)
+	}
+	return base, tax
+}
+
+// BuildInvoiceTaxBreakdownRows renders the per-rate taxable base and tax table.
+// It returns no rows when the detail items do not carry their own tax rates or the document hides prices.
+func (b *Builder) BuildInvoiceTaxBreakdownRows() []marotoCore.Row {
+	return b.buildInvoiceTaxBreakdownRows(b.invoiceSummaryNumbers())
+}
+
+func (b *Builder) buildInvoiceTaxBreakdownRows(summary invoiceSummaryNumbers) []marotoCore.Row {
+	if b.hidePrices || len(summary.TaxBreakdown) == 0 {
+		return nil
+	}
+
+	tRate := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceSummaryTaxRate", nil)
+	tTaxable := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceSummaryTaxableAmount", nil)
+	tTax := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceSummaryTaxAmount", nil)
+	currency := summary.BaseCurrency
+
+	rows := []marotoCore.Row{
+		row.New(6).Add(
+			text.NewCol(4, tRate, props.Text{Size: 8, Top: 0, Align: align.Left, Color: b.fgSecondaryColor}),
+			text.NewCol(4, tTaxable, props.Text{Size: 8, Top: 0, Align: align.Right, Color: b.fgSecondaryColor}),
+			text.NewCol(4, tTax, props.Text{Size: 8, Top: 0, Align: align.Right, Color: b.fgSecondaryColor}),
+		),
+	}
+	for _, group := range summary.TaxBreakdown {
+		rows = append(rows, row.New(6).Add(
+			text.NewCol(4, b.invoiceTaxRateLabel(group), props.Text{Size: 8, Top: 0, Align: align.Left, Color: b.fgColor}),
+			text.NewCol(4, b.formatMoney(group.Base, currency), props.Text{Size: 8, Top: 0, Align: align.Right, Color: b.fgColor}),
+			text.NewCol(4, b.formatMoney(group.Tax, currency), props.Text{Size: 8, Top: 0, Align: align.Right, Color: b.fgColor}),
+		))
+	}
+	return rows
+}
+
+func (b *Builder) invoiceTaxRateLabel(group invoiceTaxBreakdown) string {
+	percent := group.Rate.Mul(decimal.NewFromInt(100))
+	if b.jpQualifiedInvoice() && group.Category == core.TaxCategoryStandard {
+		return b.i18nBundle.MusT(b.cfg.Lang, "InvoiceSummaryTaxRateTarget", map[string]any{"Rate": percent.String()})
+	}
+	label := fmt.Sprintf("%s%%", percent)
+	if group.Category != core.TaxCategoryStandard {
+		label = fmt.Sprintf("%s (%s)", label, group.Category)
+	}
+	return label
+}
+
+// invoiceTaxExemptionReason returns the exemption reason of an exempt or reverse-charge group: the
+// reasons its items state, or else the category's name. It is empty for other categories.
+func (b *Builder) invoiceTaxExemptionReason(group invoiceTaxBreakdown) string {
+	if group.Reason != "" {
+		return group.Reason
+	}
+	switch group.Category {
+	case core.TaxCategoryExempt:
+		return b.i18nBundle.MusT(b.cfg.Lang, "InvoiceTaxExempt", nil)
+	case core.TaxCategoryReverseCharge:
+		return b.i18nBundle.MusT(b.cfg.Lang, "InvoiceReverseCharge", nil)
+	}
+	return ""
+}
+
+// invoiceTaxNotes returns the notes exempt and reverse-charge tax groups must carry. Without a
+// stated reason, reverse charge gets the wording of Article 226(11a) of Directive 2006/112/EC.
+func (b *Builder) invoiceTaxNotes(breakdown []invoiceTaxBreakdown) []string {
+	var notes []string
+	for _, group := range breakdown {
+		var note string
+		switch group.Category {
+		case core.TaxCategoryReverseCharge:
+			if group.Reason == "" {
+				notes = append(notes, b.i18nBundle.MusT(b.cfg.Lang, "InvoiceReverseChargeNote", nil))
+				continue
+			}
+			note = b.i18nBundle.MusT(b.cfg.Lang, "InvoiceReverseCharge", nil)
+		case core.TaxCategoryExempt:
+			note = b.i18nBundle.MusT(b.cfg.Lang, "InvoiceTaxExempt", nil)
+		default:
+			continue
+		}
+		if group.Reason != "" {
+			note = fmt.Sprintf("%s: %s", note, group.Reason)
+		}
+		notes = append(notes, note)
+	}
+	return notes
+}
+
+// buildInvoiceTaxNoteRows renders the exemption and reverse-charge notes below the details.
+func (b *Builder) buildInvoiceTaxNoteRows() []marotoCore.Row {
+	if b.hidePrices {
+		return nil
+	}
+	notes := b.invoiceTaxNotes(b.invoiceSummaryNumbers().TaxBreakdown)
+	rows := make([]marotoCore.Row, 0, len(notes))
+	for _, note := range notes {
+		rows = append(rows, row.New(6).Add(
+			col.New(2),
+			text.NewCol(10, note, props.Text{Size: 8, Top: 0, Align: align.Left, Color: b.fgSecondaryColor}),
+		))
+	}
+	return rows
+}
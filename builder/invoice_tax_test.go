@@ -0,0 +1,131 @@
+package builder
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+func TestInvoiceSummaryTaxBreakdownByRate(t *testing.T) {
+	params := &core.InvoiceParams{}
+	if err := params.Load("../samples/invoice-4.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	b, err := NewInvoiceBuilder(Config{Lang: "ja"}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+
+	nums := b.invoiceSummaryNumbers()
+	if len(nums.TaxBreakdown) != 2 {
+		t.Fatalf("len(TaxBreakdown) = %d, want 2", len(nums.TaxBreakdown))
+	}
+	want := []struct{ rate, base, tax int64 }{
+		{10, 50000, 5000},
+		{8, 36900, 2952},
+	}
+	for ix, w := range want {
+		group := nums.TaxBreakdown[ix]
+		if !group.Rate.Equal(decimal.New(w.rate, -2)) || !group.Base.Equal(decimal.NewFromInt(w.base)) || !group.Tax.Equal(decimal.NewFromInt(w.tax)) {
+			t.Fatalf("TaxBreakdown[%d] = %s%% base %s tax %s, want %d%% base %d tax %d",
+				ix, group.Rate.Shift(2), group.Base, group.Tax, w.rate, w.base, w.tax)
+		}
+	}
+	if !nums.Subtotal.Equal(decimal.NewFromInt(86900)) || !nums.Tax.Equal(decimal.NewFromInt(7952)) || !nums.Total.Equal(decimal.NewFromInt(94852)) {
+		t.Fatalf("summary = %s + %s = %s, want 86900 + 7952 = 94852", nums.Subtotal, nums.Tax, nums.Total)
+	}
+
+	for _, layout := range BuiltinLayoutNames() {
+		b.cfg.InvoiceLayout = layout
+		if _, err := b.GenerateInvoice(); err != nil {
+			t.Fatalf("GenerateInvoice(%s): %v", layout, err)
+		}
+	}
+}
+
+func TestInvoiceSummaryWithoutItemRatesKeepsSingleRate(t *testing.T) {
+	params := &core.InvoiceParams{
+		Currency: "USD",
+		Summary: core.InvoiceSummary{
+			TotalExcludeTax: decimal.NewFromInt(1000),
+			TaxRate:         decimal.New(1, -1),
+		},
+		DetailItems: []core.InvoiceDetailItem{{TotalExcludeTax: decimal.NewFromInt(1000)}},
+	}
+	b, err := NewInvoiceBuilder(Config{}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+	nums := b.invoiceSummaryNumbers()
+	if len(nums.TaxBreakdown) != 0 {
+		t.Fatalf("len(TaxBreakdown) = %d, want 0", len(nums.TaxBreakdown))
+	}
+	if !nums.Tax.Equal(decimal.NewFromInt(100)) {
+		t.Fatalf("Tax = %s, want 100", nums.Tax)
+	}
+}
+
+func TestInvoiceReverseChargeAndExemption(t *testing.T) {
+	params := &core.InvoiceParams{}
+	if err := params.Load("../samples/invoice-9.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	b, err := NewInvoiceBuilder(Config{ValidateParams: true, Lang: "en"}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+
+	nums := b.invoiceSummaryNumbers()
+	if !nums.Tax.IsZero() || nums.Total.String() != "5700" || len(nums.TaxBreakdown) != 2 {
+		t.Fatalf("tax/total/breakdown = %s/%s/%+v", nums.Tax, nums.Total, nums.TaxBreakdown)
+	}
+	notes := b.invoiceTaxNotes(nums.TaxBreakdown)
+	want := []string{
+		"Reverse charge: VAT to be accounted for by the recipient (Article 196 of Directive 2006/112/EC)",
+		"VAT exempt: Vocational training, Article 132(1)(i) of Directive 2006/112/EC",
+	}
+	if len(notes) != len(want) {
+		t.Fatalf("notes = %q, want %q", notes, want)
+	}
+	for ix := range want {
+		if notes[ix] != want[ix] {
+			t.Errorf("notes[%d] = %q, want %q", ix, notes[ix], want[ix])
+		}
+	}
+	if line := b.invoiceBillToTaxLine(); line != "Tax ID: FR32123456789" {
+		t.Fatalf("bill-to tax line = %q", line)
+	}
+	for _, layout := range BuiltinLayoutNames() {
+		b.cfg.InvoiceLayout = layout
+		buf, err := b.GenerateInvoice()
+		if err != nil || !bytes.HasPrefix(buf, []byte("%PDF")) {
+			t.Fatalf("GenerateInvoice(%s) = %v", layout, err)
+		}
+	}
+
+	inv, err := b.EInvoice()
+	if err != nil {
+		t.Fatalf("EInvoice: %v", err)
+	}
+	if err := inv.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if inv.Buyer.VATID != "FR32123456789" {
+		t.Fatalf("buyer VAT ID = %q", inv.Buyer.VATID)
+	}
+	reasons := map[string]string{}
+	for _, group := range inv.TaxBreakdown {
+		reasons[group.Category] = group.ExemptionReason
+	}
+	if reasons["AE"] != "Reverse charge" || !strings.HasPrefix(reasons["E"], "Vocational training") {
+		t.Fatalf("exemption reasons = %v", reasons)
+	}
+
+	params.BillToTaxNumber = ""
+	if _, err := b.GenerateInvoice(); err == nil {
+		t.Fatal("GenerateInvoice accepted a reverse-charge invoice without the buyer's tax number")
+	}
+}
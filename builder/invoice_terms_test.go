@@ -0,0 +1,110 @@
+package builder
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/quailyquaily/bizdocgen/facturx"
+	"github.com/shopspring/decimal"
+)
+
+func TestInvoiceDueDateAndOverdue(t *testing.T) {
+	params := &core.InvoiceParams{}
+	if err := params.Load("../samples/invoice-3.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	b, err := NewInvoiceBuilder(Config{}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+	if got := b.invoiceDueDateLine(); got != "Due Date: 2024/04/30 (翌月末払い)" {
+		t.Fatalf("due date line = %q", got)
+	}
+	if b.invoiceOverdue() {
+		t.Fatal("overdue without Config.MarkOverdue")
+	}
+	titleWidth := func() any {
+		return b.buildInvoiceTitleRows()[0].GetStructure().GetNexts()[0].GetData().Value
+	}
+	if got := titleWidth(); got != 12 {
+		t.Fatalf("title width = %v without the overdue marker, want 12", got)
+	}
+
+	b.cfg.MarkOverdue = true
+	b.cfg.Now = time.Date(2024, 4, 30, 18, 0, 0, 0, time.UTC)
+	if b.invoiceOverdue() {
+		t.Fatal("overdue on the due date")
+	}
+	b.cfg.Now = time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	if !b.invoiceOverdue() {
+		t.Fatal("not overdue after the due date")
+	}
+	if got := titleWidth(); got != 9 {
+		t.Fatalf("title width = %v next to the overdue marker, want 9", got)
+	}
+	for _, layout := range BuiltinLayoutNames() {
+		b.cfg.InvoiceLayout = layout
+		buf, err := b.GenerateInvoice()
+		if err != nil || !bytes.HasPrefix(buf, []byte("%PDF")) {
+			t.Fatalf("GenerateInvoice(%s) = %v", layout, err)
+		}
+	}
+
+	params.Payment.InvoicePaymentResult = core.InvoicePaymentResult{Amount: decimal.NewFromInt(1000000)}
+	if b.invoiceOverdue() {
+		t.Fatal("paid invoice marked overdue")
+	}
+
+	params.DueDate = time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	if got := b.invoiceDueDateLine(); !strings.HasPrefix(got, "Due Date: 2024/05/10") {
+		t.Fatalf("explicit due date line = %q", got)
+	}
+}
+
+func TestInvoiceRefusesUnparsedPaymentTerms(t *testing.T) {
+	params := &core.InvoiceParams{}
+	if err := params.Load("../samples/invoice-3.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	params.PaymentTerms = "Net 30 days"
+	if _, err := NewInvoiceBuilder(Config{MarkOverdue: true}, params); err == nil || !strings.Contains(err.Error(), "payment_terms") {
+		t.Fatalf("NewInvoiceBuilder = %v, want a payment_terms error", err)
+	}
+}
+
+func TestInvoiceUBLCarriesDueDateAndTerms(t *testing.T) {
+	params := &core.InvoiceParams{}
+	if err := params.Load("../samples/invoice-5.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	params.PaymentTerms = "Net 30"
+	b, err := NewInvoiceBuilder(Config{}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+	xml, err := b.GenerateInvoiceUBL()
+	if err != nil {
+		t.Fatalf("GenerateInvoiceUBL: %v", err)
+	}
+	due := params.Date.AddDate(0, 0, 30).Format("2006-01-02")
+	for _, want := range []string{"<cbc:DueDate>" + due + "</cbc:DueDate>", "<cac:PaymentTerms>", "<cbc:Note>Net 30</cbc:Note>"} {
+		if !bytes.Contains(xml, []byte(want)) {
+			t.Errorf("UBL lacks %s", want)
+		}
+	}
+
+	inv, err := b.EInvoice()
+	if err != nil {
+		t.Fatalf("EInvoice: %v", err)
+	}
+	cii, err := facturx.CII(inv, facturx.ProfileEN16931)
+	if err != nil {
+		t.Fatalf("CII: %v", err)
+	}
+	if !bytes.Contains(cii, []byte("<ram:DueDateDateTime>")) || !bytes.Contains(cii, []byte("<ram:Description>Net 30</ram:Description>")) {
+		t.Error("CII lacks the payment terms")
+	}
+}
@@ -0,0 +1,68 @@
+package builder
+
+import (
+	"fmt"
+
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	marotoCore "github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+// invoiceWithholding returns the 源泉徴収税 deducted from the total: Summary.Withholding.Amount when
+// set, otherwise the tiered tax on the subtotal, or on the total with IncludeTax. Negative amounts,
+// as on credit notes, give a negative deduction.
+func (b *Builder) invoiceWithholding(subtotal, total decimal.Decimal) decimal.Decimal {
+	withholding := b.iParams.Summary.Withholding
+	if !withholding.Enabled {
+		return decimal.Zero
+	}
+	if !withholding.Amount.IsZero() {
+		return withholding.Amount
+	}
+	base := subtotal
+	if withholding.IncludeTax {
+		base = total
+	}
+	if base.IsNegative() {
+		return core.WithholdingTax(base.Neg()).Neg()
+	}
+	return core.WithholdingTax(base)
+}
+
+// invoiceWithholdingLines returns the "Withholding Tax" and "Net Amount Payable" lines for the
+// inline summaries of the layouts, or empty strings when nothing is withheld.
+func (b *Builder) invoiceWithholdingLines() (withholdingLine, payableLine string) {
+	summary := b.invoiceSummaryNumbers()
+	if summary.Withholding.IsZero() {
+		return "", ""
+	}
+	tWithholding := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceWithholdingTax", nil)
+	tPayable := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceNetPayable", nil)
+	return fmt.Sprintf("%s: %s", tWithholding, b.formatMoney(summary.Withholding.Neg(), summary.BaseCurrency)),
+		fmt.Sprintf("%s: %s", tPayable, b.formatMoney(summary.Payable, summary.BaseCurrency))
+}
+
+// buildInvoiceWithholdingRows renders the withholding tax and the net amount payable below the
+// summary total.
+func (b *Builder) buildInvoiceWithholdingRows(summary invoiceSummaryNumbers) []marotoCore.Row {
+	if summary.Withholding.IsZero() {
+		return nil
+	}
+	tWithholding := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceWithholdingTax", nil)
+	tPayable := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceNetPayable", nil)
+	return []marotoCore.Row{
+		row.New(8).Add(
+			text.NewCol(6, tWithholding, props.Text{Size: 9, Top: 2, Align: align.Left, Color: b.fgColor}),
+			text.NewCol(6, b.formatMoney(summary.Withholding.Neg(), summary.BaseCurrency), props.Text{Size: 9, Top: 2, Align: align.Right, Color: b.fgColor}),
+		),
+		row.New(10).Add(
+			text.NewCol(6, tPayable, props.Text{Size: 10, Top: 4, Align: align.Left, Style: fontstyle.Bold, Color: b.fgColor}),
+			text.NewCol(6, b.formatMoney(summary.Payable, summary.BaseCurrency), props.Text{Size: 10, Top: 4, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
+		),
+	}
+}
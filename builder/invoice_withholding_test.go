@@ -0,0 +1,73 @@
+package builder
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+func TestInvoiceWithholding(t *testing.T) {
+	params := &core.InvoiceParams{}
+	if err := params.Load("../samples/invoice-8.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	b, err := NewInvoiceBuilder(Config{ValidateParams: true, Lang: "ja"}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+
+	// 1,000,000 × 10.21% + 200,000 × 20.42% on the fee before consumption tax.
+	nums := b.invoiceSummaryNumbers()
+	if nums.Total.String() != "1320000" || nums.Withholding.String() != "142940" || nums.Payable.String() != "1177060" {
+		t.Fatalf("total/withholding/payable = %s/%s/%s, want 1320000/142940/1177060", nums.Total, nums.Withholding, nums.Payable)
+	}
+	// Header, amount, VAT, total, withholding and net amount payable.
+	if rows := b.BuildInvoiceSummaryRows(); len(rows) != 6 {
+		t.Fatalf("len(summary rows) = %d, want 6", len(rows))
+	}
+	for _, layout := range BuiltinLayoutNames() {
+		b.cfg.InvoiceLayout = layout
+		buf, err := b.GenerateInvoice()
+		if err != nil || !bytes.HasPrefix(buf, []byte("%PDF")) {
+			t.Fatalf("GenerateInvoice(%s) = %v", layout, err)
+		}
+	}
+	if _, err := b.EInvoice(); err == nil {
+		t.Fatal("EInvoice accepted a withholding deduction")
+	}
+
+	params.Summary.Withholding.IncludeTax = true
+	if got := b.invoiceSummaryNumbers().Withholding.String(); got != "167444" {
+		t.Fatalf("withholding on the total = %s, want 167444", got)
+	}
+
+	params.Summary.Withholding.Amount = decimal.NewFromInt(100000)
+	params.Payment.Results = []core.InvoicePaymentResult{{Amount: decimal.NewFromInt(1000000)}}
+	if _, balance, ok := b.invoiceBalance(); !ok || balance.String() != "220000" {
+		t.Fatalf("balance = %s, %v; want 220000 against the amount payable", balance, ok)
+	}
+}
+
+func TestCreditNoteNegatesWithholding(t *testing.T) {
+	params := &core.CreditNoteParams{
+		Currency: "JPY",
+		Summary: core.InvoiceSummary{
+			TotalExcludeTax: decimal.NewFromInt(100000),
+			TaxRate:         decimal.RequireFromString("0.1"),
+			Withholding:     core.InvoiceWithholding{Enabled: true},
+		},
+		DetailItems: []core.InvoiceDetailItem{
+			{Title: "Refund", TotalExcludeTax: decimal.NewFromInt(100000)},
+		},
+	}
+	b, err := NewCreditNoteBuilder(Config{}, params)
+	if err != nil {
+		t.Fatalf("NewCreditNoteBuilder: %v", err)
+	}
+	nums := b.invoiceSummaryNumbers()
+	if nums.Withholding.String() != "-10210" || nums.Payable.String() != "-99790" {
+		t.Fatalf("withholding/payable = %s/%s, want -10210/-99790", nums.Withholding, nums.Payable)
+	}
+}
@@ -1,24 +1,147 @@
 package builder
 
-const (
-	labelSetInvoice   = "invoice"
-	labelSetStatement = "statement"
+import (
+	"fmt"
+	"time"
+)
+
+// documentKind describes a document type rendered through the invoice layouts: its default
+// title and hint, and the invoice labels it replaces with its own i18n keys.
+type documentKind struct {
+	name string
+	// title is the default title when doc.title is empty; titleKey, if set, localizes it instead.
+	title    string
+	titleKey string
+	// hint is the default line below the title when doc.description is empty.
+	hint string
+	// tracksBalance nets the payment results against the total into a balance due.
+	tracksBalance bool
+	// labels maps invoice i18n keys to the kind's keys; unmapped keys are used as they are.
+	labels map[string]string
+}
+
+// documentReference replaces the period line in the header, e.g. with the credited invoice.
+type documentReference struct {
+	labelKey string
+	value    string
+}
+
+var (
+	kindInvoice = &documentKind{
+		name:          "invoice",
+		title:         defaultInvoiceDocTitle,
+		hint:          defaultInvoiceDocDescription,
+		tracksBalance: true,
+	}
+	kindStatement = &documentKind{
+		name:  "statement",
+		title: defaultStatementDocTitle,
+		hint:  defaultStatementDocHint,
+		labels: map[string]string{
+			"InvoiceID":        "StatementID",
+			"InvoiceIssueDate": "StatementIssueDate",
+			"InvoicePeriod":    "StatementPeriod",
+			"InvoiceBillTo":    "StatementRecipient",
+		},
+	}
+	kindCreditNote = &documentKind{
+		name:     "creditnote",
+		titleKey: "CreditNoteTitle",
+		labels: map[string]string{
+			"InvoiceID":                    "CreditNoteID",
+			"InvoiceIssueDate":             "CreditNoteIssueDate",
+			"InvoiceBillTo":                "CreditNoteBillTo",
+			"InvoicePaymentResult":         "CreditNoteRefund",
+			"InvoicePaymentResultAmount":   "CreditNoteRefundAmount",
+			"InvoicePaymentResultPaidDate": "CreditNoteRefundDate",
+		},
+	}
+	kindQuote = &documentKind{
+		name:     "quote",
+		titleKey: "QuoteTitle",
+		labels: map[string]string{
+			"InvoiceID":        "QuoteID",
+			"InvoiceIssueDate": "QuoteIssueDate",
+			"InvoiceBillTo":    "QuoteBillTo",
+		},
+	}
+	kindReceipt = &documentKind{
+		name:          "receipt",
+		titleKey:      "ReceiptTitle",
+		tracksBalance: true,
+		labels: map[string]string{
+			"InvoiceID":            "ReceiptID",
+			"InvoiceIssueDate":     "ReceiptIssueDate",
+			"InvoiceBillTo":        "ReceiptReceivedFrom",
+			"InvoicePaymentResult": "ReceiptPaymentDetails",
+		},
+	}
+	kindPurchaseOrder = &documentKind{
+		name:     "purchaseorder",
+		titleKey: "PurchaseOrderTitle",
+		labels: map[string]string{
+			"InvoiceID":        "PurchaseOrderID",
+			"InvoiceIssueDate": "PurchaseOrderIssueDate",
+			"InvoiceBillTo":    "PurchaseOrderSupplier",
+		},
+	}
+	kindDeliveryNote = &documentKind{
+		name:     "deliverynote",
+		titleKey: "DeliveryNoteTitle",
+		labels: map[string]string{
+			"InvoiceID":        "DeliveryNoteID",
+			"InvoiceIssueDate": "DeliveryNoteIssueDate",
+			"InvoiceBillTo":    "DeliveryNoteDeliverTo",
+		},
+	}
 )
 
 func (b *Builder) labelKey(invoiceKey string) string {
-	if b.docLabelSet != labelSetStatement {
-		return invoiceKey
-	}
-	switch invoiceKey {
-	case "InvoiceID":
-		return "StatementID"
-	case "InvoiceIssueDate":
-		return "StatementIssueDate"
-	case "InvoicePeriod":
-		return "StatementPeriod"
-	case "InvoiceBillTo":
-		return "StatementRecipient"
-	default:
-		return invoiceKey
+	if key, ok := b.kind.labels[invoiceKey]; ok {
+		return key
+	}
+	return invoiceKey
+}
+
+func (b *Builder) invoiceDocTitle() string {
+	if b.iParams.Doc.Title != "" {
+		return b.iParams.Doc.Title
+	}
+	if b.kind == kindInvoice && b.jpQualifiedInvoice() {
+		return b.i18nBundle.MusT(b.cfg.Lang, "InvoiceQualifiedTitle", nil)
+	}
+	if b.kind.titleKey != "" {
+		return b.i18nBundle.MusT(b.cfg.Lang, b.kind.titleKey, nil)
+	}
+	return b.kind.title
+}
+
+func (b *Builder) invoiceDocHint() string {
+	if b.iParams.Doc.Description != "" {
+		return b.iParams.Doc.Description
+	}
+	return b.kind.hint
+}
+
+// invoiceHeaderLastLine returns the document reference if the builder has one, otherwise the period.
+// A reference without a value leaves the line empty.
+func (b *Builder) invoiceHeaderLastLine(tPeriod string) string {
+	if b.reference != nil {
+		if b.reference.value == "" {
+			return ""
+		}
+		return fmt.Sprintf("%s: %s", b.i18nBundle.MusT(b.cfg.Lang, b.reference.labelKey, nil), b.reference.value)
+	}
+	return fmt.Sprintf("%s: %s - %s", tPeriod,
+		b.iParams.Summary.PeriodStart.Format("2006/01/02"),
+		b.iParams.Summary.PeriodEnd.Format("2006/01/02"),
+	)
+}
+
+// dateReference formats an ID with its date, leaving the date out when unknown.
+func dateReference(id string, date time.Time) string {
+	if date.IsZero() {
+		return id
 	}
+	return fmt.Sprintf("%s (%s)", id, date.Format("2006/01/02"))
 }
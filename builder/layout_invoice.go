@@ -77,6 +77,9 @@ func (invoiceLayoutModern) Build(b *Builder) ([]marotoCore.Row, []marotoCore.Row
 		text.New(b.iParams.BillToCompany, props.Text{Size: 10, Top: 8, Style: fontstyle.Bold, Color: b.fgColor}),
 	)
 	lines := strings.Split(b.iParams.BillToAddress, "\n")
+	if taxLine := b.invoiceBillToTaxLine(); taxLine != "" {
+		lines = append(lines, taxLine)
+	}
 	for ix, line := range lines {
 		billToCol.Add(text.New(line, props.Text{Size: 9, Top: float64(6*ix + 16), Color: b.fgColor}))
 	}
@@ -85,26 +88,45 @@ func (invoiceLayoutModern) Build(b *Builder) ([]marotoCore.Row, []marotoCore.Row
 	summaryCol := col.New(6)
 	summaryCol.Add(
 		text.New(tSummary, props.Text{Size: 10, Top: 0, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
-		text.New(fmt.Sprintf("%s %s", summaryNumbers.Total, summaryNumbers.BaseCurrency), props.Text{Size: 18, Top: 8, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
+		text.New(b.formatMoney(summaryNumbers.Total, summaryNumbers.BaseCurrency), props.Text{Size: 18, Top: 8, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
 		text.New(b.iParams.Summary.Title, props.Text{Size: 9, Top: 26, Align: align.Right, Color: b.fgSecondaryColor}),
-		text.New(fmt.Sprintf("%s: %s %s", tAmount, summaryNumbers.Subtotal.RoundDown(2), summaryNumbers.BaseCurrency), props.Text{Size: 9, Top: 34, Align: align.Right, Color: b.fgSecondaryColor}),
-		text.New(fmt.Sprintf("%s: %s %s", tVAT, summaryNumbers.Tax, summaryNumbers.BaseCurrency), props.Text{Size: 9, Top: 40, Align: align.Right, Color: b.fgSecondaryColor}),
-		text.New(fmt.Sprintf("%s: %s %s", tTotal, summaryNumbers.Total, summaryNumbers.BaseCurrency), props.Text{Size: 9, Top: 46, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
+		text.New(fmt.Sprintf("%s: %s", tAmount, b.formatMoney(summaryNumbers.Subtotal, summaryNumbers.BaseCurrency)), props.Text{Size: 9, Top: 34, Align: align.Right, Color: b.fgSecondaryColor}),
+		text.New(fmt.Sprintf("%s: %s", tVAT, b.formatMoney(summaryNumbers.Tax, summaryNumbers.BaseCurrency)), props.Text{Size: 9, Top: 40, Align: align.Right, Color: b.fgSecondaryColor}),
+		text.New(fmt.Sprintf("%s: %s", tTotal, b.formatMoney(summaryNumbers.Total, summaryNumbers.BaseCurrency)), props.Text{Size: 9, Top: 46, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
 	)
-	if summaryNumbers.QuoteAmount.IsPositive() && summaryNumbers.QuoteText != "" {
-		summaryCol.Add(text.New(summaryNumbers.QuoteText, props.Text{Size: 8, Top: 54, Align: align.Right, Color: b.fgSecondaryColor}))
-	}
-
+	balanceTop := float64(52)
+	quoteTop := float64(54)
 	rowHeight := float64(58)
-	if summaryNumbers.QuoteAmount.IsPositive() && summaryNumbers.QuoteText != "" {
-		rowHeight = 62
+	if withholdingLine, payableLine := b.invoiceWithholdingLines(); payableLine != "" {
+		summaryCol.Add(
+			text.New(withholdingLine, props.Text{Size: 9, Top: 52, Align: align.Right, Color: b.fgSecondaryColor}),
+			text.New(payableLine, props.Text{Size: 10, Top: 58, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
+		)
+		balanceTop += 12
+		quoteTop += 12
+		rowHeight += 12
+	}
+	if paidLine, balanceLine := b.invoiceBalanceLines(); balanceLine != "" {
+		summaryCol.Add(
+			text.New(paidLine, props.Text{Size: 9, Top: balanceTop, Align: align.Right, Color: b.fgSecondaryColor}),
+			text.New(balanceLine, props.Text{Size: 10, Top: balanceTop + 6, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
+		)
+		quoteTop += 12
+		rowHeight += 12
+	}
+	if !summaryNumbers.QuoteAmount.IsZero() && summaryNumbers.QuoteText != "" {
+		summaryCol.Add(text.New(summaryNumbers.QuoteText, props.Text{Size: 8, Top: quoteTop, Align: align.Right, Color: b.fgSecondaryColor}))
+		rowHeight += 4
 	}
 
 	body := make([]marotoCore.Row, 0, 64)
-	body = append(body,
-		row.New(rowHeight).Add(billToCol, summaryCol),
-		row.New(6),
-	)
+	if b.hidePrices {
+		body = append(body, row.New(float64(6*len(lines)+20)).Add(billToCol))
+	} else {
+		body = append(body, row.New(rowHeight).Add(billToCol, summaryCol))
+		body = append(body, b.buildInvoiceTaxBreakdownRows(summaryNumbers)...)
+	}
+	body = append(body, row.New(6))
 	body = append(body, b.BuildInvoiceDetailsRows()...)
 	if showInstructions {
 		body = append(body, row.New(6))
@@ -145,6 +167,9 @@ func (invoiceLayoutCompact) Build(b *Builder) ([]marotoCore.Row, []marotoCore.Ro
 		text.New(b.iParams.BillToCompany, props.Text{Size: 9, Top: 7, Style: fontstyle.Bold, Color: b.fgColor}),
 	)
 	lines := strings.Split(b.iParams.BillToAddress, "\n")
+	if taxLine := b.invoiceBillToTaxLine(); taxLine != "" {
+		lines = append(lines, taxLine)
+	}
 	for ix, line := range lines {
 		billToCol.Add(text.New(line, props.Text{Size: 8, Top: float64(5*ix + 14), Color: b.fgColor}))
 	}
@@ -153,18 +178,36 @@ func (invoiceLayoutCompact) Build(b *Builder) ([]marotoCore.Row, []marotoCore.Ro
 	summaryCol := col.New(5)
 	summaryCol.Add(
 		text.New(tSummary, props.Text{Size: 9, Top: 0, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
-		text.New(fmt.Sprintf("%s %s", summaryNumbers.Total, summaryNumbers.BaseCurrency), props.Text{Size: 12, Top: 9, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
-		text.New(fmt.Sprintf("%s: %s %s", tVAT, summaryNumbers.Tax, summaryNumbers.BaseCurrency), props.Text{Size: 8, Top: 24, Align: align.Right, Color: b.fgSecondaryColor}),
+		text.New(b.formatMoney(summaryNumbers.Total, summaryNumbers.BaseCurrency), props.Text{Size: 12, Top: 9, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
+		text.New(fmt.Sprintf("%s: %s", tVAT, b.formatMoney(summaryNumbers.Tax, summaryNumbers.BaseCurrency)), props.Text{Size: 8, Top: 24, Align: align.Right, Color: b.fgSecondaryColor}),
 	)
-	if summaryNumbers.QuoteAmount.IsPositive() && summaryNumbers.QuoteText != "" {
-		summaryCol.Add(text.New(summaryNumbers.QuoteText, props.Text{Size: 7, Top: 30, Align: align.Right, Color: b.fgSecondaryColor}))
+	quoteTop := float64(30)
+	rowHeight := float64(38)
+	if withholdingLine, payableLine := b.invoiceWithholdingLines(); payableLine != "" {
+		summaryCol.Add(
+			text.New(withholdingLine, props.Text{Size: 8, Top: 30, Align: align.Right, Color: b.fgSecondaryColor}),
+			text.New(payableLine, props.Text{Size: 8, Top: 36, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
+		)
+		quoteTop += 12
+		rowHeight += 12
+	}
+	if _, balanceLine := b.invoiceBalanceLines(); balanceLine != "" {
+		summaryCol.Add(text.New(balanceLine, props.Text{Size: 8, Top: quoteTop, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}))
+		quoteTop += 6
+		rowHeight += 6
+	}
+	if !summaryNumbers.QuoteAmount.IsZero() && summaryNumbers.QuoteText != "" {
+		summaryCol.Add(text.New(summaryNumbers.QuoteText, props.Text{Size: 7, Top: quoteTop, Align: align.Right, Color: b.fgSecondaryColor}))
 	}
 
 	body := make([]marotoCore.Row, 0, 64)
-	body = append(body,
-		row.New(38).Add(billToCol, summaryCol),
-		row.New(4),
-	)
+	if b.hidePrices {
+		body = append(body, row.New(float64(5*len(lines)+16)).Add(billToCol))
+	} else {
+		body = append(body, row.New(rowHeight).Add(billToCol, summaryCol))
+		body = append(body, b.buildInvoiceTaxBreakdownRows(summaryNumbers)...)
+	}
+	body = append(body, row.New(4))
 	body = append(body, b.BuildInvoiceDetailsRows()...)
 	if showInstructions {
 		body = append(body, row.New(4))
@@ -245,7 +288,7 @@ func (invoiceLayoutSpotlight) Build(b *Builder) ([]marotoCore.Row, []marotoCore.
 	spotlightCol := col.New(12)
 	spotlightCol.Add(
 		text.New(tTotal, props.Text{Size: 10, Top: 2, Align: align.Center, Color: b.fgSecondaryColor}),
-		text.New(fmt.Sprintf("%s %s", summaryNumbers.Total, summaryNumbers.BaseCurrency), props.Text{Size: 22, Top: 10, Align: align.Center, Style: fontstyle.Bold, Color: b.fgColor}),
+		text.New(b.formatMoney(summaryNumbers.Total, summaryNumbers.BaseCurrency), props.Text{Size: 22, Top: 10, Align: align.Center, Style: fontstyle.Bold, Color: b.fgColor}),
 	)
 	if b.iParams.Summary.Title != "" {
 		spotlightCol.Add(text.New(b.iParams.Summary.Title, props.Text{Size: 9, Top: 23, Align: align.Center, Color: b.fgSecondaryColor}))
@@ -254,28 +297,44 @@ func (invoiceLayoutSpotlight) Build(b *Builder) ([]marotoCore.Row, []marotoCore.
 	breakdownRow := row.New(16).WithStyle(borderBottomStyle).Add(
 		col.New(4).Add(
 			text.New(tAmount, props.Text{Size: 8, Top: 4, Align: align.Center, Color: b.fgSecondaryColor}),
-			text.New(fmt.Sprintf("%s %s", summaryNumbers.Subtotal.RoundDown(2), summaryNumbers.BaseCurrency), props.Text{Size: 10, Top: 8, Align: align.Center, Style: fontstyle.Bold, Color: b.fgColor}),
+			text.New(b.formatMoney(summaryNumbers.Subtotal, summaryNumbers.BaseCurrency), props.Text{Size: 10, Top: 8, Align: align.Center, Style: fontstyle.Bold, Color: b.fgColor}),
 		),
 		col.New(4).Add(
 			text.New(tVAT, props.Text{Size: 8, Top: 4, Align: align.Center, Color: b.fgSecondaryColor}),
-			text.New(fmt.Sprintf("%s %s", summaryNumbers.Tax, summaryNumbers.BaseCurrency), props.Text{Size: 10, Top: 8, Align: align.Center, Style: fontstyle.Bold, Color: b.fgColor}),
+			text.New(b.formatMoney(summaryNumbers.Tax, summaryNumbers.BaseCurrency), props.Text{Size: 10, Top: 8, Align: align.Center, Style: fontstyle.Bold, Color: b.fgColor}),
 		),
 		col.New(4).Add(
 			text.New(tTotal, props.Text{Size: 8, Top: 4, Align: align.Center, Color: b.fgSecondaryColor}),
-			text.New(fmt.Sprintf("%s %s", summaryNumbers.Total, summaryNumbers.BaseCurrency), props.Text{Size: 10, Top: 8, Align: align.Center, Style: fontstyle.Bold, Color: b.fgColor}),
+			text.New(b.formatMoney(summaryNumbers.Total, summaryNumbers.BaseCurrency), props.Text{Size: 10, Top: 8, Align: align.Center, Style: fontstyle.Bold, Color: b.fgColor}),
 		),
 	)
 
 	body := make([]marotoCore.Row, 0, 64)
-	body = append(body,
-		row.New(32).WithStyle(borderBottomStyle).Add(spotlightCol),
-	)
-	if summaryNumbers.QuoteAmount.IsPositive() && summaryNumbers.QuoteText != "" {
-		body = append(body, row.New(8).WithStyle(borderBottomStyle).Add(
-			text.NewCol(12, summaryNumbers.QuoteText, props.Text{Size: 8, Top: 2, Align: align.Center, Color: b.fgSecondaryColor}),
-		))
+	if !b.hidePrices {
+		body = append(body,
+			row.New(32).WithStyle(borderBottomStyle).Add(spotlightCol),
+		)
+		if !summaryNumbers.QuoteAmount.IsZero() && summaryNumbers.QuoteText != "" {
+			body = append(body, row.New(8).WithStyle(borderBottomStyle).Add(
+				text.NewCol(12, summaryNumbers.QuoteText, props.Text{Size: 8, Top: 2, Align: align.Center, Color: b.fgSecondaryColor}),
+			))
+		}
+		body = append(body, breakdownRow)
+		if withholdingLine, payableLine := b.invoiceWithholdingLines(); payableLine != "" {
+			body = append(body, row.New(10).WithStyle(borderBottomStyle).Add(
+				text.NewCol(6, withholdingLine, props.Text{Size: 9, Top: 3, Align: align.Center, Color: b.fgSecondaryColor}),
+				text.NewCol(6, payableLine, props.Text{Size: 10, Top: 3, Align: align.Center, Style: fontstyle.Bold, Color: b.fgColor}),
+			))
+		}
+		if paidLine, balanceLine := b.invoiceBalanceLines(); balanceLine != "" {
+			body = append(body, row.New(10).WithStyle(borderBottomStyle).Add(
+				text.NewCol(6, paidLine, props.Text{Size: 9, Top: 3, Align: align.Center, Color: b.fgSecondaryColor}),
+				text.NewCol(6, balanceLine, props.Text{Size: 10, Top: 3, Align: align.Center, Style: fontstyle.Bold, Color: b.fgColor}),
+			))
+		}
+		body = append(body, b.buildInvoiceTaxBreakdownRows(summaryNumbers)...)
+		body = append(body, row.New(6))
 	}
-	body = append(body, breakdownRow, row.New(6))
 
 	body = append(body, b.BuildInvoiceBillTo()...)
 	body = append(body, b.BuildInvoiceDetailsRows()...)
@@ -338,14 +397,29 @@ func (invoiceLayoutSplit) Build(b *Builder) ([]marotoCore.Row, []marotoCore.Row,
 	summaryCol := col.New(6)
 	summaryCol.Add(
 		text.New(tSummary, props.Text{Size: 10, Top: 10, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
-		text.New(fmt.Sprintf("%s %s", summaryNumbers.Total, summaryNumbers.BaseCurrency), props.Text{Size: 16, Top: 20, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
-		text.New(fmt.Sprintf("%s: %s %s", tAmount, summaryNumbers.Subtotal.RoundDown(2), summaryNumbers.BaseCurrency), props.Text{Size: 9, Top: 40, Align: align.Right, Color: b.fgSecondaryColor}),
-		text.New(fmt.Sprintf("%s: %s %s", tVAT, summaryNumbers.Tax, summaryNumbers.BaseCurrency), props.Text{Size: 9, Top: 46, Align: align.Right, Color: b.fgSecondaryColor}),
-		text.New(fmt.Sprintf("%s: %s %s", tTotal, summaryNumbers.Total, summaryNumbers.BaseCurrency), props.Text{Size: 9, Top: 52, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
+		text.New(b.formatMoney(summaryNumbers.Total, summaryNumbers.BaseCurrency), props.Text{Size: 16, Top: 20, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
+		text.New(fmt.Sprintf("%s: %s", tAmount, b.formatMoney(summaryNumbers.Subtotal, summaryNumbers.BaseCurrency)), props.Text{Size: 9, Top: 40, Align: align.Right, Color: b.fgSecondaryColor}),
+		text.New(fmt.Sprintf("%s: %s", tVAT, b.formatMoney(summaryNumbers.Tax, summaryNumbers.BaseCurrency)), props.Text{Size: 9, Top: 46, Align: align.Right, Color: b.fgSecondaryColor}),
+		text.New(fmt.Sprintf("%s: %s", tTotal, b.formatMoney(summaryNumbers.Total, summaryNumbers.BaseCurrency)), props.Text{Size: 9, Top: 52, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
 	)
-	if summaryNumbers.QuoteAmount.IsPositive() && summaryNumbers.QuoteText != "" {
+	if !summaryNumbers.QuoteAmount.IsZero() && summaryNumbers.QuoteText != "" {
 		summaryCol.Add(text.New(summaryNumbers.QuoteText, props.Text{Size: 8, Top: 50, Align: align.Right, Color: b.fgSecondaryColor}))
 	}
+	summaryHeight := float64(56)
+	if withholdingLine, payableLine := b.invoiceWithholdingLines(); payableLine != "" {
+		summaryCol.Add(
+			text.New(withholdingLine, props.Text{Size: 9, Top: summaryHeight + 2, Align: align.Right, Color: b.fgSecondaryColor}),
+			text.New(payableLine, props.Text{Size: 10, Top: summaryHeight + 8, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
+		)
+		summaryHeight += 14
+	}
+	if paidLine, balanceLine := b.invoiceBalanceLines(); balanceLine != "" {
+		summaryCol.Add(
+			text.New(paidLine, props.Text{Size: 9, Top: summaryHeight + 2, Align: align.Right, Color: b.fgSecondaryColor}),
+			text.New(balanceLine, props.Text{Size: 10, Top: summaryHeight + 8, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
+		)
+		summaryHeight += 14
+	}
 
 	body := make([]marotoCore.Row, 0, 64)
 	body = append(body, b.BuildInvoiceBillTo()...)
@@ -354,7 +428,10 @@ func (invoiceLayoutSplit) Build(b *Builder) ([]marotoCore.Row, []marotoCore.Row,
 	body = append(body, row.New(6))
 
 	if !showInstructions {
-		body = append(body, row.New(56).WithStyle(borderBottomStyle).Add(col.New(6), summaryCol))
+		if !b.hidePrices {
+			body = append(body, row.New(summaryHeight).WithStyle(borderBottomStyle).Add(col.New(6), summaryCol))
+			body = append(body, b.buildInvoiceTaxBreakdownRows(summaryNumbers)...)
+		}
 		if showResult {
 			body = append(body, row.New(6))
 			body = append(body, b.BuildInvoicePaymentResultRows()...)
@@ -393,7 +470,8 @@ func (invoiceLayoutSplit) Build(b *Builder) ([]marotoCore.Row, []marotoCore.Row,
 	addLine("SWIFT", instruction.ReceiveAccountSwift)
 	addLine("Routing Number", instruction.ReceiveAccountRouting)
 
-	body = append(body, row.New(56).WithStyle(borderTopStyle).Add(paymentCol, summaryCol))
+	body = append(body, row.New(summaryHeight).WithStyle(borderTopStyle).Add(paymentCol, summaryCol))
+	body = append(body, b.buildInvoiceTaxBreakdownRows(summaryNumbers)...)
 	if showResult {
 		body = append(body, row.New(6))
 		body = append(body, b.BuildInvoicePaymentResultRows()...)
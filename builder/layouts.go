@@ -1,7 +1,11 @@
 package builder
 
 import (
+	"errors"
+	"fmt"
+	"sort"
 	"strings"
+	"sync"
 
 	marotoCore "github.com/johnfercher/maroto/v2/pkg/core"
 )
@@ -15,39 +19,82 @@ const (
 	LayoutSplit     = "split"
 )
 
+// ErrUnknownLayout is wrapped by InvoiceLayoutByName when no layout is registered under a name.
+var ErrUnknownLayout = errors.New("unknown layout")
+
 type InvoiceLayout interface {
 	Name() string
 	Build(b *Builder) (header []marotoCore.Row, body []marotoCore.Row, err error)
 }
 
-func InvoiceLayoutByName(name string) InvoiceLayout {
-	switch normalizeLayoutName(name) {
-	case LayoutModern:
-		return invoiceLayoutModern{}
-	case LayoutCompact:
-		return invoiceLayoutCompact{}
-	case LayoutSpotlight:
-		return invoiceLayoutSpotlight{}
-	case LayoutLedger:
-		return invoiceLayoutLedger{}
-	case LayoutSplit:
-		return invoiceLayoutSplit{}
-	case LayoutClassic:
-		fallthrough
-	default:
-		return invoiceLayoutClassic{}
+var builtinLayouts = []InvoiceLayout{
+	invoiceLayoutClassic{},
+	invoiceLayoutModern{},
+	invoiceLayoutCompact{},
+	invoiceLayoutSpotlight{},
+	invoiceLayoutLedger{},
+	invoiceLayoutSplit{},
+}
+
+var layoutRegistry = struct {
+	sync.RWMutex
+	layouts map[string]InvoiceLayout
+	custom  []string
+}{layouts: map[string]InvoiceLayout{}}
+
+func init() {
+	for _, layout := range builtinLayouts {
+		layoutRegistry.layouts[layout.Name()] = layout
 	}
 }
 
+// RegisterInvoiceLayout makes layout selectable as Config.InvoiceLayout / Config.SettlementStatementLayout.
+// Names are case-insensitive. It is meant to be called from an init function and panics
+// when the name is empty, the layout is nil or the name is already taken.
+func RegisterInvoiceLayout(name string, layout InvoiceLayout) {
+	key := strings.TrimSpace(strings.ToLower(name))
+	if key == "" {
+		panic("builder: RegisterInvoiceLayout with an empty name")
+	}
+	if layout == nil {
+		panic(fmt.Sprintf("builder: RegisterInvoiceLayout(%q) with a nil layout", name))
+	}
+
+	layoutRegistry.Lock()
+	defer layoutRegistry.Unlock()
+	if _, dup := layoutRegistry.layouts[key]; dup {
+		panic(fmt.Sprintf("builder: layout %q is already registered", key))
+	}
+	layoutRegistry.layouts[key] = layout
+	layoutRegistry.custom = append(layoutRegistry.custom, key)
+	sort.Strings(layoutRegistry.custom)
+}
+
+// InvoiceLayoutByName returns the built-in or registered layout for name; an empty name selects classic.
+// Unknown names return an error wrapping ErrUnknownLayout.
+func InvoiceLayoutByName(name string) (InvoiceLayout, error) {
+	layoutRegistry.RLock()
+	defer layoutRegistry.RUnlock()
+	layout, ok := layoutRegistry.layouts[normalizeLayoutName(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w %q (available: %s)", ErrUnknownLayout, name, strings.Join(layoutNamesLocked(), ", "))
+	}
+	return layout, nil
+}
+
+// BuiltinLayoutNames lists the built-in layouts followed by the registered ones in alphabetical order.
 func BuiltinLayoutNames() []string {
-	return []string{
-		LayoutClassic,
-		LayoutModern,
-		LayoutCompact,
-		LayoutSpotlight,
-		LayoutLedger,
-		LayoutSplit,
+	layoutRegistry.RLock()
+	defer layoutRegistry.RUnlock()
+	return layoutNamesLocked()
+}
+
+func layoutNamesLocked() []string {
+	names := make([]string, 0, len(builtinLayouts)+len(layoutRegistry.custom))
+	for _, layout := range builtinLayouts {
+		names = append(names, layout.Name())
 	}
+	return append(names, layoutRegistry.custom...)
 }
 
 func normalizeLayoutName(name string) string {
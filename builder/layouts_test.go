@@ -0,0 +1,74 @@
+package builder
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	marotoCore "github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/quailyquaily/bizdocgen/core"
+)
+
+type brandedTestLayout struct{ built *int }
+
+func (brandedTestLayout) Name() string { return "branded-test" }
+
+func (l brandedTestLayout) Build(b *Builder) ([]marotoCore.Row, []marotoCore.Row, error) {
+	*l.built++
+	headers, body, err := invoiceLayoutClassic{}.Build(b)
+	return headers, append([]marotoCore.Row{row.New(8).Add(text.NewCol(12, "ACME branded"))}, body...), err
+}
+
+func TestRegisterInvoiceLayout(t *testing.T) {
+	built := 0
+	RegisterInvoiceLayout("Branded-Test", brandedTestLayout{built: &built})
+
+	if !slices.Contains(BuiltinLayoutNames(), "branded-test") {
+		t.Fatalf("BuiltinLayoutNames() = %v, want branded-test listed", BuiltinLayoutNames())
+	}
+
+	params := &core.InvoiceParams{}
+	if err := params.Load("../samples/invoice-1.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	b, err := NewInvoiceBuilder(Config{InvoiceLayout: "BRANDED-TEST"}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+	if _, err := b.GenerateInvoice(); err != nil {
+		t.Fatalf("GenerateInvoice: %v", err)
+	}
+	if built != 1 {
+		t.Fatalf("custom layout built %d times, want 1", built)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("registering a duplicate name did not panic")
+		}
+	}()
+	RegisterInvoiceLayout(LayoutModern, brandedTestLayout{built: &built})
+}
+
+func TestInvoiceLayoutByNameUnknown(t *testing.T) {
+	if layout, err := InvoiceLayoutByName(""); err != nil || layout.Name() != LayoutClassic {
+		t.Fatalf(`InvoiceLayoutByName("") = %v, %v; want classic`, layout, err)
+	}
+	if _, err := InvoiceLayoutByName("no-such-layout"); !errors.Is(err, ErrUnknownLayout) {
+		t.Fatalf("err = %v, want ErrUnknownLayout", err)
+	}
+
+	params := &core.InvoiceParams{}
+	if err := params.Load("../samples/invoice-1.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	b, err := NewInvoiceBuilder(Config{InvoiceLayout: "no-such-layout"}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+	if _, err := b.GenerateInvoice(); !errors.Is(err, ErrUnknownLayout) {
+		t.Fatalf("GenerateInvoice err = %v, want ErrUnknownLayout", err)
+	}
+}
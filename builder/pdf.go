@@ -45,6 +45,9 @@ func (b *Builder) CreateMetricsDecorator(head []marotoCore.Row) (marotoCore.Maro
 			useCustomFonts = true
 		}
 	}
+	if !useCustomFonts && b.cfg.FacturXProfile != "" {
+		return nil, errFacturXFonts
+	}
 
 	bu := config.NewBuilder()
 	if b.iParams != nil {
@@ -61,6 +64,10 @@ func (b *Builder) CreateMetricsDecorator(head []marotoCore.Row) (marotoCore.Maro
 		bu = bu.WithDefaultFont(&props.Font{Family: b.cfg.FontName})
 	}
 
+	if !b.cfg.Now.IsZero() {
+		bu = bu.WithCreationDate(b.cfg.Now)
+	}
+
 	cfg := bu.Build()
 
 	mrt := maroto.New(cfg)
@@ -0,0 +1,97 @@
+package builder
+
+import (
+	"log"
+
+	"github.com/johnfercher/maroto/v2/pkg/components/page"
+	"github.com/quailyquaily/bizdocgen/core"
+)
+
+func NewPurchaseOrderBuilder(cfg Config, params *core.PurchaseOrderParams) (*Builder, error) {
+	builder, err := NewInvoiceBuilder(cfg, invoiceParamsFromPurchaseOrder(params))
+	if err != nil {
+		return nil, err
+	}
+	deliveryDate := ""
+	if !params.DeliveryDate.IsZero() {
+		deliveryDate = params.DeliveryDate.Format("2006/01/02")
+	}
+	builder.kind = kindPurchaseOrder
+	builder.reference = &documentReference{labelKey: "PurchaseOrderDeliveryDate", value: deliveryDate}
+	builder.validateParams = paramsValidator(cfg, params.Validate, params.ValidateWithoutSummaryCheck)
+	return builder, nil
+}
+
+func NewPurchaseOrderBuilderFromFile(cfg Config, filename string) (*Builder, error) {
+	params := &core.PurchaseOrderParams{}
+	if err := params.Load(filename); err != nil {
+		return nil, err
+	}
+	return NewPurchaseOrderBuilder(cfg, params)
+}
+
+func (b *Builder) GeneratePurchaseOrder() ([]byte, error) {
+	if err := b.checkParams(); err != nil {
+		return nil, err
+	}
+	layout, err := InvoiceLayoutByName(b.cfg.PurchaseOrderLayout)
+	if err != nil {
+		log.Printf("failed to select purchase order layout: %v\n", err)
+		return nil, err
+	}
+	headers, body, err := layout.Build(b)
+	if err != nil {
+		log.Printf("failed to build purchase order layout %q: %v\n", layout.Name(), err)
+		return nil, err
+	}
+
+	m, err := b.CreateMetricsDecorator(headers)
+	if err != nil {
+		log.Printf("failed to register header: %v\n", err)
+		return nil, err
+	}
+
+	footer, err := b.BuildInvoiceFooter()
+	if err != nil {
+		log.Printf("failed to build purchase order footer: %v\n", err)
+		return nil, err
+	}
+	if err := m.RegisterFooter(footer...); err != nil {
+		log.Printf("failed to register footer: %v\n", err)
+		return nil, err
+	}
+
+	newPage := page.New()
+	newPage.Add(body...)
+
+	m.AddPages(newPage)
+
+	return b.getBytesFromMaroto(m)
+}
+
+// invoiceParamsFromPurchaseOrder maps a purchase order onto invoice params: the supplier takes the
+// bill-to party's place and there are no payment blocks.
+func invoiceParamsFromPurchaseOrder(params *core.PurchaseOrderParams) *core.InvoiceParams {
+	return &core.InvoiceParams{
+		ID:              params.ID,
+		TaxNumber:       params.TaxNumber,
+		Date:            params.Date,
+		Currency:        params.Currency,
+		CompanyName:     params.CompanyName,
+		CompanyAddr:     params.CompanyAddr,
+		CompanyEmail:    params.CompanyEmail,
+		CompanySeal:     params.CompanySeal,
+		CompanyCountry:  params.CompanyCountry,
+		CompanyEndpoint: params.CompanyEndpoint,
+		BillToCompany:   params.SupplierCompany,
+		BillToAddress:   params.SupplierAddress,
+		BillToCountry:   params.SupplierCountry,
+		Summary:         params.Summary,
+		DetailItems:     params.DetailItems,
+		Payment: core.InvoicePayment{
+			InvoicePaymentInstruction: core.InvoicePaymentInstruction{Disabled: true},
+			InvoicePaymentResult:      core.InvoicePaymentResult{Disabled: true},
+		},
+		Doc: params.Doc,
+	}
+}
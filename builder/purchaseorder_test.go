@@ -0,0 +1,43 @@
+package builder
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGeneratePurchaseOrder(t *testing.T) {
+	for _, layout := range BuiltinLayoutNames() {
+		b, err := NewPurchaseOrderBuilderFromFile(Config{PurchaseOrderLayout: layout, ValidateParams: true}, "../samples/purchaseorder-1.yaml")
+		if err != nil {
+			t.Fatalf("NewPurchaseOrderBuilderFromFile: %v", err)
+		}
+		buf, err := b.GeneratePurchaseOrder()
+		if err != nil || !bytes.HasPrefix(buf, []byte("%PDF")) {
+			t.Fatalf("GeneratePurchaseOrder(%s) = %v", layout, err)
+		}
+	}
+}
+
+func TestPurchaseOrderLabels(t *testing.T) {
+	b, err := NewPurchaseOrderBuilderFromFile(Config{}, "../samples/purchaseorder-1.yaml")
+	if err != nil {
+		t.Fatalf("NewPurchaseOrderBuilderFromFile: %v", err)
+	}
+	if got := b.invoiceDocTitle(); got != "Purchase Order" {
+		t.Fatalf("title = %q, want Purchase Order", got)
+	}
+	if got := b.invoiceHeaderLastLine(""); got != "Delivery Date: 2024/04/19" {
+		t.Fatalf("header delivery date = %q", got)
+	}
+	if got := b.i18nBundle.MusT("en", b.labelKey("InvoiceBillTo"), nil); got != "Supplier" {
+		t.Fatalf("bill-to label = %q, want Supplier", got)
+	}
+	if got := b.invoiceSummaryNumbers().Total.String(); got != "200200" {
+		t.Fatalf("total = %s, want 200200", got)
+	}
+
+	b.cfg.Lang = "ja"
+	if got := b.invoiceDocTitle(); got != "発注書" {
+		t.Fatalf("ja title = %q, want 発注書", got)
+	}
+}
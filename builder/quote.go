@@ -0,0 +1,160 @@
+package builder
+
+import (
+	"log"
+
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/page"
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/border"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	marotoCore "github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+	"github.com/quailyquaily/bizdocgen/core"
+)
+
+func NewQuoteBuilder(cfg Config, params *core.QuoteParams) (*Builder, error) {
+	builder, err := NewInvoiceBuilder(cfg, invoiceParamsFromQuote(params))
+	if err != nil {
+		return nil, err
+	}
+	builder.kind = kindQuote
+	builder.reference = &documentReference{labelKey: "QuoteValidUntil", value: params.ValidUntil.Format("2006/01/02")}
+	builder.quote = params
+	builder.validateParams = paramsValidator(cfg, params.Validate, params.ValidateWithoutSummaryCheck)
+	return builder, nil
+}
+
+func NewQuoteBuilderFromFile(cfg Config, filename string) (*Builder, error) {
+	params := &core.QuoteParams{}
+	if err := params.Load(filename); err != nil {
+		return nil, err
+	}
+	return NewQuoteBuilder(cfg, params)
+}
+
+func (b *Builder) GenerateQuote() ([]byte, error) {
+	if err := b.checkParams(); err != nil {
+		return nil, err
+	}
+	layout, err := InvoiceLayoutByName(b.cfg.QuoteLayout)
+	if err != nil {
+		log.Printf("failed to select quote layout: %v\n", err)
+		return nil, err
+	}
+	headers, body, err := layout.Build(b)
+	if err != nil {
+		log.Printf("failed to build quote layout %q: %v\n", layout.Name(), err)
+		return nil, err
+	}
+	body = append(body, b.BuildQuoteAcceptanceRows()...)
+
+	m, err := b.CreateMetricsDecorator(headers)
+	if err != nil {
+		log.Printf("failed to register header: %v\n", err)
+		return nil, err
+	}
+
+	footer, err := b.BuildInvoiceFooter()
+	if err != nil {
+		log.Printf("failed to build quote footer: %v\n", err)
+		return nil, err
+	}
+	if err := m.RegisterFooter(footer...); err != nil {
+		log.Printf("failed to register footer: %v\n", err)
+		return nil, err
+	}
+
+	newPage := page.New()
+	newPage.Add(body...)
+
+	m.AddPages(newPage)
+
+	return b.getBytesFromMaroto(m)
+}
+
+// BuildQuoteAcceptanceRows renders the signature block: signature, name and date lines,
+// pre-filled with the acceptance details when known. It is empty unless the builder renders a quote.
+func (b *Builder) BuildQuoteAcceptanceRows() []marotoCore.Row {
+	if b.quote == nil || b.quote.Acceptance.Disabled {
+		return nil
+	}
+	acceptance := b.quote.Acceptance
+	tAcceptance := b.i18nBundle.MusT(b.cfg.Lang, "QuoteAcceptance", nil)
+	tSignature := b.i18nBundle.MusT(b.cfg.Lang, "QuoteAcceptanceSignature", nil)
+	tName := b.i18nBundle.MusT(b.cfg.Lang, "QuoteAcceptanceName", nil)
+	tDate := b.i18nBundle.MusT(b.cfg.Lang, "QuoteAcceptanceDate", nil)
+
+	borderBottomStyle := &props.Cell{
+		BorderType:  border.Bottom,
+		BorderColor: b.borderColor,
+	}
+
+	rows := []marotoCore.Row{
+		row.New(16).WithStyle(borderBottomStyle).Add(
+			text.NewCol(12, tAcceptance, props.Text{Size: 10, Top: 8, Align: align.Left, Style: fontstyle.Bold, Color: b.fgColor}),
+		),
+	}
+	if acceptance.Note != "" {
+		rows = append(rows, text.NewRow(10, acceptance.Note, props.Text{Size: 9, Top: 4, Align: align.Left, Color: b.fgSecondaryColor}))
+	}
+
+	acceptedDate := ""
+	if !acceptance.AcceptedDate.IsZero() {
+		acceptedDate = acceptance.AcceptedDate.Format("2006/01/02")
+	}
+	signatureLineStyle := &props.Cell{
+		BorderType:      border.Bottom,
+		BorderColor:     b.fgTertiaryColor,
+		BorderThickness: 0.2,
+	}
+	valueProps := props.Text{Size: 9, Top: 12, Align: align.Left, Color: b.fgColor}
+	labelProps := props.Text{Size: 8, Top: 1, Align: align.Left, Color: b.fgTertiaryColor}
+	rows = append(rows,
+		row.New(18).Add(
+			col.New(5).WithStyle(signatureLineStyle),
+			col.New(1),
+			col.New(3).WithStyle(signatureLineStyle).Add(text.New(acceptance.AcceptedBy, valueProps)),
+			col.New(1),
+			col.New(2).WithStyle(signatureLineStyle).Add(text.New(acceptedDate, valueProps)),
+		),
+		row.New(8).Add(
+			text.NewCol(5, tSignature, labelProps),
+			col.New(1),
+			text.NewCol(3, tName, labelProps),
+			col.New(1),
+			text.NewCol(2, tDate, labelProps),
+		),
+	)
+	return rows
+}
+
+func invoiceParamsFromQuote(params *core.QuoteParams) *core.InvoiceParams {
+	return &core.InvoiceParams{
+		ID:              params.ID,
+		TaxNumber:       params.TaxNumber,
+		Date:            params.Date,
+		Currency:        params.Currency,
+		CompanyName:     params.CompanyName,
+		CompanyAddr:     params.CompanyAddr,
+		CompanyEmail:    params.CompanyEmail,
+		CompanySeal:     params.CompanySeal,
+		CompanyCountry:  params.CompanyCountry,
+		CompanyEndpoint: params.CompanyEndpoint,
+		BillToCompany:   params.BillToCompany,
+		BillToAddress:   params.BillToAddress,
+		BillToCountry:   params.BillToCountry,
+		BillToEndpoint:  params.BillToEndpoint,
+		BillToTaxNumber: params.BillToTaxNumber,
+		BuyerReference:  params.BuyerReference,
+		Summary:         params.Summary,
+		DetailItems:     params.DetailItems,
+		Payment: core.InvoicePayment{
+			InvoicePaymentInstruction: params.Payment.QuotePaymentInstruction,
+			InvoicePaymentResult:      core.InvoicePaymentResult{Disabled: true},
+		},
+		Doc: params.Doc,
+	}
+}
@@ -0,0 +1,62 @@
+package builder
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/quailyquaily/bizdocgen/core"
+)
+
+func TestGenerateQuote(t *testing.T) {
+	for _, layout := range BuiltinLayoutNames() {
+		b, err := NewQuoteBuilderFromFile(Config{QuoteLayout: layout, ValidateParams: true}, "../samples/quote-1.yaml")
+		if err != nil {
+			t.Fatalf("NewQuoteBuilderFromFile: %v", err)
+		}
+		buf, err := b.GenerateQuote()
+		if err != nil || !bytes.HasPrefix(buf, []byte("%PDF")) {
+			t.Fatalf("GenerateQuote(%s) = %v", layout, err)
+		}
+	}
+}
+
+func TestQuoteLabelsAndAcceptanceBlock(t *testing.T) {
+	params := &core.QuoteParams{}
+	if err := params.Load("../samples/quote-1.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	b, err := NewQuoteBuilder(Config{}, params)
+	if err != nil {
+		t.Fatalf("NewQuoteBuilder: %v", err)
+	}
+	if got := b.invoiceDocTitle(); got != "Quotation" {
+		t.Fatalf("title = %q, want Quotation", got)
+	}
+	if got := b.invoiceHeaderLastLine(""); got != "Valid Until: 2024/03/31" {
+		t.Fatalf("header validity = %q", got)
+	}
+	if got := b.i18nBundle.MusT("en", b.labelKey("InvoiceID"), nil); got != "Quote No." {
+		t.Fatalf("id label = %q, want Quote No.", got)
+	}
+	if rows := b.BuildQuoteAcceptanceRows(); len(rows) != 4 {
+		t.Fatalf("len(acceptance rows) = %d, want 4", len(rows))
+	}
+
+	b.cfg.Lang = "ja"
+	if got := b.invoiceDocTitle(); got != "見積書" {
+		t.Fatalf("ja title = %q, want 見積書", got)
+	}
+
+	params.Acceptance.Disabled = true
+	if rows := b.BuildQuoteAcceptanceRows(); len(rows) != 0 {
+		t.Fatalf("disabled acceptance rendered %d rows", len(rows))
+	}
+
+	invoice, err := NewInvoiceBuilderFromFile(Config{}, "../samples/invoice-1.yaml")
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilderFromFile: %v", err)
+	}
+	if rows := invoice.BuildQuoteAcceptanceRows(); len(rows) != 0 {
+		t.Fatalf("invoice rendered %d acceptance rows", len(rows))
+	}
+}
@@ -0,0 +1,236 @@
+package builder
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/page"
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/border"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	marotoCore "github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+// jpRevenueStampThreshold is the amount from which a Japanese receipt for sales proceeds is
+// subject to stamp duty (印紙税法 別表第一 第17号文書): 50,000 yen, excluding separately stated tax.
+var jpRevenueStampThreshold = decimal.NewFromInt(50000)
+
+// NewReceiptBuilder prepares a receipt (領収書) for a paid invoice. The receipt carries the
+// invoice's number, is dated on the latest payment date and states the amount received in the
+// recorded payments, of which there must be at least one; see receiptPayment.
+func NewReceiptBuilder(cfg Config, params *core.InvoiceParams) (*Builder, error) {
+	payment, err := receiptPayment(params)
+	if err != nil {
+		return nil, err
+	}
+	builder, err := NewInvoiceBuilder(cfg, receiptParamsFromInvoice(params, payment))
+	if err != nil {
+		return nil, err
+	}
+	builder.kind = kindReceipt
+	builder.reference = &documentReference{labelKey: "ReceiptInvoice", value: dateReference(params.ID, params.Date)}
+	builder.validateParams = paramsValidator(cfg, params.Validate, params.ValidateWithoutSummaryCheck)
+	return builder, nil
+}
+
+func NewReceiptBuilderFromFile(cfg Config, filename string) (*Builder, error) {
+	params := &core.InvoiceParams{}
+	if err := params.Load(filename); err != nil {
+		return nil, err
+	}
+	return NewReceiptBuilder(cfg, params)
+}
+
+func (b *Builder) GenerateReceipt() ([]byte, error) {
+	if err := b.checkParams(); err != nil {
+		return nil, err
+	}
+	headers, err := b.buildInvoiceHeader(6)
+	if err != nil {
+		log.Printf("failed to build receipt header: %v\n", err)
+		return nil, err
+	}
+
+	body := b.BuildInvoiceBillTo()
+	body = append(body, b.BuildReceiptAmountRows()...)
+	body = append(body, row.New(6))
+	body = append(body, b.BuildInvoiceSummaryRows()...)
+	body = append(body, b.BuildInvoicePaymentResultRows()...)
+	body = append(body, b.BuildReceiptRevenueStampRows()...)
+
+	m, err := b.CreateMetricsDecorator(headers)
+	if err != nil {
+		log.Printf("failed to register header: %v\n", err)
+		return nil, err
+	}
+
+	footer, err := b.BuildInvoiceFooter()
+	if err != nil {
+		log.Printf("failed to build receipt footer: %v\n", err)
+		return nil, err
+	}
+	if err := m.RegisterFooter(footer...); err != nil {
+		log.Printf("failed to register footer: %v\n", err)
+		return nil, err
+	}
+
+	newPage := page.New()
+	newPage.Add(body...)
+
+	m.AddPages(newPage)
+
+	return b.getBytesFromMaroto(m)
+}
+
+// BuildReceiptAmountRows renders the boxed amount received, the "received with thanks" wording
+// and what the payment was for.
+func (b *Builder) BuildReceiptAmountRows() []marotoCore.Row {
+	amount, currency := b.receiptAmount()
+	tReceived := b.i18nBundle.MusT(b.cfg.Lang, "ReceiptReceivedWithThanks", nil)
+
+	boxStyle := &props.Cell{
+		BorderType:      border.Full,
+		BorderColor:     b.fgTertiaryColor,
+		BorderThickness: 0.4,
+	}
+	rows := []marotoCore.Row{
+		row.New(4),
+		row.New(18).Add(
+			col.New(2),
+			col.New(8).WithStyle(boxStyle).Add(
+				text.New(b.formatMoney(amount, currency), props.Text{Size: 18, Top: 4, Align: align.Center, Style: fontstyle.Bold, Color: b.fgColor}),
+			),
+			col.New(2),
+		),
+	}
+	if title := strings.TrimSpace(b.iParams.Summary.Title); title != "" {
+		tFor := b.i18nBundle.MusT(b.cfg.Lang, "ReceiptFor", map[string]any{"Title": title})
+		rows = append(rows, text.NewRow(10, tFor, props.Text{Size: 9, Top: 4, Align: align.Center, Color: b.fgColor}))
+	}
+	rows = append(rows, text.NewRow(8, tReceived, props.Text{Size: 9, Top: 2, Align: align.Center, Color: b.fgColor}))
+	return rows
+}
+
+// BuildReceiptRevenueStampRows renders the revenue stamp (収入印紙) box when the receipt is subject
+// to Japanese stamp duty; see receiptRequiresRevenueStamp.
+func (b *Builder) BuildReceiptRevenueStampRows() []marotoCore.Row {
+	if !b.receiptRequiresRevenueStamp() {
+		return nil
+	}
+	tStamp := b.i18nBundle.MusT(b.cfg.Lang, "ReceiptRevenueStamp", nil)
+	tNote := b.i18nBundle.MusT(b.cfg.Lang, "ReceiptRevenueStampNote", nil)
+	boxStyle := &props.Cell{
+		BorderType:      border.Full,
+		BorderColor:     b.fgTertiaryColor,
+		BorderThickness: 0.2,
+	}
+	return []marotoCore.Row{
+		row.New(8),
+		row.New(24).Add(
+			col.New(2).WithStyle(boxStyle).Add(
+				text.New(tStamp, props.Text{Size: 8, Top: 10, Align: align.Center, Color: b.fgTertiaryColor}),
+			),
+			col.New(1),
+			text.NewCol(9, tNote, props.Text{Size: 8, Top: 8, Align: align.Left, Color: b.fgSecondaryColor}),
+		),
+	}
+}
+
+// receiptAmount returns the amount received and its currency.
+func (b *Builder) receiptAmount() (decimal.Decimal, string) {
+	result := b.iParams.Payment.InvoicePaymentResult
+	currency := strings.TrimSpace(result.Currency)
+	if currency == "" {
+		currency = b.iParams.Currency
+	}
+	return result.Amount, currency
+}
+
+// receiptRequiresRevenueStamp reports whether a yen receipt reaches the stamp duty threshold.
+// The consumption tax is excluded when the receipt settles the invoice in full, as the tax is
+// then stated separately on the receipt. Config.OmitRevenueStamp disables the box.
+func (b *Builder) receiptRequiresRevenueStamp() bool {
+	if b.cfg.OmitRevenueStamp {
+		return false
+	}
+	amount, currency := b.receiptAmount()
+	if !sameCurrency(currency, "JPY") {
+		return false
+	}
+	summary := b.invoiceSummaryNumbers()
+	if sameCurrency(summary.BaseCurrency, currency) && amount.GreaterThanOrEqual(summary.Payable) {
+		amount = summary.Subtotal
+	}
+	return amount.GreaterThanOrEqual(jpRevenueStampThreshold)
+}
+
+// receiptPayment combines the payments recorded in payment.result and payment.results into the
+// one the receipt is issued for: their sum, paid on the latest date. Payments in different
+// currencies cannot be combined and are refused.
+func receiptPayment(params *core.InvoiceParams) (core.InvoicePaymentResult, error) {
+	results := params.Payment.PaymentResults()
+	if len(results) == 0 {
+		return core.InvoicePaymentResult{}, fmt.Errorf("invoice %s has no payment result to issue a receipt for", params.ID)
+	}
+	currency := func(result core.InvoicePaymentResult) string {
+		if code := strings.TrimSpace(result.Currency); code != "" {
+			return code
+		}
+		return params.Currency
+	}
+	payment := results[0]
+	payment.Currency = currency(payment)
+	methods, txIDs := []string{payment.PaymentMethod}, []string{payment.TxID}
+	for _, result := range results[1:] {
+		if !sameCurrency(currency(result), payment.Currency) {
+			return core.InvoicePaymentResult{}, fmt.Errorf("invoice %s has payments in %s and %s, which cannot be combined on one receipt",
+				params.ID, payment.Currency, currency(result))
+		}
+		payment.Amount = payment.Amount.Add(result.Amount)
+		if result.PaidDate.After(payment.PaidDate) {
+			payment.PaidDate = result.PaidDate
+		}
+		methods, txIDs = append(methods, result.PaymentMethod), append(txIDs, result.TxID)
+	}
+	payment.PaymentMethod = joinDistinct(methods)
+	payment.TxID = joinDistinct(txIDs)
+	return payment, nil
+}
+
+// joinDistinct joins the distinct non-empty values, keeping their order.
+func joinDistinct(values []string) string {
+	seen := make(map[string]bool, len(values))
+	distinct := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" && !seen[value] {
+			seen[value] = true
+			distinct = append(distinct, value)
+		}
+	}
+	return strings.Join(distinct, ", ")
+}
+
+// receiptParamsFromInvoice records payment as the only payment result, dates the receipt on its
+// date and drops the parts of the invoice a receipt does not show: payment instructions, due date
+// and terms, and the invoice's own title.
+func receiptParamsFromInvoice(params *core.InvoiceParams, payment core.InvoicePaymentResult) *core.InvoiceParams {
+	receipt := *params
+	if !payment.PaidDate.IsZero() {
+		receipt.Date = payment.PaidDate
+	}
+	receipt.Payment = core.InvoicePayment{
+		InvoicePaymentInstruction: core.InvoicePaymentInstruction{Disabled: true},
+		InvoicePaymentResult:      payment,
+	}
+	receipt.DueDate, receipt.PaymentTerms, receipt.LateFee = time.Time{}, "", ""
+	receipt.Doc = core.InvoiceDoc{}
+	return &receipt
+}
@@ -0,0 +1,128 @@
+package builder
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+func TestGenerateReceipt(t *testing.T) {
+	b, err := NewReceiptBuilderFromFile(Config{
+		FontName:   "noto-sans-cjk",
+		FontNormal: "../fonts/NotoSansCJK-JP/NotoSansCJKjp-Regular.ttf",
+		FontBold:   "../fonts/NotoSansCJK-JP/NotoSansCJKjp-Bold.ttf",
+		Lang:       "ja",
+	}, "../samples/invoice-2.yaml")
+	if err != nil {
+		t.Fatalf("NewReceiptBuilderFromFile: %v", err)
+	}
+	if got := b.invoiceDocTitle(); got != "領収書" {
+		t.Fatalf("title = %q, want 領収書", got)
+	}
+	if got := b.iParams.Date.Format("2006-01-02"); got != "2024-02-20" {
+		t.Fatalf("receipt date = %s, want the paid date 2024-02-20", got)
+	}
+	if got := b.invoiceHeaderLastLine(""); got != "請求書: 20240210-SAMPLE (2024/02/10)" {
+		t.Fatalf("header reference = %q", got)
+	}
+	if !b.receiptRequiresRevenueStamp() {
+		t.Fatal("a 550,000 yen receipt requires a revenue stamp")
+	}
+
+	buf, err := b.GenerateReceipt()
+	if err != nil || !bytes.HasPrefix(buf, []byte("%PDF")) {
+		t.Fatalf("GenerateReceipt() = %v", err)
+	}
+}
+
+func TestReceiptRevenueStampThreshold(t *testing.T) {
+	receipt := func(cfg Config, currency string, subtotal, paid int64) *Builder {
+		t.Helper()
+		params := &core.InvoiceParams{
+			ID:       "1",
+			Currency: currency,
+			Summary: core.InvoiceSummary{
+				TotalExcludeTax: decimal.NewFromInt(subtotal),
+				TaxRate:         decimal.NewFromFloat(0.1),
+			},
+			Payment: core.InvoicePayment{
+				InvoicePaymentResult: core.InvoicePaymentResult{Amount: decimal.NewFromInt(paid)},
+			},
+		}
+		b, err := NewReceiptBuilder(cfg, params)
+		if err != nil {
+			t.Fatalf("NewReceiptBuilder: %v", err)
+		}
+		return b
+	}
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		currency string
+		subtotal int64
+		paid     int64
+		want     bool
+	}{
+		{"tax excluded on full payment", Config{}, "JPY", 49900, 54890, false},
+		{"threshold reached", Config{}, "JPY", 50000, 55000, true},
+		{"partial payment counts in full", Config{}, "JPY", 100000, 50000, true},
+		{"below threshold", Config{}, "JPY", 100000, 49999, false},
+		{"lower-case code", Config{}, " jpy", 50000, 55000, true},
+		{"tax excluded in an alias", Config{}, "円", 49900, 54890, false},
+		{"other currencies", Config{}, "USD", 100000, 110000, false},
+		{"omitted", Config{OmitRevenueStamp: true}, "JPY", 100000, 110000, false},
+	}
+	for _, tc := range tests {
+		if got := receipt(tc.cfg, tc.currency, tc.subtotal, tc.paid).receiptRequiresRevenueStamp(); got != tc.want {
+			t.Errorf("%s: receiptRequiresRevenueStamp() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestNewReceiptBuilderRequiresPaymentResult(t *testing.T) {
+	params := &core.InvoiceParams{}
+	if err := params.Load("../samples/invoice-1.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if _, err := NewReceiptBuilder(Config{}, params); err == nil {
+		t.Fatal("NewReceiptBuilder accepted an invoice without a payment result")
+	}
+}
+
+func TestReceiptForInstallments(t *testing.T) {
+	params := &core.InvoiceParams{}
+	if err := params.Load("../samples/invoice-6.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	b, err := NewReceiptBuilder(Config{}, params)
+	if err != nil {
+		t.Fatalf("NewReceiptBuilder: %v", err)
+	}
+	if amount, currency := b.receiptAmount(); amount.String() != "22000" || currency != "USD" {
+		t.Fatalf("receiptAmount() = %s %s, want the two payments, 22000 USD", amount, currency)
+	}
+	if got := b.iParams.Date.Format("2006-01-02"); got != "2024-04-30" {
+		t.Fatalf("receipt date = %s, want the latest payment 2024-04-30", got)
+	}
+	if got := b.iParams.Payment.TxID; got != "DEP-20240301, BOA-88213" {
+		t.Fatalf("transaction IDs = %q", got)
+	}
+	if paid, balance, ok := b.invoiceBalance(); !ok || paid.String() != "22000" || balance.String() != "11000" {
+		t.Fatalf("invoiceBalance() = %s, %s, %v; want the receipt amount and 11000 due", paid, balance, ok)
+	}
+	if buf, err := b.GenerateReceipt(); err != nil || !bytes.HasPrefix(buf, []byte("%PDF")) {
+		t.Fatalf("GenerateReceipt() = %v", err)
+	}
+
+	params.Payment.Results[1].Currency = "EUR"
+	if _, err := NewReceiptBuilder(Config{}, params); err == nil {
+		t.Fatal("payments in USD and EUR were combined")
+	}
+	params.Payment.Results = nil
+	if _, err := NewReceiptBuilder(Config{}, params); err == nil {
+		t.Fatal("receipt issued without a payment")
+	}
+}
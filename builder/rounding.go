@@ -0,0 +1,96 @@
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+// Rounding levels.
+const (
+	RoundPerDocument = "document"
+	RoundPerLine     = "line"
+)
+
+func validateRounding(cfg Config) error {
+	if _, err := core.ParseRoundingMode(cfg.Rounding); err != nil {
+		return err
+	}
+	switch normalizeRoundingLevel(cfg.RoundingLevel) {
+	case "", RoundPerDocument:
+	case RoundPerLine:
+		// A qualified invoice rounds the tax once per rate (端数処理は税率ごとに1回).
+		if normalizeCompliance(cfg.Compliance) == ComplianceJPQualifiedInvoice {
+			return fmt.Errorf("jp qualified invoice: rounding level %q: the tax is rounded once per rate", cfg.RoundingLevel)
+		}
+	default:
+		return fmt.Errorf("unknown rounding level %q; use %s or %s", cfg.RoundingLevel, RoundPerDocument, RoundPerLine)
+	}
+	for code, decimals := range cfg.CurrencyDecimals {
+		if decimals < 0 || decimals > 8 {
+			return fmt.Errorf("currency decimals of %s: %d is not between 0 and 8", code, decimals)
+		}
+	}
+	return nil
+}
+
+func normalizeRoundingLevel(level string) string {
+	return strings.ToLower(strings.TrimSpace(level))
+}
+
+// currencyDecimals returns the decimals amounts in currency are rounded to.
+func currencyDecimals(cfg Config, currency string) int32 {
+	decimals, _ := lookupCurrencyDecimals(cfg, currency)
+	return decimals
+}
+
+// lookupCurrencyDecimals is currencyDecimals, also reporting whether the decimals are known:
+// configured in Config.CurrencyDecimals or given by ISO 4217.
+func lookupCurrencyDecimals(cfg Config, currency string) (int32, bool) {
+	code := strings.TrimSpace(currency)
+	if cur, ok := core.LookupCurrency(code); ok {
+		code = cur.Code
+	}
+	for key, decimals := range cfg.CurrencyDecimals {
+		if strings.EqualFold(strings.TrimSpace(key), code) {
+			return decimals, true
+		}
+	}
+	_, known := core.LookupCurrency(code)
+	return core.AmountDecimals(code), known
+}
+
+// rounding returns the rounding of amounts in currency under Config.Rounding. Amounts in the
+// document currency are rounded to Builder.Round decimals.
+func (b *Builder) rounding(currency string) core.Rounding {
+	mode, _ := core.ParseRoundingMode(b.cfg.Rounding)
+	if mode == "" {
+		mode = core.RoundHalfUp
+	}
+	decimals := currencyDecimals(b.cfg, currency)
+	if b.iParams != nil && sameCurrency(currency, b.iParams.Currency) {
+		decimals = b.Round
+	}
+	return core.Rounding{Mode: mode, Decimals: decimals}
+}
+
+// round rounds an amount in currency, e.g. a total or a percentage discount.
+func (b *Builder) round(amount decimal.Decimal, currency string) decimal.Decimal {
+	return b.rounding(currency).Round(amount)
+}
+
+// roundTax rounds a tax amount in currency. Qualified invoices round down (切り捨て) unless
+// Config.Rounding is set.
+func (b *Builder) roundTax(tax decimal.Decimal, currency string) decimal.Decimal {
+	r := b.rounding(currency)
+	if strings.TrimSpace(b.cfg.Rounding) == "" && b.jpQualifiedInvoice() {
+		r.Mode = core.RoundDown
+	}
+	return r.Round(tax)
+}
+
+func (b *Builder) roundPerLine() bool {
+	return normalizeRoundingLevel(b.cfg.RoundingLevel) == RoundPerLine
+}
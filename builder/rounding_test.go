@@ -0,0 +1,159 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+// supportItem is 1 × 1.05 taxed at 10%, whose tax of 0.105 rounds differently per mode and level.
+var supportItem = core.InvoiceDetailItem{Title: "Support", Quantity: decimal.NewFromInt(1), UnitPrice: decimal.RequireFromString("1.05"), TaxRate: decimal.RequireFromString("0.1")}
+
+func TestInvoiceRoundingModesAndLevels(t *testing.T) {
+	tests := []struct {
+		rounding, level string
+		tax, total      string
+	}{
+		{rounding: "", level: "", tax: "0.32", total: "3.47"},
+		{rounding: core.RoundHalfUp, level: RoundPerLine, tax: "0.33", total: "3.48"},
+		{rounding: core.RoundHalfEven, level: RoundPerDocument, tax: "0.32", total: "3.47"},
+		{rounding: core.RoundHalfEven, level: RoundPerLine, tax: "0.3", total: "3.45"},
+		{rounding: core.RoundDown, level: RoundPerDocument, tax: "0.31", total: "3.46"},
+		{rounding: core.RoundDown, level: RoundPerLine, tax: "0.3", total: "3.45"},
+	}
+	for _, tt := range tests {
+		b, err := NewInvoiceBuilder(Config{Rounding: tt.rounding, RoundingLevel: tt.level}, testInvoiceParams(supportItem, supportItem, supportItem))
+		if err != nil {
+			t.Fatalf("NewInvoiceBuilder: %v", err)
+		}
+		nums := b.invoiceSummaryNumbers()
+		if nums.Tax.String() != tt.tax || nums.Total.String() != tt.total {
+			t.Errorf("%s/%s: tax %s, total %s; want %s, %s", tt.rounding, tt.level, nums.Tax, nums.Total, tt.tax, tt.total)
+		}
+	}
+}
+
+func TestInvoiceSummaryRateRoundsPerLine(t *testing.T) {
+	params := testInvoiceParams(supportItem, supportItem, supportItem)
+	for ix := range params.DetailItems {
+		params.DetailItems[ix].TaxRate = decimal.Zero
+	}
+	params.Summary.TotalExcludeTax = decimal.RequireFromString("3.15")
+	params.Summary.TaxRate = decimal.RequireFromString("0.1")
+
+	for level, want := range map[string]string{RoundPerDocument: "0.32", RoundPerLine: "0.33"} {
+		b, err := NewInvoiceBuilder(Config{RoundingLevel: level}, params)
+		if err != nil {
+			t.Fatalf("NewInvoiceBuilder: %v", err)
+		}
+		if got := b.invoiceSummaryNumbers().Tax.String(); got != want {
+			t.Errorf("%s: tax %s, want %s", level, got, want)
+		}
+	}
+}
+
+// TestInvoiceTotalsReconcile checks that the printed lines, adjustments and per-rate taxes add up
+// to the printed totals under every rounding policy.
+func TestInvoiceTotalsReconcile(t *testing.T) {
+	params := testInvoiceParams(supportItem, supportItem, supportItem)
+	params.DetailItems = append(params.DetailItems,
+		core.InvoiceDetailItem{Title: "API calls", Quantity: decimal.NewFromInt(3), UnitPrice: decimal.RequireFromString("0.125"), TaxRate: decimal.RequireFromString("0.1")},
+		core.InvoiceDetailItem{
+			Title: "Books", Quantity: decimal.NewFromInt(7), UnitPrice: decimal.RequireFromString("2.99"), TaxRate: decimal.RequireFromString("0.07"),
+			Adjustments: []core.InvoiceAdjustment{{Kind: core.AdjustmentDiscount, Percent: decimal.RequireFromString("7.5")}},
+		},
+	)
+	params.Summary.Adjustments = []core.InvoiceAdjustment{
+		{Kind: core.AdjustmentDiscount, Percent: decimal.RequireFromString("3.3")},
+		{Kind: core.AdjustmentSurcharge, Amount: decimal.RequireFromString("0.99"), Untaxed: true},
+	}
+
+	for _, mode := range []string{core.RoundHalfUp, core.RoundHalfEven, core.RoundDown} {
+		for _, level := range []string{RoundPerDocument, RoundPerLine} {
+			b, err := NewInvoiceBuilder(Config{Rounding: mode, RoundingLevel: level}, params)
+			if err != nil {
+				t.Fatalf("NewInvoiceBuilder: %v", err)
+			}
+			nums := b.invoiceSummaryNumbers()
+
+			lines, lineTax := decimal.Zero, decimal.Zero
+			for _, item := range params.DetailItems {
+				amounts := b.invoiceLineAmounts(item)
+				lines = lines.Add(amounts.ExcludeTax)
+				lineTax = lineTax.Add(amounts.Tax)
+			}
+			taxed, untaxed := sumInvoiceAdjustments(nums.Adjustments)
+			for _, adj := range nums.Adjustments {
+				if !adj.Untaxed {
+					lineTax = lineTax.Add(b.roundTax(adj.Amount.Mul(adj.Rate), "USD"))
+				}
+			}
+			base, tax := sumInvoiceTaxBreakdown(nums.TaxBreakdown)
+
+			name := mode + "/" + level
+			for label, amount := range map[string]decimal.Decimal{"subtotal": nums.Subtotal, "tax": nums.Tax, "total": nums.Total} {
+				if !amount.Equal(amount.Round(2)) {
+					t.Errorf("%s: %s %s has more than two decimals", name, label, amount)
+				}
+			}
+			if !nums.ItemsTotal.Equal(lines) || !nums.Subtotal.Equal(lines.Add(taxed)) || !base.Equal(nums.Subtotal) {
+				t.Errorf("%s: lines %s + adjustments %s do not add up to subtotal %s (breakdown %s)", name, lines, taxed, nums.Subtotal, base)
+			}
+			if !tax.Equal(nums.Tax) || !nums.Total.Equal(nums.Subtotal.Add(nums.Tax).Add(untaxed)) {
+				t.Errorf("%s: subtotal %s + tax %s (breakdown %s) + %s do not add up to total %s", name, nums.Subtotal, nums.Tax, tax, untaxed, nums.Total)
+			}
+			if level == RoundPerLine && !lineTax.Equal(nums.Tax) {
+				t.Errorf("%s: line taxes add up to %s, want the tax %s", name, lineTax, nums.Tax)
+			}
+		}
+	}
+}
+
+func TestInvoiceRoundsToThreeDecimalCurrencies(t *testing.T) {
+	item := supportItem
+	item.UnitPrice = decimal.RequireFromString("1.235")
+	params := testInvoiceParams(item, item, item)
+	params.Currency = "KWD"
+	b, err := NewInvoiceBuilder(Config{Lang: "en"}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+	nums := b.invoiceSummaryNumbers()
+	if nums.Subtotal.String() != "3.705" || nums.Total.String() != "4.076" {
+		t.Errorf("subtotal %s, total %s; want 3.705, 4.076", nums.Subtotal, nums.Total)
+	}
+	if got := b.formatMoney(nums.Total, "KWD"); got != "KWD 4.076" {
+		t.Errorf("formatMoney = %q, want %q", got, "KWD 4.076")
+	}
+}
+
+func TestInvoiceRoundingConfig(t *testing.T) {
+	item := supportItem
+	item.UnitPrice = decimal.RequireFromString("1.0512345")
+	params := testInvoiceParams(item, item, item)
+	params.Currency = "USDT"
+	b, err := NewInvoiceBuilder(Config{CurrencyDecimals: map[string]int32{"usdt": 4}}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+	if nums := b.invoiceSummaryNumbers(); nums.Subtotal.String() != "3.1536" || nums.Tax.String() != "0.3154" {
+		t.Errorf("subtotal %s, tax %s; want 3.1536, 0.3154", nums.Subtotal, nums.Tax)
+	}
+	if got := b.formatMoney(decimal.RequireFromString("3.1536"), "USDT"); got != "USDT 3.1536" {
+		t.Errorf("formatMoney = %q, want %q", got, "USDT 3.1536")
+	}
+
+	for _, cfg := range []Config{
+		{Rounding: "ceiling"},
+		{RoundingLevel: "page"},
+		{CurrencyDecimals: map[string]int32{"USD": -1}},
+		{Compliance: ComplianceJPQualifiedInvoice, RoundingLevel: RoundPerLine},
+	} {
+		params := testInvoiceParams(supportItem, supportItem, supportItem)
+		params.TaxNumber = "T1234567890123"
+		if _, err := NewInvoiceBuilder(cfg, params); err == nil {
+			t.Errorf("NewInvoiceBuilder(%+v) succeeded, want an error", cfg)
+		}
+	}
+}
@@ -12,7 +12,8 @@ func NewSettlementStatementBuilder(cfg Config, params *core.SettlementStatementP
 	if err != nil {
 		return nil, err
 	}
-	builder.docLabelSet = labelSetStatement
+	builder.kind = kindStatement
+	builder.validateParams = paramsValidator(cfg, params.Validate, params.ValidateWithoutSummaryCheck)
 	return builder, nil
 }
 
@@ -25,7 +26,14 @@ func NewSettlementStatementBuilderFromFile(cfg Config, filename string) (*Builde
 }
 
 func (b *Builder) GenerateSettlementStatement() ([]byte, error) {
-	layout := InvoiceLayoutByName(b.cfg.SettlementStatementLayout)
+	if err := b.checkParams(); err != nil {
+		return nil, err
+	}
+	layout, err := InvoiceLayoutByName(b.cfg.SettlementStatementLayout)
+	if err != nil {
+		log.Printf("failed to select settlement statement layout: %v\n", err)
+		return nil, err
+	}
 	headers, body, err := layout.Build(b)
 	if err != nil {
 		log.Printf("failed to build settlement statement layout %q: %v\n", layout.Name(), err)
@@ -0,0 +1,47 @@
+package builder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/quailyquaily/bizdocgen/core"
+)
+
+func TestGenerateRefusesInvalidParams(t *testing.T) {
+	params := &core.InvoiceParams{}
+	if err := params.Load("../samples/invoice-1.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	params.ID = ""
+
+	b, err := NewInvoiceBuilder(Config{}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+	if _, err := b.GenerateInvoice(); err != nil {
+		t.Fatalf("GenerateInvoice without ValidateParams: %v", err)
+	}
+
+	b, err = NewInvoiceBuilder(Config{ValidateParams: true}, params)
+	if err != nil {
+		t.Fatalf("NewInvoiceBuilder: %v", err)
+	}
+	var errs core.ValidationErrors
+	if _, err := b.GenerateInvoice(); !errors.As(err, &errs) {
+		t.Fatalf("GenerateInvoice = %v, want ValidationErrors", err)
+	}
+
+	statement := &core.SettlementStatementParams{}
+	if err := statement.Load("../samples/settlementstatement-1.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	statement.RecipientCompany = ""
+	b, err = NewSettlementStatementBuilder(Config{ValidateParams: true}, statement)
+	if err != nil {
+		t.Fatalf("NewSettlementStatementBuilder: %v", err)
+	}
+	_, err = b.GenerateSettlementStatement()
+	if !errors.As(err, &errs) || errs[0].Field != "recipient_company" {
+		t.Fatalf("GenerateSettlementStatement = %v, want recipient_company error", err)
+	}
+}
@@ -0,0 +1,71 @@
+// Command bizdocgen-server renders business documents over a local REST API.
+//
+//	POST /invoice               body: invoice params (JSON, YAML or TOML) -> application/pdf
+//	POST /settlement-statement  body: statement params                    -> application/pdf
+//	POST /credit-note           body: credit note params                  -> application/pdf
+//	POST /quote                 body: quote params                        -> application/pdf
+//	POST /receipt               body: paid invoice params                 -> application/pdf
+//	POST /purchase-order        body: purchase order params               -> application/pdf
+//	POST /delivery-note         body: delivery note params                -> application/pdf
+//	GET  /layouts               -> JSON list of layout names
+//
+// Rendering options are passed as query parameters: lang, layout and compliance. Exchange rates
+// for reference amounts are read from the -rates table at startup. Factur-X output (facturx)
+// needs the -font-normal and -font-bold TTF fonts, as PDF/A-3 embeds every font.
+// Invalid params are answered with 422 and a JSON list of field errors.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/quailyquaily/bizdocgen/core"
+)
+
+func main() {
+	fs := flag.NewFlagSet("bizdocgen-server", flag.ExitOnError)
+	addr := fs.String("addr", "127.0.0.1:8080", "listen address")
+	maxBody := fs.Int64("max-body", 1<<20, "maximum request body size in bytes")
+	timeout := fs.Duration("timeout", 30*time.Second, "maximum time spent rendering one document")
+	sealDir := fs.String("seal-dir", "", "directory company_seal paths are resolved in (empty disables seals)")
+	fontName := fs.String("font-name", "", "font family name for the custom fonts")
+	fontNormal := fs.String("font-normal", "", "path to the regular TTF font")
+	fontItalic := fs.String("font-italic", "", "path to the italic TTF font")
+	fontBold := fs.String("font-bold", "", "path to the bold TTF font")
+	fontBoldItalic := fs.String("font-bold-italic", "", "path to the bold italic TTF font")
+	rates := fs.String("rates", "", "exchange rate table (YAML, JSON, TOML or CSV) for reference amounts")
+	_ = fs.Parse(os.Args[1:])
+
+	srv := &server{
+		maxBodyBytes: *maxBody,
+		sealDir:      *sealDir,
+	}
+	srv.cfg.FontName = *fontName
+	srv.cfg.FontNormal = *fontNormal
+	srv.cfg.FontItalic = *fontItalic
+	srv.cfg.FontBold = *fontBold
+	srv.cfg.FontBoldItalic = *fontBoldItalic
+	if *rates != "" {
+		table := &core.ExchangeRateTable{}
+		if err := table.Load(*rates); err != nil {
+			log.Fatal(err)
+		}
+		srv.cfg.ExchangeRates = table
+	}
+
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           withTimeout(srv.handler(), *timeout),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       *timeout,
+		WriteTimeout:      *timeout + 5*time.Second,
+		IdleTimeout:       time.Minute,
+	}
+	log.Printf("bizdocgen-server listening on %s\n", *addr)
+	if err := httpServer.ListenAndServe(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/quailyquaily/bizdocgen/builder"
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/quailyquaily/bizdocgen/einvoice"
+	"github.com/quailyquaily/bizdocgen/facturx"
+	"github.com/shopspring/decimal"
+)
+
+type server struct {
+	// cfg holds the server-wide options (fonts); lang, layout and compliance come from the request.
+	cfg          builder.Config
+	maxBodyBytes int64
+	// sealDir confines company_seal paths; an empty sealDir rejects params that set one.
+	sealDir string
+}
+
+// errorResponse is the JSON body of every non-PDF answer.
+type errorResponse struct {
+	Error  string            `json:"error"`
+	Errors []core.FieldError `json:"errors,omitempty"`
+	// Rules lists the violated e-invoice business rules of a Factur-X request.
+	Rules []einvoice.RuleError `json:"rules,omitempty"`
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /invoice", s.handleInvoice)
+	mux.HandleFunc("POST /settlement-statement", s.handleSettlementStatement)
+	mux.HandleFunc("POST /credit-note", s.handleCreditNote)
+	mux.HandleFunc("POST /quote", s.handleQuote)
+	mux.HandleFunc("POST /receipt", s.handleReceipt)
+	mux.HandleFunc("POST /purchase-order", s.handlePurchaseOrder)
+	mux.HandleFunc("POST /delivery-note", s.handleDeliveryNote)
+	mux.HandleFunc("GET /layouts", s.handleLayouts)
+	return mux
+}
+
+// withTimeout is http.TimeoutHandler answering requests that run out of time with a JSON
+// errorResponse, like every other answer that is not a PDF.
+func withTimeout(h http.Handler, timeout time.Duration) http.Handler {
+	body, _ := json.Marshal(errorResponse{Error: "rendering timed out"})
+	timeoutHandler := http.TimeoutHandler(h, timeout, string(body))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Headers set by h replace this one; only the timeout answer keeps it.
+		w.Header().Set("Content-Type", "application/json")
+		timeoutHandler.ServeHTTP(w, r)
+	})
+}
+
+func (s *server) handleInvoice(w http.ResponseWriter, r *http.Request) {
+	cfg, err := s.requestConfig(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	cfg.InvoiceLayout = r.URL.Query().Get("layout")
+	if cfg.FacturXProfile = r.URL.Query().Get("facturx"); cfg.FacturXProfile != "" {
+		if _, err := facturx.ParseProfile(cfg.FacturXProfile); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if cfg.FontNormal == "" || cfg.FontBold == "" {
+			writeError(w, http.StatusBadRequest, errors.New("facturx needs embedded fonts: start the server with -font-normal and -font-bold"))
+			return
+		}
+	}
+
+	params := &core.InvoiceParams{}
+	if !s.readParams(w, r, params) {
+		return
+	}
+	if params.CompanySeal, err = s.resolveSeal(params.CompanySeal); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	bd, err := builder.NewInvoiceBuilder(cfg, params)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	buf, err := bd.GenerateInvoice()
+	s.writePDF(w, buf, err)
+}
+
+func (s *server) handleSettlementStatement(w http.ResponseWriter, r *http.Request) {
+	cfg, err := s.requestConfig(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	cfg.SettlementStatementLayout = r.URL.Query().Get("layout")
+
+	params := &core.SettlementStatementParams{}
+	if !s.readParams(w, r, params) {
+		return
+	}
+	if params.CompanySeal, err = s.resolveSeal(params.CompanySeal); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	bd, err := builder.NewSettlementStatementBuilder(cfg, params)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	buf, err := bd.GenerateSettlementStatement()
+	s.writePDF(w, buf, err)
+}
+
+func (s *server) handleCreditNote(w http.ResponseWriter, r *http.Request) {
+	cfg, err := s.requestConfig(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	cfg.CreditNoteLayout = r.URL.Query().Get("layout")
+
+	params := &core.CreditNoteParams{}
+	if !s.readParams(w, r, params) {
+		return
+	}
+	if params.CompanySeal, err = s.resolveSeal(params.CompanySeal); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	bd, err := builder.NewCreditNoteBuilder(cfg, params)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	buf, err := bd.GenerateCreditNote()
+	s.writePDF(w, buf, err)
+}
+
+func (s *server) handleQuote(w http.ResponseWriter, r *http.Request) {
+	cfg, err := s.requestConfig(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	cfg.QuoteLayout = r.URL.Query().Get("layout")
+
+	params := &core.QuoteParams{}
+	if !s.readParams(w, r, params) {
+		return
+	}
+	if params.CompanySeal, err = s.resolveSeal(params.CompanySeal); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	bd, err := builder.NewQuoteBuilder(cfg, params)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	buf, err := bd.GenerateQuote()
+	s.writePDF(w, buf, err)
+}
+
+func (s *server) handleReceipt(w http.ResponseWriter, r *http.Request) {
+	cfg, err := s.requestConfig(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	cfg.OmitRevenueStamp = r.URL.Query().Get("revenue_stamp") == "omit"
+
+	params := &core.InvoiceParams{}
+	if !s.readParams(w, r, params) {
+		return
+	}
+	if params.CompanySeal, err = s.resolveSeal(params.CompanySeal); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	bd, err := builder.NewReceiptBuilder(cfg, params)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	buf, err := bd.GenerateReceipt()
+	s.writePDF(w, buf, err)
+}
+
+func (s *server) handlePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	cfg, err := s.requestConfig(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	cfg.PurchaseOrderLayout = r.URL.Query().Get("layout")
+
+	params := &core.PurchaseOrderParams{}
+	if !s.readParams(w, r, params) {
+		return
+	}
+	if params.CompanySeal, err = s.resolveSeal(params.CompanySeal); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	bd, err := builder.NewPurchaseOrderBuilder(cfg, params)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	buf, err := bd.GeneratePurchaseOrder()
+	s.writePDF(w, buf, err)
+}
+
+func (s *server) handleDeliveryNote(w http.ResponseWriter, r *http.Request) {
+	cfg, err := s.requestConfig(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	cfg.DeliveryNoteLayout = r.URL.Query().Get("layout")
+
+	params := &core.DeliveryNoteParams{}
+	if !s.readParams(w, r, params) {
+		return
+	}
+	if params.CompanySeal, err = s.resolveSeal(params.CompanySeal); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	bd, err := builder.NewDeliveryNoteBuilder(cfg, params)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	buf, err := bd.GenerateDeliveryNote()
+	s.writePDF(w, buf, err)
+}
+
+func (s *server) handleLayouts(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, builder.BuiltinLayoutNames())
+}
+
+// requestConfig derives the builder config from the query parameters.
+// Params are always validated: the server never renders a document Validate() rejects.
+func (s *server) requestConfig(r *http.Request) (builder.Config, error) {
+	query := r.URL.Query()
+	cfg := s.cfg
+	cfg.Lang = query.Get("lang")
+	cfg.Locale = query.Get("locale")
+	cfg.Compliance = query.Get("compliance")
+	cfg.Rounding = query.Get("rounding")
+	cfg.RoundingLevel = query.Get("rounding_level")
+	cfg.SummaryCheck = query.Get("summary_check")
+	cfg.MarkOverdue = query.Get("overdue") == "mark"
+	cfg.ValidateParams = true
+	if tolerance := query.Get("summary_tolerance"); tolerance != "" {
+		value, err := decimal.NewFromString(tolerance)
+		if err != nil {
+			return cfg, fmt.Errorf("summary_tolerance: %w", err)
+		}
+		cfg.SummaryTolerance = value
+	}
+
+	if _, err := builder.InvoiceLayoutByName(query.Get("layout")); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// readParams decodes the size-limited request body into params, answering the request on failure.
+func (s *server) readParams(w http.ResponseWriter, r *http.Request, params interface{ LoadFromReader(io.Reader) error }) bool {
+	body := http.MaxBytesReader(w, r.Body, s.maxBodyBytes)
+	err := params.LoadFromReader(body)
+	if err == nil {
+		return true
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("request body exceeds %d bytes", tooLarge.Limit))
+		return false
+	}
+	writeError(w, http.StatusBadRequest, err)
+	return false
+}
+
+// resolveSeal maps a company_seal value onto the seal directory, refusing paths that escape it.
+func (s *server) resolveSeal(seal string) (string, error) {
+	if seal == "" {
+		return "", nil
+	}
+	if s.sealDir == "" {
+		return "", core.ValidationErrors{{Field: "company_seal", Message: "seals are disabled on this server"}}
+	}
+	if !filepath.IsLocal(seal) {
+		return "", core.ValidationErrors{{Field: "company_seal", Message: "must be a relative path inside the seal directory"}}
+	}
+	return filepath.Join(s.sealDir, seal), nil
+}
+
+func (s *server) writePDF(w http.ResponseWriter, buf []byte, err error) {
+	if err != nil {
+		var validationErrs core.ValidationErrors
+		var ruleErrs einvoice.RuleErrors
+		if errors.As(err, &validationErrs) || errors.As(err, &ruleErrs) {
+			writeError(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+		log.Printf("failed to render document: %v\n", err)
+		writeError(w, http.StatusInternalServerError, errors.New("failed to render document"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Length", fmt.Sprint(len(buf)))
+	if _, err := w.Write(buf); err != nil {
+		log.Printf("failed to write response: %v\n", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	resp := errorResponse{Error: err.Error()}
+	var validationErrs core.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		resp.Error = "invalid params"
+		resp.Errors = validationErrs
+	}
+	var ruleErrs einvoice.RuleErrors
+	if errors.As(err, &ruleErrs) {
+		resp.Error = "e-invoice rules violated"
+		resp.Rules = ruleErrs
+	}
+	writeJSON(w, status, resp)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("failed to write response: %v\n", err)
+	}
+}
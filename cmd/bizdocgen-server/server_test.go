@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := &server{maxBodyBytes: 1 << 20, sealDir: "../.."}
+	ts := httptest.NewServer(srv.handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func post(t *testing.T, url, contentType string, body []byte) *http.Response {
+	t.Helper()
+	resp, err := http.Post(url, contentType, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST %s: %v", url, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestServerRendersInvoiceAndStatement(t *testing.T) {
+	ts := newTestServer(t)
+	cases := []struct {
+		path        string
+		sample      string
+		contentType string
+	}{
+		{"/invoice?layout=modern&lang=ja", "../../samples/invoice-1.json", "application/json"},
+		{"/settlement-statement", "../../samples/settlementstatement-1.yaml", "application/yaml"},
+		{"/credit-note?layout=split", "../../samples/creditnote-1.yaml", "application/yaml"},
+		{"/quote?lang=ja", "../../samples/quote-1.yaml", "application/yaml"},
+		{"/purchase-order?layout=ledger", "../../samples/purchaseorder-1.yaml", "application/yaml"},
+		{"/delivery-note?layout=modern", "../../samples/deliverynote-1.yaml", "application/yaml"},
+	}
+	for _, tc := range cases {
+		body, err := os.ReadFile(tc.sample)
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		resp := post(t, ts.URL+tc.path, tc.contentType, body)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: status = %d", tc.path, resp.StatusCode)
+		}
+		if got := resp.Header.Get("Content-Type"); got != "application/pdf" {
+			t.Fatalf("%s: Content-Type = %q", tc.path, got)
+		}
+	}
+}
+
+func TestServerRendersReceipt(t *testing.T) {
+	ts := newTestServer(t)
+	body, err := os.ReadFile("../../samples/invoice-2.yaml")
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	body = bytes.Replace(body, []byte(`"../sample-seal.png"`), []byte(`"sample-seal.png"`), 1)
+	resp := post(t, ts.URL+"/receipt?lang=ja&revenue_stamp=omit", "application/yaml", body)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/pdf" {
+		t.Fatalf("status = %d, Content-Type = %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	unpaid, err := os.ReadFile("../../samples/invoice-1.json")
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	resp = post(t, ts.URL+"/receipt", "application/json", unpaid)
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("unpaid invoice: status = %d, want %d", resp.StatusCode, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestServerReportsValidationErrorsAsJSON(t *testing.T) {
+	ts := newTestServer(t)
+	resp := post(t, ts.URL+"/invoice", "application/json", []byte(`{"currency": "USD"}`))
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusUnprocessableEntity)
+	}
+	var got errorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	fields := make([]string, 0, len(got.Errors))
+	for _, fieldErr := range got.Errors {
+		fields = append(fields, fieldErr.Field)
+	}
+	if !strings.Contains(strings.Join(fields, ","), "id") {
+		t.Fatalf("errors = %+v, want a field error for id", got.Errors)
+	}
+}
+
+func TestServerReportsFacturXRuleErrors(t *testing.T) {
+	// Factur-X output embeds its fonts; the Go fonts stand in for the server's -font flags.
+	srv := &server{maxBodyBytes: 1 << 20}
+	dir := t.TempDir()
+	srv.cfg.FontName = "go"
+	srv.cfg.FontNormal, srv.cfg.FontBold = filepath.Join(dir, "Go-Regular.ttf"), filepath.Join(dir, "Go-Bold.ttf")
+	if err := os.WriteFile(srv.cfg.FontNormal, goregular.TTF, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(srv.cfg.FontBold, gobold.TTF, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv.handler())
+	t.Cleanup(ts.Close)
+	invoice, err := os.ReadFile("../../samples/invoice-1.yaml")
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	resp := post(t, ts.URL+"/invoice?facturx=en16931", "application/yaml", invoice)
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusUnprocessableEntity)
+	}
+	var got errorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got.Rules) == 0 || got.Rules[0].Rule != "BR-9" {
+		t.Fatalf("rules = %+v, want BR-9 (seller country) first", got.Rules)
+	}
+}
+
+func TestServerRejectsBadRequests(t *testing.T) {
+	ts := newTestServer(t)
+	invoice, err := os.ReadFile("../../samples/invoice-1.yaml")
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	cases := []struct {
+		name string
+		path string
+		body []byte
+		want int
+	}{
+		{"unknown layout", "/invoice?layout=nope", invoice, http.StatusBadRequest},
+		{"unknown Factur-X profile", "/invoice?facturx=extended", invoice, http.StatusBadRequest},
+		{"Factur-X without fonts", "/invoice?facturx=en16931", invoice, http.StatusBadRequest},
+		{"malformed summary tolerance", "/invoice?summary_check=fail&summary_tolerance=abc", invoice, http.StatusBadRequest},
+		{"unknown summary check", "/invoice?summary_check=strict", invoice, http.StatusUnprocessableEntity},
+		{"malformed body", "/invoice", []byte("{not json"), http.StatusBadRequest},
+		{"body too large", "/invoice", bytes.Repeat([]byte("#"), 2<<20), http.StatusRequestEntityTooLarge},
+		{"seal outside seal dir", "/invoice", append(invoice, "\ncompany_seal: ../../etc/passwd\n"...), http.StatusUnprocessableEntity},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := post(t, ts.URL+tc.path, "application/yaml", tc.body)
+			if resp.StatusCode != tc.want {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tc.want)
+			}
+		})
+	}
+}
+
+func TestServerAnswersTimeoutsWithJSON(t *testing.T) {
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	ts := httptest.NewServer(withTimeout(slow, time.Millisecond))
+	t.Cleanup(ts.Close)
+
+	resp := post(t, ts.URL+"/invoice", "application/json", []byte("{}"))
+	if resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("status = %d, Content-Type = %q; want 503 and JSON", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	var got errorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil || got.Error != "rendering timed out" {
+		t.Fatalf("body = %+v, %v; want the timeout error", got, err)
+	}
+}
+
+func TestServerListsLayouts(t *testing.T) {
+	ts := newTestServer(t)
+	resp, err := http.Get(ts.URL + "/layouts")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+	var names []string
+	if err := json.NewDecoder(resp.Body).Decode(&names); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(names) == 0 || names[0] != "classic" {
+		t.Fatalf("layouts = %v", names)
+	}
+}
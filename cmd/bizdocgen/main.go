@@ -0,0 +1,524 @@
+// Command bizdocgen renders business documents from YAML, JSON or TOML params.
+//
+//	bizdocgen invoice   [flags] <input|->
+//	bizdocgen statement [flags] <input|->
+//	bizdocgen creditnote [flags] <input|->
+//	bizdocgen quote     [flags] <input|->
+//	bizdocgen receipt   [flags] <input|->
+//	bizdocgen purchaseorder [flags] <input|->
+//	bizdocgen deliverynote  [flags] <input|->
+//	bizdocgen quote-to-invoice -id <invoice id> [-date YYYY-MM-DD] [-out file] <input|->
+//	bizdocgen ubl       [-out file] [-strict] <input|->
+//	bizdocgen validate  [-kind invoice|statement|creditnote|quote|purchaseorder|deliverynote] <input|->...
+//	bizdocgen batch     [flags] -out-dir <dir> <dir|glob|stream.yaml>...
+//	bizdocgen layouts
+//
+// An input of "-" reads stdin; "-out -" (the default) writes the PDF or XML to stdout.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/quailyquaily/bizdocgen/builder"
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/quailyquaily/bizdocgen/einvoice"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+const usage = `usage: bizdocgen <command> [flags]
+
+commands:
+  invoice    render an invoice PDF
+  statement  render a settlement statement PDF
+  creditnote render a credit note PDF
+  quote      render a quotation PDF
+  receipt    render a receipt PDF for a paid invoice
+  purchaseorder
+             render a purchase order PDF
+  deliverynote
+             render a delivery note PDF
+  quote-to-invoice
+             convert an accepted quote into invoice params (JSON)
+  ubl        export an invoice as UBL 2.1 / PEPPOL BIS Billing 3.0 XML
+  validate   check params files without rendering
+  batch      render many invoices (directories, globs, "---" YAML streams)
+  layouts    list the available layouts
+
+Run "bizdocgen <command> -h" for the flags of a command.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	cli := &cli{stdin: stdin, stdout: stdout, stderr: stderr}
+	switch args[0] {
+	case "invoice", "statement", "creditnote", "quote", "receipt", "purchaseorder", "deliverynote":
+		return cli.render(args[0], args[1:])
+	case "quote-to-invoice":
+		return cli.quoteToInvoice(args[1:])
+	case "ubl":
+		return cli.ubl(args[1:])
+	case "validate":
+		return cli.validate(args[1:])
+	case "batch":
+		return cli.batch(args[1:])
+	case "layouts":
+		return cli.layouts(args[1:])
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "bizdocgen: unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+}
+
+// document is implemented by the core params types.
+type document interface {
+	Load(filename string) error
+	LoadFromReader(r io.Reader) error
+	Validate() error
+}
+
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func (c *cli) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("bizdocgen "+name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	return fs
+}
+
+func (c *cli) fail(err error) int {
+	fmt.Fprintf(c.stderr, "bizdocgen: %v\n", err)
+	var validationErrs core.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		for _, fieldErr := range validationErrs {
+			fmt.Fprintf(c.stderr, "  %s\n", fieldErr)
+		}
+	}
+	var ruleErrs einvoice.RuleErrors
+	if errors.As(err, &ruleErrs) {
+		for _, ruleErr := range ruleErrs {
+			fmt.Fprintf(c.stderr, "  %s\n", ruleErr)
+		}
+	}
+	return exitError
+}
+
+// configFlags registers the rendering flags shared by the render and batch commands.
+// The returned string receives the -layout value.
+func configFlags(fs *flag.FlagSet, cfg *builder.Config) *string {
+	layout := fs.String("layout", "", "layout name (see `bizdocgen layouts`)")
+	fs.StringVar(&cfg.Lang, "lang", "", "document language: en, ja, zh_cn, zh_tw (default en)")
+	fs.StringVar(&cfg.Locale, "locale", "", `amount formatting locale, e.g. "de-DE" (default: -lang)`)
+	fs.StringVar(&cfg.Compliance, "compliance", "", "compliance mode, e.g. "+builder.ComplianceJPQualifiedInvoice)
+	fs.StringVar(&cfg.Rounding, "rounding", "", "rounding mode: half_up, half_even or down (default half_up)")
+	fs.StringVar(&cfg.RoundingLevel, "rounding-level", "", "round taxes per document or per line (default document)")
+	fs.BoolVar(&cfg.ValidateParams, "strict", false, "refuse params that fail validation")
+	fs.StringVar(&cfg.SummaryCheck, "summary-check", "", "cross-check the summary with the detail items: derive, warn or fail")
+	fs.Func("summary-tolerance", "largest summary difference -summary-check accepts (default 0)", func(value string) error {
+		tolerance, err := decimal.NewFromString(value)
+		if err != nil {
+			return err
+		}
+		cfg.SummaryTolerance = tolerance
+		return nil
+	})
+	fs.Func("rates", "exchange rate table (YAML, JSON, TOML or CSV) for reference amounts", func(filename string) error {
+		table := &core.ExchangeRateTable{}
+		if err := table.Load(filename); err != nil {
+			return err
+		}
+		cfg.ExchangeRates = table
+		return nil
+	})
+	fs.BoolVar(&cfg.MarkOverdue, "mark-overdue", false, "mark unpaid invoices past their due date as overdue")
+	fs.BoolVar(&cfg.OmitRevenueStamp, "no-revenue-stamp", false, "omit the revenue stamp box from yen receipts")
+	fs.StringVar(&cfg.FacturXProfile, "facturx", "", "embed Factur-X XML into invoices: minimum or en16931 (needs -font-normal and -font-bold)")
+	fs.StringVar(&cfg.FontName, "font-name", "", "font family name for the custom fonts")
+	fs.StringVar(&cfg.FontNormal, "font-normal", "", "path to the regular TTF font")
+	fs.StringVar(&cfg.FontItalic, "font-italic", "", "path to the italic TTF font")
+	fs.StringVar(&cfg.FontBold, "font-bold", "", "path to the bold TTF font")
+	fs.StringVar(&cfg.FontBoldItalic, "font-bold-italic", "", "path to the bold italic TTF font")
+	return layout
+}
+
+func (c *cli) render(command string, args []string) int {
+	fs := c.flagSet(command)
+	cfg := builder.Config{}
+	out := fs.String("out", "-", `output PDF path ("-" for stdout)`)
+	layout := configFlags(fs, &cfg)
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintf(c.stderr, "bizdocgen %s: expected exactly one input file (or - for stdin)\n", command)
+		fs.Usage()
+		return exitUsage
+	}
+	input := fs.Arg(0)
+
+	var buf []byte
+	var err error
+	switch command {
+	case "invoice":
+		cfg.InvoiceLayout = *layout
+		buf, err = c.renderInvoice(cfg, input)
+	case "statement":
+		cfg.SettlementStatementLayout = *layout
+		buf, err = c.renderStatement(cfg, input)
+	case "creditnote":
+		cfg.CreditNoteLayout = *layout
+		buf, err = c.renderCreditNote(cfg, input)
+	case "quote":
+		cfg.QuoteLayout = *layout
+		buf, err = c.renderQuote(cfg, input)
+	case "receipt":
+		buf, err = c.renderReceipt(cfg, input)
+	case "purchaseorder":
+		cfg.PurchaseOrderLayout = *layout
+		buf, err = c.renderPurchaseOrder(cfg, input)
+	case "deliverynote":
+		cfg.DeliveryNoteLayout = *layout
+		buf, err = c.renderDeliveryNote(cfg, input)
+	}
+	if err != nil {
+		return c.fail(err)
+	}
+
+	if err := c.writeOutput(*out, buf); err != nil {
+		return c.fail(err)
+	}
+	return exitOK
+}
+
+func (c *cli) renderInvoice(cfg builder.Config, input string) ([]byte, error) {
+	params := &core.InvoiceParams{}
+	if err := c.load(input, params); err != nil {
+		return nil, err
+	}
+	params.CompanySeal = resolveRelativeToInput(input, params.CompanySeal)
+	bd, err := builder.NewInvoiceBuilder(cfg, params)
+	if err != nil {
+		return nil, err
+	}
+	return bd.GenerateInvoice()
+}
+
+func (c *cli) renderStatement(cfg builder.Config, input string) ([]byte, error) {
+	params := &core.SettlementStatementParams{}
+	if err := c.load(input, params); err != nil {
+		return nil, err
+	}
+	params.CompanySeal = resolveRelativeToInput(input, params.CompanySeal)
+	bd, err := builder.NewSettlementStatementBuilder(cfg, params)
+	if err != nil {
+		return nil, err
+	}
+	return bd.GenerateSettlementStatement()
+}
+
+func (c *cli) renderCreditNote(cfg builder.Config, input string) ([]byte, error) {
+	params := &core.CreditNoteParams{}
+	if err := c.load(input, params); err != nil {
+		return nil, err
+	}
+	params.CompanySeal = resolveRelativeToInput(input, params.CompanySeal)
+	bd, err := builder.NewCreditNoteBuilder(cfg, params)
+	if err != nil {
+		return nil, err
+	}
+	return bd.GenerateCreditNote()
+}
+
+func (c *cli) renderQuote(cfg builder.Config, input string) ([]byte, error) {
+	params := &core.QuoteParams{}
+	if err := c.load(input, params); err != nil {
+		return nil, err
+	}
+	params.CompanySeal = resolveRelativeToInput(input, params.CompanySeal)
+	bd, err := builder.NewQuoteBuilder(cfg, params)
+	if err != nil {
+		return nil, err
+	}
+	return bd.GenerateQuote()
+}
+
+func (c *cli) renderReceipt(cfg builder.Config, input string) ([]byte, error) {
+	params := &core.InvoiceParams{}
+	if err := c.load(input, params); err != nil {
+		return nil, err
+	}
+	params.CompanySeal = resolveRelativeToInput(input, params.CompanySeal)
+	bd, err := builder.NewReceiptBuilder(cfg, params)
+	if err != nil {
+		return nil, err
+	}
+	return bd.GenerateReceipt()
+}
+
+func (c *cli) renderPurchaseOrder(cfg builder.Config, input string) ([]byte, error) {
+	params := &core.PurchaseOrderParams{}
+	if err := c.load(input, params); err != nil {
+		return nil, err
+	}
+	params.CompanySeal = resolveRelativeToInput(input, params.CompanySeal)
+	bd, err := builder.NewPurchaseOrderBuilder(cfg, params)
+	if err != nil {
+		return nil, err
+	}
+	return bd.GeneratePurchaseOrder()
+}
+
+func (c *cli) renderDeliveryNote(cfg builder.Config, input string) ([]byte, error) {
+	params := &core.DeliveryNoteParams{}
+	if err := c.load(input, params); err != nil {
+		return nil, err
+	}
+	params.CompanySeal = resolveRelativeToInput(input, params.CompanySeal)
+	bd, err := builder.NewDeliveryNoteBuilder(cfg, params)
+	if err != nil {
+		return nil, err
+	}
+	return bd.GenerateDeliveryNote()
+}
+
+func (c *cli) quoteToInvoice(args []string) int {
+	fs := c.flagSet("quote-to-invoice")
+	out := fs.String("out", "-", `output JSON path ("-" for stdout)`)
+	id := fs.String("id", "", "invoice ID (required)")
+	date := fs.String("date", time.Now().Format(time.DateOnly), "invoice date, YYYY-MM-DD")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *id == "" || fs.NArg() != 1 {
+		fmt.Fprintln(c.stderr, "bizdocgen quote-to-invoice: expected -id and exactly one input file (or - for stdin)")
+		fs.Usage()
+		return exitUsage
+	}
+	invoiceDate, err := time.Parse(time.DateOnly, *date)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "bizdocgen quote-to-invoice: invalid -date %q, want YYYY-MM-DD\n", *date)
+		return exitUsage
+	}
+
+	quote := &core.QuoteParams{}
+	if err := c.load(fs.Arg(0), quote); err != nil {
+		return c.fail(err)
+	}
+	params, err := quote.ToInvoice(*id, invoiceDate)
+	if err != nil {
+		return c.fail(err)
+	}
+	params.CompanySeal = resolveRelativeToInput(fs.Arg(0), params.CompanySeal)
+	buf, err := json.MarshalIndent(params, "", "  ")
+	if err != nil {
+		return c.fail(err)
+	}
+	if err := c.writeOutput(*out, append(buf, '\n')); err != nil {
+		return c.fail(err)
+	}
+	return exitOK
+}
+
+func (c *cli) ubl(args []string) int {
+	fs := c.flagSet("ubl")
+	cfg := builder.Config{}
+	out := fs.String("out", "-", `output XML path ("-" for stdout)`)
+	fs.BoolVar(&cfg.ValidateParams, "strict", false, "refuse params that fail validation")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(c.stderr, "bizdocgen ubl: expected exactly one input file (or - for stdin)")
+		fs.Usage()
+		return exitUsage
+	}
+
+	params := &core.InvoiceParams{}
+	if err := c.load(fs.Arg(0), params); err != nil {
+		return c.fail(err)
+	}
+	bd, err := builder.NewInvoiceBuilder(cfg, params)
+	if err != nil {
+		return c.fail(err)
+	}
+	buf, err := bd.GenerateInvoiceUBL()
+	if err != nil {
+		return c.fail(err)
+	}
+	if err := c.writeOutput(*out, buf); err != nil {
+		return c.fail(err)
+	}
+	return exitOK
+}
+
+func (c *cli) validate(args []string) int {
+	fs := c.flagSet("validate")
+	kind := fs.String("kind", "invoice", "params kind: invoice, statement, creditnote, quote, purchaseorder or deliverynote")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(c.stderr, "bizdocgen validate: expected at least one input file (or - for stdin)")
+		fs.Usage()
+		return exitUsage
+	}
+
+	code := exitOK
+	for _, input := range fs.Args() {
+		var params document
+		switch *kind {
+		case "invoice":
+			params = &core.InvoiceParams{}
+		case "statement":
+			params = &core.SettlementStatementParams{}
+		case "creditnote":
+			params = &core.CreditNoteParams{}
+		case "quote":
+			params = &core.QuoteParams{}
+		case "purchaseorder":
+			params = &core.PurchaseOrderParams{}
+		case "deliverynote":
+			params = &core.DeliveryNoteParams{}
+		default:
+			fmt.Fprintf(c.stderr, "bizdocgen validate: unknown kind %q\n", *kind)
+			return exitUsage
+		}
+
+		if err := c.load(input, params); err != nil {
+			fmt.Fprintf(c.stderr, "%v\n", err)
+			code = exitError
+			continue
+		}
+		err := params.Validate()
+		var validationErrs core.ValidationErrors
+		switch {
+		case err == nil:
+			fmt.Fprintf(c.stdout, "%s: ok\n", displayName(input))
+		case errors.As(err, &validationErrs):
+			for _, fieldErr := range validationErrs {
+				fmt.Fprintf(c.stderr, "%s: %s\n", displayName(input), fieldErr)
+			}
+			code = exitError
+		default:
+			fmt.Fprintf(c.stderr, "%s: %v\n", displayName(input), err)
+			code = exitError
+		}
+	}
+	return code
+}
+
+func (c *cli) batch(args []string) int {
+	fs := c.flagSet("batch")
+	cfg := builder.Config{}
+	outDir := fs.String("out-dir", "", "directory receiving <invoice id>.pdf files (required)")
+	workers := fs.Int("workers", 0, "documents rendered concurrently (default: number of CPUs)")
+	layout := configFlags(fs, &cfg)
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *outDir == "" || fs.NArg() == 0 {
+		fmt.Fprintln(c.stderr, "bizdocgen batch: expected -out-dir and at least one directory, glob or file")
+		fs.Usage()
+		return exitUsage
+	}
+	cfg.InvoiceLayout = *layout
+
+	if err := os.MkdirAll(*outDir, 0o777); err != nil {
+		return c.fail(err)
+	}
+	jobs, err := builder.LoadInvoiceBatchJobs(fs.Args()...)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	failed := 0
+	results := builder.GenerateInvoiceBatch(context.Background(), cfg, jobs, builder.BatchOptions{
+		Workers:   *workers,
+		OutputDir: *outDir,
+	})
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			fmt.Fprintf(c.stderr, "FAIL %s: %v\n", result.Source, result.Err)
+			continue
+		}
+		fmt.Fprintf(c.stdout, "ok   %s -> %s\n", result.Source, filepath.Join(*outDir, result.Filename))
+	}
+	if failed > 0 {
+		fmt.Fprintf(c.stderr, "bizdocgen batch: %d of %d documents failed\n", failed, len(results))
+		return exitError
+	}
+	return exitOK
+}
+
+func (c *cli) layouts(args []string) int {
+	fs := c.flagSet("layouts")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	for _, name := range builder.BuiltinLayoutNames() {
+		fmt.Fprintln(c.stdout, name)
+	}
+	return exitOK
+}
+
+func (c *cli) load(input string, params document) error {
+	if input == "-" {
+		return params.LoadFromReader(c.stdin)
+	}
+	return params.Load(input)
+}
+
+func (c *cli) writeOutput(out string, buf []byte) error {
+	if out == "" || out == "-" {
+		_, err := c.stdout.Write(buf)
+		return err
+	}
+	return os.WriteFile(out, buf, 0o666)
+}
+
+func displayName(input string) string {
+	if input == "-" {
+		return "<stdin>"
+	}
+	return input
+}
+
+// resolveRelativeToInput resolves paths inside params (e.g. company_seal) against the input file.
+// Params read from stdin resolve against the working directory.
+func resolveRelativeToInput(inputPath, maybeRelativePath string) string {
+	if maybeRelativePath == "" || inputPath == "-" {
+		return maybeRelativePath
+	}
+	if filepath.IsAbs(maybeRelativePath) {
+		return maybeRelativePath
+	}
+	return filepath.Clean(filepath.Join(filepath.Dir(inputPath), maybeRelativePath))
+}
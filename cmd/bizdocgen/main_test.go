@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/quailyquaily/bizdocgen/core"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestRunInvoiceToStdout(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"invoice", "-layout", "modern", "../../samples/invoice-1.yaml"}, nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr.String())
+	}
+	if !bytes.HasPrefix(stdout.Bytes(), []byte("%PDF")) {
+		t.Fatalf("stdout is not a PDF: %q", stdout.Bytes()[:min(16, stdout.Len())])
+	}
+}
+
+// goFontFlags returns the font flags for the Go fonts, which Factur-X output needs embedded.
+func goFontFlags(t *testing.T) []string {
+	t.Helper()
+	dir := t.TempDir()
+	normal, bold := filepath.Join(dir, "Go-Regular.ttf"), filepath.Join(dir, "Go-Bold.ttf")
+	if err := os.WriteFile(normal, goregular.TTF, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(bold, gobold.TTF, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return []string{"-font-name", "go", "-font-normal", normal, "-font-bold", bold}
+}
+
+func TestRunInvoiceFacturX(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	fonts := goFontFlags(t)
+	code := run(append([]string{"invoice", "-facturx", "en16931"}, append(fonts, "../../samples/invoice-5.yaml")...), nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr.String())
+	}
+	if !bytes.Contains(stdout.Bytes(), []byte("/AFRelationship /Alternative")) {
+		t.Fatal("output has no Factur-X attachment")
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run(append([]string{"invoice", "-facturx", "en16931"}, append(fonts, "../../samples/invoice-1.yaml")...), nil, &stdout, &stderr)
+	if code != exitError || !strings.Contains(stderr.String(), "  [BR-9] ") {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr.String())
+	}
+}
+
+func TestRunUBL(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"ubl", "../../samples/invoice-5.yaml"}, nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr.String())
+	}
+	if !bytes.Contains(stdout.Bytes(), []byte("<cbc:ID>20240415-EU</cbc:ID>")) {
+		t.Fatalf("stdout is not the UBL invoice: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"ubl", "../../samples/invoice-1.yaml"}, nil, &stdout, &stderr)
+	if code != exitError || !strings.Contains(stderr.String(), "  [PEPPOL-EN16931-R003] ") {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr.String())
+	}
+}
+
+func TestRunStatementFromStdinToFile(t *testing.T) {
+	input, err := os.ReadFile("../../samples/settlementstatement-1.yaml")
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	out := filepath.Join(t.TempDir(), "statement.pdf")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"statement", "-out", out, "-"}, bytes.NewReader(input), &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr.String())
+	}
+	buf, err := os.ReadFile(out)
+	if err != nil || !bytes.HasPrefix(buf, []byte("%PDF")) {
+		t.Fatalf("output is not a PDF: %v", err)
+	}
+}
+
+func TestRunCreditNote(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "creditnote.pdf")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"creditnote", "-layout", "compact", "-out", out, "../../samples/creditnote-1.yaml"}, nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr.String())
+	}
+	buf, err := os.ReadFile(out)
+	if err != nil || !bytes.HasPrefix(buf, []byte("%PDF")) {
+		t.Fatalf("output is not a PDF: %v", err)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"validate", "-kind", "creditnote", "../../samples/creditnote-1.yaml"}, nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr.String())
+	}
+}
+
+func TestRunPurchaseOrderAndDeliveryNote(t *testing.T) {
+	for _, tc := range []struct{ kind, sample string }{
+		{"purchaseorder", "../../samples/purchaseorder-1.yaml"},
+		{"deliverynote", "../../samples/deliverynote-1.yaml"},
+	} {
+		out := filepath.Join(t.TempDir(), tc.kind+".pdf")
+
+		var stdout, stderr bytes.Buffer
+		code := run([]string{tc.kind, "-out", out, tc.sample}, nil, &stdout, &stderr)
+		if code != exitOK {
+			t.Fatalf("%s: exit code = %d, stderr = %s", tc.kind, code, stderr.String())
+		}
+		buf, err := os.ReadFile(out)
+		if err != nil || !bytes.HasPrefix(buf, []byte("%PDF")) {
+			t.Fatalf("%s: output is not a PDF: %v", tc.kind, err)
+		}
+
+		code = run([]string{"validate", "-kind", tc.kind, tc.sample}, nil, &stdout, &stderr)
+		if code != exitOK {
+			t.Fatalf("validate %s: exit code = %d, stderr = %s", tc.kind, code, stderr.String())
+		}
+	}
+}
+
+func TestRunQuoteToInvoice(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"quote-to-invoice", "-id", "20240401-01", "-date", "2024-04-01", "../../samples/quote-1.yaml"}, nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr.String())
+	}
+	params := &core.InvoiceParams{}
+	if err := params.LoadFromBytes(stdout.Bytes()); err != nil {
+		t.Fatalf("LoadFromBytes: %v", err)
+	}
+	if params.ID != "20240401-01" || params.BuyerReference != "Q-20240301-01" {
+		t.Fatalf("invoice id %q ref %q", params.ID, params.BuyerReference)
+	}
+
+	out := filepath.Join(t.TempDir(), "quote.pdf")
+	stderr.Reset()
+	code = run([]string{"quote", "-out", out, "../../samples/quote-1.yaml"}, nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr.String())
+	}
+
+	code = run([]string{"quote-to-invoice", "-date", "2024-04-01", "../../samples/quote-1.yaml"}, nil, &stdout, &stderr)
+	if code != exitUsage {
+		t.Fatalf("exit code without -id = %d, want %d", code, exitUsage)
+	}
+}
+
+func TestRunReceipt(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"receipt", "-lang", "ja", "../../samples/invoice-2.yaml"}, nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr.String())
+	}
+	if !bytes.HasPrefix(stdout.Bytes(), []byte("%PDF")) {
+		t.Fatal("output is not a PDF")
+	}
+
+	stderr.Reset()
+	code = run([]string{"receipt", "../../samples/invoice-1.yaml"}, nil, &stdout, &stderr)
+	if code != exitError || !strings.Contains(stderr.String(), "no payment result") {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr.String())
+	}
+}
+
+func TestRunValidateReportsFieldErrors(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	input := strings.NewReader("id: \"\"\ncurrency: XYZ\n")
+	code := run([]string{"validate", "-"}, input, &stdout, &stderr)
+	if code != exitError {
+		t.Fatalf("exit code = %d, want %d", code, exitError)
+	}
+	for _, want := range []string{"<stdin>: id: is required", "<stdin>: currency: unknown currency"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Fatalf("stderr %q does not contain %q", stderr.String(), want)
+		}
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"validate", "-kind", "statement", "../../samples/settlementstatement-1.yaml"}, nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr.String())
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		args []string
+		code int
+	}{
+		{args: nil, code: exitUsage},
+		{args: []string{"bogus"}, code: exitUsage},
+		{args: []string{"invoice"}, code: exitUsage},
+		{args: []string{"invoice", "does-not-exist.yaml"}, code: exitError},
+		{args: []string{"invoice", "-rates", "does-not-exist.csv", "../../samples/invoice-5.yaml"}, code: exitUsage},
+		{args: []string{"invoice", "-rates", "../../samples/rates.csv", "-out", os.DevNull, "../../samples/invoice-5.yaml"}, code: exitOK},
+		{args: []string{"invoice", "-summary-tolerance", "abc", "../../samples/invoice-5.yaml"}, code: exitUsage},
+		{args: []string{"invoice", "-summary-check", "fail", "-out", os.DevNull, "../../samples/invoice-5.yaml"}, code: exitOK},
+		{args: []string{"layouts"}, code: exitOK},
+	}
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		if code := run(tt.args, nil, &stdout, &stderr); code != tt.code {
+			t.Errorf("run(%q) = %d, want %d (stderr: %s)", tt.args, code, tt.code, stderr.String())
+		}
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	outDir := filepath.Join(t.TempDir(), "out")
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"batch", "-workers", "2", "-out-dir", outDir, "../../samples/invoice-[12].yaml"}, nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr.String())
+	}
+	// Both samples share an ID, so the second one gets a suffix.
+	for _, name := range []string{"20240210-SAMPLE.pdf", "20240210-SAMPLE-2.pdf"} {
+		if _, err := os.Stat(filepath.Join(outDir, name)); err != nil {
+			t.Fatalf("missing %s: %v (stdout: %s)", name, err, stdout.String())
+		}
+	}
+
+	code = run([]string{"batch", "-out-dir", outDir, "../../samples/nothing-*.yaml"}, nil, &stdout, &stderr)
+	if code != exitError {
+		t.Fatalf("exit code = %d, want %d", code, exitError)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/quailyquaily/bizdocgen/core"
+)
+
+func main() {
+	repoRoot, err := os.Getwd()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	outDir := filepath.Join(repoRoot, "schema")
+	if err := os.MkdirAll(outDir, 0o777); err != nil {
+		log.Fatal(err)
+	}
+
+	schemas := []struct {
+		filename string
+		build    func() ([]byte, error)
+	}{
+		{filename: "invoice.schema.json", build: core.InvoiceParamsJSONSchema},
+		{filename: "settlementstatement.schema.json", build: core.SettlementStatementParamsJSONSchema},
+		{filename: "creditnote.schema.json", build: core.CreditNoteParamsJSONSchema},
+		{filename: "quote.schema.json", build: core.QuoteParamsJSONSchema},
+		{filename: "purchaseorder.schema.json", build: core.PurchaseOrderParamsJSONSchema},
+		{filename: "deliverynote.schema.json", build: core.DeliveryNoteParamsJSONSchema},
+	}
+	for _, schema := range schemas {
+		data, err := schema.build()
+		if err != nil {
+			log.Fatal(err)
+		}
+		out := filepath.Join(outDir, schema.filename)
+		if err := os.WriteFile(out, data, 0o666); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("wrote %s", out)
+	}
+}
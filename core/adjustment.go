@@ -0,0 +1,64 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Adjustment kinds.
+const (
+	AdjustmentDiscount  = "discount"
+	AdjustmentSurcharge = "surcharge"
+)
+
+var hundred = decimal.NewFromInt(100)
+
+// InvoiceAdjustment is a discount or surcharge such as a volume discount or a shipping fee. On a
+// detail item it changes the line's net amount; on the summary it applies to the net total of
+// the detail items, before tax.
+type InvoiceAdjustment struct {
+	// Kind is "discount" or "surcharge".
+	Kind  string `yaml:"kind" json:"kind" toml:"kind"`
+	Title string `yaml:"title" json:"title" toml:"title"`
+	// Amount is a fixed amount. Percent is a percentage (5 for 5%) of the amount after the
+	// adjustments listed before it. Exactly one of them is set.
+	Amount  decimal.Decimal `yaml:"amount" json:"amount" toml:"amount"`
+	Percent decimal.Decimal `yaml:"percent" json:"percent" toml:"percent"`
+	// Untaxed summary adjustments are added to the total after tax, e.g. a fee passed on at cost.
+	// Line adjustments are always taxed with their line.
+	Untaxed bool `yaml:"untaxed" json:"untaxed" toml:"untaxed"`
+	// TaxCategory/TaxRate tax a summary adjustment in its own tax group. Without either it is
+	// taxed at Summary.TaxRate.
+	TaxCategory string          `yaml:"tax_category" json:"tax_category" toml:"tax_category"`
+	TaxRate     decimal.Decimal `yaml:"tax_rate" json:"tax_rate" toml:"tax_rate"`
+}
+
+// IsDiscount reports whether the adjustment lowers the amount.
+func (adj InvoiceAdjustment) IsDiscount() bool {
+	return strings.ToLower(strings.TrimSpace(adj.Kind)) == AdjustmentDiscount
+}
+
+// Apply returns the signed amount the adjustment adds to base, rounded with r: negative for
+// discounts, positive for surcharges.
+func (adj InvoiceAdjustment) Apply(base decimal.Decimal, r Rounding) decimal.Decimal {
+	amount := adj.Amount
+	if amount.IsZero() {
+		amount = base.Mul(adj.Percent).Div(hundred)
+	}
+	amount = r.Round(amount)
+	if adj.IsDiscount() {
+		return amount.Neg()
+	}
+	return amount
+}
+
+// ApplyAdjustments applies adjustments to base in order and returns the signed amount of each.
+func ApplyAdjustments(base decimal.Decimal, adjustments []InvoiceAdjustment, r Rounding) []decimal.Decimal {
+	amounts := make([]decimal.Decimal, len(adjustments))
+	for ix, adj := range adjustments {
+		amounts[ix] = adj.Apply(base, r)
+		base = base.Add(amounts[ix])
+	}
+	return amounts
+}
@@ -0,0 +1,22 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var jpRegistrationNumberPattern = regexp.MustCompile(`^T[0-9]{13}$`)
+
+// ValidateJPRegistrationNumber checks a Japanese Qualified Invoice issuer registration number
+// (適格請求書発行事業者登録番号): "T" followed by 13 digits.
+func ValidateJPRegistrationNumber(number string) error {
+	number = strings.TrimSpace(number)
+	if number == "" {
+		return fmt.Errorf("registration number is empty")
+	}
+	if !jpRegistrationNumberPattern.MatchString(number) {
+		return fmt.Errorf("registration number %q must be \"T\" followed by 13 digits", number)
+	}
+	return nil
+}
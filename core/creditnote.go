@@ -0,0 +1,80 @@
+package core
+
+import (
+	"io"
+	"time"
+)
+
+type (
+	CreditNoteSummary       = InvoiceSummary
+	CreditNoteDetailItem    = InvoiceDetailItem
+	CreditNotePaymentResult = InvoicePaymentResult
+	CreditNoteDoc           = InvoiceDoc
+)
+
+// InvoiceReference identifies a previously issued invoice.
+type InvoiceReference struct {
+	ID   string    `yaml:"id" json:"id" toml:"id"`
+	Date time.Time `yaml:"date" json:"date" toml:"date" time_format:"2006/01/02"`
+}
+
+type CreditNotePayment struct {
+	// CreditNotePaymentResult records the refund, if the credit is paid out rather than offset.
+	CreditNotePaymentResult `yaml:"result,omitempty" json:"result,omitempty" toml:"result,omitempty"`
+}
+
+// CreditNoteParams describes a credit note: a document that reverses all or part of an invoice.
+// Amounts are entered as positive values, i.e. the amounts being credited; they are rendered negative.
+type CreditNoteParams struct {
+	ID              string    `yaml:"id" json:"id" toml:"id"`
+	TaxNumber       string    `yaml:"tax_number" json:"tax_number" toml:"tax_number"`
+	Date            time.Time `yaml:"date" json:"date" toml:"date" time_format:"2006/01/02"`
+	Currency        string    `yaml:"currency" json:"currency" toml:"currency"`
+	CompanyName     string    `yaml:"company_name" json:"company_name" toml:"company_name"`
+	CompanyAddr     string    `yaml:"company_address" json:"company_address" toml:"company_address"`
+	CompanyEmail    string    `yaml:"company_email" json:"company_email" toml:"company_email"`
+	CompanySeal     string    `yaml:"company_seal" json:"company_seal" toml:"company_seal"`
+	CompanyCountry  string    `yaml:"company_country" json:"company_country" toml:"company_country"`
+	CompanyEndpoint string    `yaml:"company_endpoint" json:"company_endpoint" toml:"company_endpoint"`
+
+	BillToCompany   string `yaml:"bill_to_company" json:"bill_to_company" toml:"bill_to_company"`
+	BillToAddress   string `yaml:"bill_to_address" json:"bill_to_address" toml:"bill_to_address"`
+	BillToCountry   string `yaml:"bill_to_country" json:"bill_to_country" toml:"bill_to_country"`
+	BillToEndpoint  string `yaml:"bill_to_endpoint" json:"bill_to_endpoint" toml:"bill_to_endpoint"`
+	BillToTaxNumber string `yaml:"bill_to_tax_number" json:"bill_to_tax_number" toml:"bill_to_tax_number"`
+	BuyerReference  string `yaml:"buyer_reference" json:"buyer_reference" toml:"buyer_reference"`
+
+	// OriginalInvoice is the invoice being credited.
+	OriginalInvoice InvoiceReference `yaml:"original_invoice" json:"original_invoice" toml:"original_invoice"`
+	// Reason explains the credit, e.g. "Partial refund for cancelled seats".
+	Reason string `yaml:"reason" json:"reason" toml:"reason"`
+
+	// Summary
+	Summary CreditNoteSummary `yaml:"summary" json:"summary" toml:"summary"`
+
+	// Details
+	DetailItems []CreditNoteDetailItem `yaml:"detail_items" json:"detail_items" toml:"detail_items"`
+
+	// Refund
+	Payment CreditNotePayment `yaml:"payment" json:"payment" toml:"payment"`
+
+	// Doc related info
+	Doc CreditNoteDoc `yaml:"doc" json:"doc" toml:"doc"`
+}
+
+// Load reads params from a YAML, JSON or TOML file; see DetectFormat.
+// Errors are *LoadError values carrying the filename and position.
+func (params *CreditNoteParams) Load(filename string) error {
+	return loadFile(filename, params)
+}
+
+// LoadFromReader reads params from a stream, e.g. an HTTP body or an object storage reader.
+// The format is detected from the content.
+func (params *CreditNoteParams) LoadFromReader(r io.Reader) error {
+	return loadReader(r, params)
+}
+
+// LoadFromBytes reads params from an in-memory document, detecting its format from the content.
+func (params *CreditNoteParams) LoadFromBytes(data []byte) error {
+	return decode("", data, params)
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"io"
+	"time"
+)
+
+type (
+	DeliveryNoteSummary    = InvoiceSummary
+	DeliveryNoteDetailItem = InvoiceDetailItem
+	DeliveryNoteDoc        = InvoiceDoc
+)
+
+// DeliveryNoteParams describes a delivery note (納品書) accompanying the goods of an order.
+// With HidePrices the document lists quantities only, and currency and amounts may be left out.
+type DeliveryNoteParams struct {
+	ID              string    `yaml:"id" json:"id" toml:"id"`
+	TaxNumber       string    `yaml:"tax_number" json:"tax_number" toml:"tax_number"`
+	Date            time.Time `yaml:"date" json:"date" toml:"date" time_format:"2006/01/02"`
+	Currency        string    `yaml:"currency" json:"currency" toml:"currency"`
+	CompanyName     string    `yaml:"company_name" json:"company_name" toml:"company_name"`
+	CompanyAddr     string    `yaml:"company_address" json:"company_address" toml:"company_address"`
+	CompanyEmail    string    `yaml:"company_email" json:"company_email" toml:"company_email"`
+	CompanySeal     string    `yaml:"company_seal" json:"company_seal" toml:"company_seal"`
+	CompanyCountry  string    `yaml:"company_country" json:"company_country" toml:"company_country"`
+	CompanyEndpoint string    `yaml:"company_endpoint" json:"company_endpoint" toml:"company_endpoint"`
+
+	DeliverToCompany string `yaml:"deliver_to_company" json:"deliver_to_company" toml:"deliver_to_company"`
+	DeliverToAddress string `yaml:"deliver_to_address" json:"deliver_to_address" toml:"deliver_to_address"`
+	DeliverToCountry string `yaml:"deliver_to_country" json:"deliver_to_country" toml:"deliver_to_country"`
+
+	// OrderReference is the customer's order (purchase order) number the delivery fulfils.
+	OrderReference string `yaml:"order_reference" json:"order_reference" toml:"order_reference"`
+
+	// HidePrices omits unit prices, amounts and the summary from the document.
+	HidePrices bool `yaml:"hide_prices" json:"hide_prices" toml:"hide_prices"`
+
+	// Summary
+	Summary DeliveryNoteSummary `yaml:"summary" json:"summary" toml:"summary"`
+
+	// Details
+	DetailItems []DeliveryNoteDetailItem `yaml:"detail_items" json:"detail_items" toml:"detail_items"`
+
+	// Doc related info
+	Doc DeliveryNoteDoc `yaml:"doc" json:"doc" toml:"doc"`
+}
+
+// Load reads params from a YAML, JSON or TOML file; see DetectFormat.
+// Errors are *LoadError values carrying the filename and position.
+func (params *DeliveryNoteParams) Load(filename string) error {
+	return loadFile(filename, params)
+}
+
+// LoadFromReader reads params from a stream, e.g. an HTTP body or an object storage reader.
+// The format is detected from the content.
+func (params *DeliveryNoteParams) LoadFromReader(r io.Reader) error {
+	return loadReader(r, params)
+}
+
+// LoadFromBytes reads params from an in-memory document, detecting its format from the content.
+func (params *DeliveryNoteParams) LoadFromBytes(data []byte) error {
+	return decode("", data, params)
+}
@@ -0,0 +1,171 @@
+package core
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrNoExchangeRate is returned, wrapped, by providers without a rate for a currency pair.
+var ErrNoExchangeRate = errors.New("no exchange rate")
+
+// exchangeRateDecimals bounds the precision of inverted rates.
+const exchangeRateDecimals = 10
+
+type (
+	// ExchangeRate is the price of one unit of Base in Quote, e.g. 1 USD = 151.25 JPY.
+	ExchangeRate struct {
+		Base  string          `yaml:"base" json:"base" toml:"base"`
+		Quote string          `yaml:"quote" json:"quote" toml:"quote"`
+		Rate  decimal.Decimal `yaml:"rate" json:"rate" toml:"rate"`
+		// Date is the day the rate was published for. A zero date applies to any day. YAML and CSV
+		// take YYYY-MM-DD, TOML an unquoted date, JSON an RFC 3339 time such as 2024-04-30T00:00:00Z.
+		Date time.Time `yaml:"date" json:"date" toml:"date"`
+		// Source names where the rate comes from, e.g. "ECB reference rate".
+		Source string `yaml:"source" json:"source" toml:"source"`
+	}
+
+	// ExchangeRateProvider looks up exchange rates for the reference amounts in a quote currency.
+	ExchangeRateProvider interface {
+		// ExchangeRate returns the rate of base in quote applying on date, or an error wrapping
+		// ErrNoExchangeRate.
+		ExchangeRate(base, quote string, date time.Time) (ExchangeRate, error)
+	}
+
+	// ExchangeRateFunc adapts a function, e.g. a client of a rate service, to ExchangeRateProvider.
+	ExchangeRateFunc func(base, quote string, date time.Time) (ExchangeRate, error)
+
+	// ExchangeRateTable is a static list of rates, loaded from a YAML, JSON, TOML or CSV file.
+	ExchangeRateTable struct {
+		// Source is the source of the rates that do not name their own.
+		Source string         `yaml:"source" json:"source" toml:"source"`
+		Rates  []ExchangeRate `yaml:"rates" json:"rates" toml:"rates"`
+	}
+)
+
+func (f ExchangeRateFunc) ExchangeRate(base, quote string, date time.Time) (ExchangeRate, error) {
+	return f(base, quote, date)
+}
+
+// ExchangeRate returns the latest rate of the pair published on or before date, the latest one
+// when date is zero. Without a rate for the pair, the inverse of the reverse pair is used.
+func (t *ExchangeRateTable) ExchangeRate(base, quote string, date time.Time) (ExchangeRate, error) {
+	base, quote = CurrencyCode(base), CurrencyCode(quote)
+	if rate, ok := t.lookup(base, quote, date); ok {
+		return rate, nil
+	}
+	if rate, ok := t.lookup(quote, base, date); ok && !rate.Rate.IsZero() {
+		rate.Base, rate.Quote = base, quote
+		rate.Rate = decimal.NewFromInt(1).DivRound(rate.Rate, exchangeRateDecimals)
+		return rate, nil
+	}
+	if date.IsZero() {
+		return ExchangeRate{}, fmt.Errorf("%w for %s/%s", ErrNoExchangeRate, base, quote)
+	}
+	return ExchangeRate{}, fmt.Errorf("%w for %s/%s on %s", ErrNoExchangeRate, base, quote, date.Format("2006-01-02"))
+}
+
+func (t *ExchangeRateTable) lookup(base, quote string, date time.Time) (ExchangeRate, bool) {
+	var found ExchangeRate
+	ok := false
+	for _, rate := range t.Rates {
+		if CurrencyCode(rate.Base) != base || CurrencyCode(rate.Quote) != quote {
+			continue
+		}
+		if !date.IsZero() && !rate.Date.IsZero() && rate.Date.After(date) {
+			continue
+		}
+		if !ok || rate.Date.After(found.Date) {
+			found, ok = rate, true
+		}
+	}
+	if found.Source == "" {
+		found.Source = t.Source
+	}
+	found.Base, found.Quote = base, quote
+	return found, ok
+}
+
+// Load reads the table from a file: CSV for the .csv extension, otherwise YAML, JSON or TOML
+// as for params; see DetectFormat.
+func (t *ExchangeRateTable) Load(filename string) error {
+	if !strings.EqualFold(filepath.Ext(filename), ".csv") {
+		return loadFile(filename, t)
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return &LoadError{Filename: filename, Err: err}
+	}
+	defer f.Close()
+	if err := t.LoadCSV(f); err != nil {
+		var loadErr *LoadError
+		if errors.As(err, &loadErr) {
+			loadErr.Filename = filename
+		}
+		return err
+	}
+	return nil
+}
+
+// LoadCSV reads rates from CSV with a header row naming the columns base, quote and rate, and
+// optionally date (YYYY-MM-DD) and source, e.g.
+//
+//	base,quote,rate,date,source
+//	USD,JPY,151.25,2024-04-30,MUFG TTM
+func (t *ExchangeRateTable) LoadCSV(r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		loadErr := &LoadError{Err: err}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			loadErr.Line, loadErr.Column = parseErr.Line, parseErr.Column
+		}
+		return loadErr
+	}
+	if len(records) == 0 {
+		return &LoadError{Err: fmt.Errorf("missing header row")}
+	}
+	columns := make(map[string]int)
+	for ix, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\xef\xbb\xbf")))] = ix
+	}
+	for _, name := range []string{"base", "quote", "rate"} {
+		if _, ok := columns[name]; !ok {
+			return &LoadError{Line: 1, Err: fmt.Errorf("missing %q column", name)}
+		}
+	}
+	field := func(record []string, name string) string {
+		if ix, ok := columns[name]; ok && ix < len(record) {
+			return strings.TrimSpace(record[ix])
+		}
+		return ""
+	}
+
+	for ix, record := range records[1:] {
+		line := ix + 2
+		rate := ExchangeRate{
+			Base:   field(record, "base"),
+			Quote:  field(record, "quote"),
+			Source: field(record, "source"),
+		}
+		if rate.Rate, err = decimal.NewFromString(field(record, "rate")); err != nil {
+			return &LoadError{Line: line, Err: fmt.Errorf("rate: %w", err)}
+		}
+		if date := field(record, "date"); date != "" {
+			if rate.Date, err = time.Parse("2006-01-02", date); err != nil {
+				return &LoadError{Line: line, Err: fmt.Errorf("date: %w", err)}
+			}
+		}
+		t.Rates = append(t.Rates, rate)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestExchangeRateTable(t *testing.T) {
+	table := &ExchangeRateTable{}
+	if err := table.Load("../samples/rates.csv"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	day := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+
+	tests := []struct {
+		base, quote string
+		date        time.Time
+		rate        string
+		source      string
+	}{
+		{base: "USD", quote: "JPY", date: day("2024-03-01"), rate: "149.85", source: "MUFG TTM"},
+		{base: "USD", quote: "円", date: day("2024-05-01"), rate: "151.25", source: "MUFG TTM"},
+		{base: "usd", quote: "JPY", rate: "151.25", source: "MUFG TTM"},
+		{base: "USD", quote: "EUR", date: day("2024-04-15"), rate: "0.9365049635", source: "ECB reference rate"},
+	}
+	for _, tt := range tests {
+		rate, err := table.ExchangeRate(tt.base, tt.quote, tt.date)
+		if err != nil {
+			t.Errorf("ExchangeRate(%s, %s): %v", tt.base, tt.quote, err)
+			continue
+		}
+		if rate.Rate.String() != tt.rate || rate.Source != tt.source || rate.Quote == "円" {
+			t.Errorf("ExchangeRate(%s, %s) = %+v, want %s from %s", tt.base, tt.quote, rate, tt.rate, tt.source)
+		}
+	}
+
+	for _, date := range []time.Time{day("2024-01-01"), {}} {
+		if _, err := table.ExchangeRate("GBP", "JPY", date); !errors.Is(err, ErrNoExchangeRate) {
+			t.Errorf("ExchangeRate(GBP, JPY, %s) error = %v, want ErrNoExchangeRate", date, err)
+		}
+	}
+	if _, err := table.ExchangeRate("USD", "JPY", day("2024-01-01")); !errors.Is(err, ErrNoExchangeRate) {
+		t.Errorf("ExchangeRate before the first rate: error = %v, want ErrNoExchangeRate", err)
+	}
+}
+
+func TestExchangeRateTableLoadDates(t *testing.T) {
+	for name, data := range map[string]string{
+		"rates.yaml": "source: Bank of Japan\nrates:\n  - base: USD\n    quote: JPY\n    rate: 150\n    date: 2024-04-30\n",
+		"rates.toml": "source = \"Bank of Japan\"\n[[rates]]\nbase = \"USD\"\nquote = \"JPY\"\nrate = \"150\"\ndate = 2024-04-30\n",
+		"rates.json": `{"source": "Bank of Japan", "rates": [{"base": "USD", "quote": "JPY", "rate": "150", "date": "2024-04-30T00:00:00Z"}]}`,
+	} {
+		filename := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(filename, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		table := &ExchangeRateTable{}
+		if err := table.Load(filename); err != nil {
+			t.Fatalf("Load(%s): %v", name, err)
+		}
+		rate, err := table.ExchangeRate("USD", "JPY", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+		if err != nil || !rate.Rate.Equal(decimal.NewFromInt(150)) || rate.Source != "Bank of Japan" || rate.Date.Format("2006-01-02") != "2024-04-30" {
+			t.Fatalf("%s: ExchangeRate = %+v, %v; want 150 of 2024-04-30 from the table source", name, rate, err)
+		}
+	}
+}
+
+func TestExchangeRateTableLoadCSVErrors(t *testing.T) {
+	tests := []struct {
+		csv  string
+		want string
+	}{
+		{csv: "", want: "missing header row"},
+		{csv: "base,rate\nUSD,1\n", want: `<input>:1: missing "quote" column`},
+		{csv: "base,quote,rate\nUSD,JPY,abc\n", want: "<input>:2: rate:"},
+		{csv: "base,quote,rate,date\nUSD,JPY,150,30/04/2024\n", want: "<input>:2: date:"},
+	}
+	for _, tt := range tests {
+		err := (&ExchangeRateTable{}).LoadCSV(strings.NewReader(tt.csv))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("LoadCSV(%q) error = %v, want %q", tt.csv, err, tt.want)
+		}
+	}
+}
+
+func TestExchangeRateFunc(t *testing.T) {
+	var provider ExchangeRateProvider = ExchangeRateFunc(func(base, quote string, date time.Time) (ExchangeRate, error) {
+		return ExchangeRate{Base: base, Quote: quote, Rate: decimal.NewFromInt(2), Date: date}, nil
+	})
+	date := time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)
+	if rate, err := provider.ExchangeRate("EUR", "USD", date); err != nil || !rate.Rate.Equal(decimal.NewFromInt(2)) || !rate.Date.Equal(date) {
+		t.Fatalf("ExchangeRate = %+v, %v", rate, err)
+	}
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		filename string
+		data     string
+		want     Format
+	}{
+		{filename: "a.json", data: "id: 1", want: FormatJSON},
+		{filename: "a.TOML", data: "{}", want: FormatTOML},
+		{filename: "a.yml", data: "{}", want: FormatYAML},
+		{data: "  \n{\"id\": \"1\"}", want: FormatJSON},
+		{data: "# comment\nid = \"1\"\n", want: FormatTOML},
+		{data: "[summary]\ntitle = \"x\"\n", want: FormatTOML},
+		{data: "id: \"1\"\nsummary:\n  title: x\n", want: FormatYAML},
+		{data: "- a\n- b\n", want: FormatYAML},
+	}
+	for _, tt := range tests {
+		if got := DetectFormat(tt.filename, []byte(tt.data)); got != tt.want {
+			t.Errorf("DetectFormat(%q, %q) = %q, want %q", tt.filename, tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestLoadJSONRoundTrip(t *testing.T) {
+	fromYAML := &InvoiceParams{}
+	if err := fromYAML.Load("../samples/invoice-2.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	data, err := json.Marshal(fromYAML)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	fromJSON := &InvoiceParams{}
+	if err := fromJSON.LoadFromBytes(data); err != nil {
+		t.Fatalf("LoadFromBytes: %v", err)
+	}
+	again, err := json.Marshal(fromJSON)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if !bytes.Equal(data, again) {
+		t.Fatalf("JSON round trip mismatch:\n%s\n%s", data, again)
+	}
+
+	if err := fromJSON.Load("../samples/invoice-1.json"); err != nil {
+		t.Fatalf("Load(json): %v", err)
+	}
+	if err := fromJSON.Validate(); err != nil {
+		t.Fatalf("Validate(json): %v", err)
+	}
+}
+
+func TestLoadTOML(t *testing.T) {
+	data := []byte(`id = "20240210-TOML"
+date = 2024-02-10
+currency = "USD"
+company_name = "ABC Inc"
+bill_to_company = "XYZ LLC"
+
+[summary]
+title = "Service"
+total_exclude_tax = 500000
+tax_rate = 0.1
+
+[[detail_items]]
+title = "Implementation"
+quantity = 40
+unit_price = "12500.50"
+
+[payment.instruction]
+disabled = true
+`)
+	params := &InvoiceParams{}
+	if err := params.LoadFromBytes(data); err != nil {
+		t.Fatalf("LoadFromBytes: %v", err)
+	}
+	if params.ID != "20240210-TOML" || params.Summary.TaxRate.String() != "0.1" ||
+		params.DetailItems[0].UnitPrice.String() != "12500.5" || !params.Payment.InvoicePaymentInstruction.Disabled {
+		t.Fatalf("unexpected params: %+v", params)
+	}
+}
+
+func TestLoadJSONReportsPosition(t *testing.T) {
+	err := (&InvoiceParams{}).LoadFromBytes([]byte("{\n  \"id\": \"1\",\n  \"detail_items\": 5\n}"))
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) || loadErr.Line != 3 {
+		t.Fatalf("LoadFromBytes() = %v, want *LoadError on line 3", err)
+	}
+}
+
+func TestPublishedJSONSchemaIsUpToDate(t *testing.T) {
+	schemas := map[string]func() ([]byte, error){
+		"../schema/invoice.schema.json":             InvoiceParamsJSONSchema,
+		"../schema/settlementstatement.schema.json": SettlementStatementParamsJSONSchema,
+		"../schema/creditnote.schema.json":          CreditNoteParamsJSONSchema,
+		"../schema/quote.schema.json":               QuoteParamsJSONSchema,
+		"../schema/purchaseorder.schema.json":       PurchaseOrderParamsJSONSchema,
+		"../schema/deliverynote.schema.json":        DeliveryNoteParamsJSONSchema,
+	}
+	for filename, build := range schemas {
+		want, err := build()
+		if err != nil {
+			t.Fatalf("%s: %v", filename, err)
+		}
+		got, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("%s is stale; run `go run ./cmd/generate-schema`", filename)
+		}
+	}
+}
@@ -1,126 +1,242 @@
 package core
 
 import (
-	"os"
+	"io"
 	"time"
 
 	"github.com/shopspring/decimal"
-	"github.com/sirupsen/logrus"
-	"gopkg.in/yaml.v2"
+)
+
+// Tax categories follow the UNCL5305 codes used by EN 16931 e-invoices.
+const (
+	TaxCategoryStandard      = "S"
+	TaxCategoryZeroRated     = "Z"
+	TaxCategoryExempt        = "E"
+	TaxCategoryReverseCharge = "AE"
 )
 
 type (
 	InvoiceDetailItem struct {
-		Date            time.Time       `yaml:"date" time_format:"2006/01/02"`
-		Title           string          `yaml:"title"`
-		Desc            string          `yaml:"desc"`
-		URL             string          `yaml:"url"`
-		URLs            []string        `yaml:"urls"`
-		Currency        string          `yaml:"currency"`
-		TotalExcludeTax decimal.Decimal `yaml:"total_exclude_tax"`
-		TotalIncludeTax decimal.Decimal `yaml:"total_include_tax"`
+		Date     time.Time `yaml:"date" json:"date" toml:"date" time_format:"2006/01/02"`
+		Title    string    `yaml:"title" json:"title" toml:"title"`
+		Desc     string    `yaml:"desc" json:"desc" toml:"desc"`
+		URL      string    `yaml:"url" json:"url" toml:"url"`
+		URLs     []string  `yaml:"urls" json:"urls" toml:"urls"`
+		Currency string    `yaml:"currency" json:"currency" toml:"currency"`
+		// Quantity/Unit/UnitPrice/Discount describe a priced line such as "40 hours × 12,000 JPY".
+		// When both totals are zero, the builder derives them as Quantity × UnitPrice − Discount.
+		Quantity        decimal.Decimal `yaml:"quantity" json:"quantity" toml:"quantity"`
+		Unit            string          `yaml:"unit" json:"unit" toml:"unit"`
+		UnitPrice       decimal.Decimal `yaml:"unit_price" json:"unit_price" toml:"unit_price"`
+		Discount        decimal.Decimal `yaml:"discount" json:"discount" toml:"discount"`
+		TotalExcludeTax decimal.Decimal `yaml:"total_exclude_tax" json:"total_exclude_tax" toml:"total_exclude_tax"`
+		TotalIncludeTax decimal.Decimal `yaml:"total_include_tax" json:"total_include_tax" toml:"total_include_tax"`
 		// TotalIncludeTaxQuoteAmount/TotalIncludeTaxQuoteSymbol provide a reference total in a quote currency,
 		// allowing the invoice to display an implied exchange rate for any currency pair.
-		TotalIncludeTaxQuoteAmount decimal.Decimal `yaml:"total_include_tax_quote_amount"`
-		TotalIncludeTaxQuoteSymbol string          `yaml:"total_include_tax_quote_symbol"`
-		Tax                        decimal.Decimal `yaml:"tax"`
+		TotalIncludeTaxQuoteAmount decimal.Decimal `yaml:"total_include_tax_quote_amount" json:"total_include_tax_quote_amount" toml:"total_include_tax_quote_amount"`
+		TotalIncludeTaxQuoteSymbol string          `yaml:"total_include_tax_quote_symbol" json:"total_include_tax_quote_symbol" toml:"total_include_tax_quote_symbol"`
+		Tax                        decimal.Decimal `yaml:"tax" json:"tax" toml:"tax"`
+		// TaxCategory/TaxRate put the item into a per-rate tax group (e.g. 0.08 reduced + 0.1 standard).
+		// Items without either fall back to Summary.TaxRate.
+		TaxCategory string          `yaml:"tax_category" json:"tax_category" toml:"tax_category"`
+		TaxRate     decimal.Decimal `yaml:"tax_rate" json:"tax_rate" toml:"tax_rate"`
+		// TaxExemptionReason states why an exempt ("E") or reverse-charge ("AE") item carries no tax,
+		// e.g. "Article 135(1)(g) of Directive 2006/112/EC". It is printed below the details.
+		TaxExemptionReason string `yaml:"tax_exemption_reason" json:"tax_exemption_reason" toml:"tax_exemption_reason"`
+		// Adjustments are applied in order after Discount, on priced lines only.
+		Adjustments []InvoiceAdjustment `yaml:"adjustments" json:"adjustments" toml:"adjustments"`
 	}
 
 	InvoiceSummary struct {
-		PeriodStart     time.Time       `yaml:"period_start" time_format:"2006/01/02"`
-		PeriodEnd       time.Time       `yaml:"period_end" time_format:"2006/01/02"`
-		Title           string          `yaml:"title"`
-		Currency        string          `yaml:"currency"`
-		TotalExcludeTax decimal.Decimal `yaml:"total_exclude_tax"`
-		TotalIncludeTax decimal.Decimal `yaml:"total_include_tax"`
+		PeriodStart     time.Time       `yaml:"period_start" json:"period_start" toml:"period_start" time_format:"2006/01/02"`
+		PeriodEnd       time.Time       `yaml:"period_end" json:"period_end" toml:"period_end" time_format:"2006/01/02"`
+		Title           string          `yaml:"title" json:"title" toml:"title"`
+		Currency        string          `yaml:"currency" json:"currency" toml:"currency"`
+		TotalExcludeTax decimal.Decimal `yaml:"total_exclude_tax" json:"total_exclude_tax" toml:"total_exclude_tax"`
+		TotalIncludeTax decimal.Decimal `yaml:"total_include_tax" json:"total_include_tax" toml:"total_include_tax"`
 		// TotalIncludeTaxQuoteAmount/TotalIncludeTaxQuoteSymbol provide a reference total in a quote currency,
-		// allowing the invoice to display an implied exchange rate for any currency pair.
-		TotalIncludeTaxQuoteAmount decimal.Decimal `yaml:"total_include_tax_quote_amount"`
-		TotalIncludeTaxQuoteSymbol string          `yaml:"total_include_tax_quote_symbol"`
+		// allowing the invoice to display an implied exchange rate for any currency pair. Without an
+		// amount, the builder computes it from its exchange rate provider, if any.
+		TotalIncludeTaxQuoteAmount decimal.Decimal `yaml:"total_include_tax_quote_amount" json:"total_include_tax_quote_amount" toml:"total_include_tax_quote_amount"`
+		TotalIncludeTaxQuoteSymbol string          `yaml:"total_include_tax_quote_symbol" json:"total_include_tax_quote_symbol" toml:"total_include_tax_quote_symbol"`
 		// Alias for TotalIncludeTaxQuoteSymbol (kept for backward/typo compatibility).
-		TotalIncludeTaxQuotaSymbol string `yaml:"total_include_tax_quota_symbol"`
+		TotalIncludeTaxQuotaSymbol string `yaml:"total_include_tax_quota_symbol" json:"total_include_tax_quota_symbol" toml:"total_include_tax_quota_symbol"`
+		// TotalIncludeTaxQuoteRateSource/TotalIncludeTaxQuoteRateDate record where the rate behind the
+		// quote amount comes from, e.g. "MUFG TTM" on 2024-04-30, the date written as ExchangeRate.Date.
+		// Rates looked up through the builder's exchange rate provider bring their own.
+		TotalIncludeTaxQuoteRateSource string    `yaml:"total_include_tax_quote_rate_source" json:"total_include_tax_quote_rate_source" toml:"total_include_tax_quote_rate_source"`
+		TotalIncludeTaxQuoteRateDate   time.Time `yaml:"total_include_tax_quote_rate_date" json:"total_include_tax_quote_rate_date" toml:"total_include_tax_quote_rate_date"`
 
 		// Deprecated: use TotalIncludeTaxQuoteAmount/TotalIncludeTaxQuoteSymbol.
-		TotalIncludeTaxJPY decimal.Decimal `yaml:"total_include_tax_jpy"`
-		Tax                decimal.Decimal `yaml:"tax"`
-		TaxRate            decimal.Decimal `yaml:"tax_rate"`
+		TotalIncludeTaxJPY decimal.Decimal `yaml:"total_include_tax_jpy" json:"total_include_tax_jpy" toml:"total_include_tax_jpy"`
+		Tax                decimal.Decimal `yaml:"tax" json:"tax" toml:"tax"`
+		TaxRate            decimal.Decimal `yaml:"tax_rate" json:"tax_rate" toml:"tax_rate"`
+		// Adjustments are document-level discounts and surcharges. TotalExcludeTax includes the
+		// taxed ones; TotalIncludeTax includes all of them.
+		Adjustments []InvoiceAdjustment `yaml:"adjustments" json:"adjustments" toml:"adjustments"`
+		// Withholding deducts 源泉徴収税 from the amount payable. It applies to yen amounts only.
+		Withholding InvoiceWithholding `yaml:"withholding" json:"withholding" toml:"withholding"`
 	}
 
 	InvoicePaymentInstruction struct {
-		Disabled bool   `yaml:"disabled"`
-		Method   string `yaml:"method"`
-
-		ReceiveAccountBank    string `yaml:"receive_account_bank"`
-		ReceiveAccountBranch  string `yaml:"receive_account_branch"`
-		ReceiveDepositType    string `yaml:"receive_deposit_type"`
-		ReceiveAccountNumber  string `yaml:"receive_account_number"`
-		ReceiveAccountName    string `yaml:"receive_account_name"`
-		ReceiveAccountRouting string `yaml:"receive_account_routing"`
-		ReceiveAccountSwift   string `yaml:"receive_account_swift"`
-
-		ReceiveCryptoCurrency string `yaml:"receive_crypto_currency"`
-		ReceiveCryptoNetwork  string `yaml:"receive_crypto_network"`
-		ReceiveCryptoAddress  string `yaml:"receive_crypto_address"`
-		ReceiveCryptoMemo     string `yaml:"receive_crypto_memo"`
+		Disabled bool   `yaml:"disabled" json:"disabled" toml:"disabled"`
+		Method   string `yaml:"method" json:"method" toml:"method"`
+
+		ReceiveAccountBank    string `yaml:"receive_account_bank" json:"receive_account_bank" toml:"receive_account_bank"`
+		ReceiveAccountBranch  string `yaml:"receive_account_branch" json:"receive_account_branch" toml:"receive_account_branch"`
+		ReceiveDepositType    string `yaml:"receive_deposit_type" json:"receive_deposit_type" toml:"receive_deposit_type"`
+		ReceiveAccountNumber  string `yaml:"receive_account_number" json:"receive_account_number" toml:"receive_account_number"`
+		ReceiveAccountName    string `yaml:"receive_account_name" json:"receive_account_name" toml:"receive_account_name"`
+		ReceiveAccountRouting string `yaml:"receive_account_routing" json:"receive_account_routing" toml:"receive_account_routing"`
+		ReceiveAccountSwift   string `yaml:"receive_account_swift" json:"receive_account_swift" toml:"receive_account_swift"`
+
+		ReceiveCryptoCurrency string `yaml:"receive_crypto_currency" json:"receive_crypto_currency" toml:"receive_crypto_currency"`
+		ReceiveCryptoNetwork  string `yaml:"receive_crypto_network" json:"receive_crypto_network" toml:"receive_crypto_network"`
+		ReceiveCryptoAddress  string `yaml:"receive_crypto_address" json:"receive_crypto_address" toml:"receive_crypto_address"`
+		ReceiveCryptoMemo     string `yaml:"receive_crypto_memo" json:"receive_crypto_memo" toml:"receive_crypto_memo"`
 	}
 
 	InvoicePaymentResult struct {
-		Disabled      bool            `yaml:"disabled"`
-		PaymentMethod string          `yaml:"payment_method"`
-		Amount        decimal.Decimal `yaml:"amount_paid"`
-		Currency      string          `yaml:"currency"`
-		PaidDate      time.Time       `yaml:"paid_date" time_format:"2006-01-02"`
-		TxID          string          `yaml:"tx_id"`
+		Disabled      bool            `yaml:"disabled" json:"disabled" toml:"disabled"`
+		PaymentMethod string          `yaml:"payment_method" json:"payment_method" toml:"payment_method"`
+		Amount        decimal.Decimal `yaml:"amount_paid" json:"amount_paid" toml:"amount_paid"`
+		Currency      string          `yaml:"currency" json:"currency" toml:"currency"`
+		PaidDate      time.Time       `yaml:"paid_date" json:"paid_date" toml:"paid_date" time_format:"2006-01-02"`
+		TxID          string          `yaml:"tx_id" json:"tx_id" toml:"tx_id"`
 	}
 
 	InvoicePayment struct {
-		InvoicePaymentInstruction `yaml:"instruction,omitempty"`
-		InvoicePaymentResult      `yaml:"result,omitempty"`
+		InvoicePaymentInstruction `yaml:"instruction,omitempty" json:"instruction,omitempty" toml:"instruction,omitempty"`
+		InvoicePaymentResult      `yaml:"result,omitempty" json:"result,omitempty" toml:"result,omitempty"`
+		// Results records installments and deposits of an invoice paid in several payments,
+		// instead of or in addition to result.
+		Results []InvoicePaymentResult `yaml:"results" json:"results" toml:"results"`
 	}
 
 	InvoiceDoc struct {
-		Title       string `yaml:"title"`
-		Description string `yaml:"description"`
+		Title       string `yaml:"title" json:"title" toml:"title"`
+		Description string `yaml:"description" json:"description" toml:"description"`
 	}
 
 	InvoiceParams struct {
-		ID           string    `yaml:"id"`
-		TaxNumber    string    `yaml:"tax_number"`
-		Date         time.Time `yaml:"date" time_format:"2006/01/02"`
-		Currency     string    `yaml:"currency"`
-		CompanyName  string    `yaml:"company_name"`
-		CompanyAddr  string    `yaml:"company_address"`
-		CompanyEmail string    `yaml:"company_email"`
-		CompanySeal  string    `yaml:"company_seal"`
-
-		BillToCompany string `yaml:"bill_to_company"`
-		BillToAddress string `yaml:"bill_to_address"`
+		ID           string    `yaml:"id" json:"id" toml:"id"`
+		TaxNumber    string    `yaml:"tax_number" json:"tax_number" toml:"tax_number"`
+		Date         time.Time `yaml:"date" json:"date" toml:"date" time_format:"2006/01/02"`
+		Currency     string    `yaml:"currency" json:"currency" toml:"currency"`
+		CompanyName  string    `yaml:"company_name" json:"company_name" toml:"company_name"`
+		CompanyAddr  string    `yaml:"company_address" json:"company_address" toml:"company_address"`
+		CompanyEmail string    `yaml:"company_email" json:"company_email" toml:"company_email"`
+		CompanySeal  string    `yaml:"company_seal" json:"company_seal" toml:"company_seal"`
+		// CompanyCountry/BillToCountry are ISO 3166-1 alpha-2 codes, required by e-invoice exports.
+		CompanyCountry string `yaml:"company_country" json:"company_country" toml:"company_country"`
+		// CompanyEndpoint/BillToEndpoint are PEPPOL electronic addresses in the form
+		// "<EAS scheme>:<identifier>", e.g. "0088:4035811991014" or "9930:DE123456789".
+		CompanyEndpoint string `yaml:"company_endpoint" json:"company_endpoint" toml:"company_endpoint"`
+
+		BillToCompany  string `yaml:"bill_to_company" json:"bill_to_company" toml:"bill_to_company"`
+		BillToAddress  string `yaml:"bill_to_address" json:"bill_to_address" toml:"bill_to_address"`
+		BillToCountry  string `yaml:"bill_to_country" json:"bill_to_country" toml:"bill_to_country"`
+		BillToEndpoint string `yaml:"bill_to_endpoint" json:"bill_to_endpoint" toml:"bill_to_endpoint"`
+		// BillToTaxNumber is the buyer's VAT identifier, e.g. "FR12345678901". Reverse-charge
+		// invoices require it.
+		BillToTaxNumber string `yaml:"bill_to_tax_number" json:"bill_to_tax_number" toml:"bill_to_tax_number"`
+		// BuyerReference is the reference the buyer asked to be quoted, e.g. a purchase order
+		// number or the German Leitweg-ID.
+		BuyerReference string `yaml:"buyer_reference" json:"buyer_reference" toml:"buyer_reference"`
+
+		// DueDate is the payment due date. When empty it is derived from Date and PaymentTerms.
+		DueDate time.Time `yaml:"due_date" json:"due_date" toml:"due_date" time_format:"2006/01/02"`
+		// PaymentTerms such as "Net 30", "end of next month" or "due on receipt"; see ParsePaymentTerms.
+		PaymentTerms string `yaml:"payment_terms" json:"payment_terms" toml:"payment_terms"`
+		// LateFee is a late payment clause printed below the header, e.g. "Overdue amounts bear
+		// interest of 1% per month."
+		LateFee string `yaml:"late_fee" json:"late_fee" toml:"late_fee"`
 
 		// Summary
-		Summary InvoiceSummary `yaml:"summary"`
+		Summary InvoiceSummary `yaml:"summary" json:"summary" toml:"summary"`
 
 		// Details
-		DetailItems []InvoiceDetailItem `yaml:"detail_items"`
+		DetailItems []InvoiceDetailItem `yaml:"detail_items" json:"detail_items" toml:"detail_items"`
 
 		// Payment Instructions
-		Payment InvoicePayment `yaml:"payment"`
+		Payment InvoicePayment `yaml:"payment" json:"payment" toml:"payment"`
 
 		// Doc related info
-		Doc InvoiceDoc `yaml:"doc"`
+		Doc InvoiceDoc `yaml:"doc" json:"doc" toml:"doc"`
 	}
 )
 
-func (params *InvoiceParams) Load(filename string) error {
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		logrus.WithError(err).Fatalf("failed to read YAML file")
-		return err
+// PaymentResults returns the recorded payments: result unless it is disabled or has no amount,
+// followed by the entries of results that are not disabled.
+func (payment InvoicePayment) PaymentResults() []InvoicePaymentResult {
+	results := make([]InvoicePaymentResult, 0, len(payment.Results)+1)
+	if result := payment.InvoicePaymentResult; !result.Disabled && !result.Amount.IsZero() {
+		results = append(results, result)
+	}
+	for _, result := range payment.Results {
+		if !result.Disabled {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// EffectiveQuantity returns Quantity, counting a priced line without a quantity as one unit.
+func (item InvoiceDetailItem) EffectiveQuantity() decimal.Decimal {
+	if item.Quantity.IsZero() && !item.UnitPrice.IsZero() {
+		return decimal.NewFromInt(1)
 	}
+	return item.Quantity
+}
 
-	if err := yaml.Unmarshal(data, params); err != nil {
-		logrus.WithError(err).Fatalf("failed to unmarshal YAML")
-		return err
+// NetAmount returns the line total excluding tax: TotalExcludeTax when set, otherwise
+// Quantity × UnitPrice − Discount plus the line's adjustments for a priced line without explicit
+// totals, rounded half up to the decimals of the item's currency.
+func (item InvoiceDetailItem) NetAmount() decimal.Decimal {
+	return item.NetAmountRounded(CurrencyRounding(item.Currency))
+}
+
+// NetAmountRounded is NetAmount with the adjustments and the computed line total rounded with r,
+// for items whose currency is inherited from the document or documents with their own rounding.
+func (item InvoiceDetailItem) NetAmountRounded(r Rounding) decimal.Decimal {
+	if !item.Priced() {
+		return item.TotalExcludeTax
+	}
+	net := item.EffectiveQuantity().Mul(item.UnitPrice).Sub(item.Discount)
+	for _, amount := range ApplyAdjustments(net, item.Adjustments, r) {
+		net = net.Add(amount)
 	}
+	return r.Round(net)
+}
+
+// AdjustmentAmounts returns the signed amount of each of the line's adjustments, or nil when the
+// line has explicit totals and its adjustments do not apply.
+func (item InvoiceDetailItem) AdjustmentAmounts(r Rounding) []decimal.Decimal {
+	if !item.Priced() || len(item.Adjustments) == 0 {
+		return nil
+	}
+	net := item.EffectiveQuantity().Mul(item.UnitPrice).Sub(item.Discount)
+	return ApplyAdjustments(net, item.Adjustments, r)
+}
+
+// Priced reports whether the line total is derived from the unit price.
+func (item InvoiceDetailItem) Priced() bool {
+	return item.TotalExcludeTax.IsZero() && item.TotalIncludeTax.IsZero() && !item.UnitPrice.IsZero()
+}
+
+// Load reads params from a YAML, JSON or TOML file; see DetectFormat.
+// Errors are *LoadError values carrying the filename and position.
+func (params *InvoiceParams) Load(filename string) error {
+	return loadFile(filename, params)
+}
+
+// LoadFromReader reads params from a stream, e.g. an HTTP body or an object storage reader.
+// The format is detected from the content.
+func (params *InvoiceParams) LoadFromReader(r io.Reader) error {
+	return loadReader(r, params)
+}
 
-	return nil
+// LoadFromBytes reads params from an in-memory document, detecting its format from the content.
+func (params *InvoiceParams) LoadFromBytes(data []byte) error {
+	return decode("", data, params)
 }
@@ -0,0 +1,239 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// Format is the serialization format of a params document.
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+	FormatTOML Format = "toml"
+)
+
+var tomlFirstLinePattern = regexp.MustCompile(`^(\[[A-Za-z0-9_.\-]+\]|[A-Za-z0-9_\-]+\s*=)`)
+
+// DetectFormat picks the format from the filename extension (.json, .toml, .yaml/.yml) and falls back
+// to sniffing the content: a leading "{" is JSON, a "[table]" or "key = value" first line is TOML,
+// anything else is YAML.
+func DetectFormat(filename string, data []byte) Format {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return FormatJSON
+	case ".toml":
+		return FormatTOML
+	case ".yaml", ".yml":
+		return FormatYAML
+	}
+
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		return FormatJSON
+	}
+	for _, line := range strings.Split(string(trimmed), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if tomlFirstLinePattern.MatchString(line) {
+			return FormatTOML
+		}
+		break
+	}
+	return FormatYAML
+}
+
+// LoadError reports a document that could not be read or decoded.
+type LoadError struct {
+	// Filename is empty when the document was loaded from a reader or bytes.
+	Filename string
+	// Line and Column are 1-based positions in the document; zero when unknown.
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *LoadError) Error() string {
+	pos := e.Filename
+	if pos == "" {
+		pos = "<input>"
+	}
+	if e.Line > 0 {
+		pos = fmt.Sprintf("%s:%d", pos, e.Line)
+		if e.Column > 0 {
+			pos = fmt.Sprintf("%s:%d", pos, e.Column)
+		}
+	}
+	msg := e.Err.Error()
+	if typeErr, ok := e.Err.(*yaml.TypeError); ok {
+		msg = "yaml: " + strings.Join(typeErr.Errors, "; ")
+	}
+	return fmt.Sprintf("%s: %s", pos, msg)
+}
+
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	yamlErrorLinePattern  = regexp.MustCompile(`line ([0-9]+):`)
+	yamlErrorValuePattern = regexp.MustCompile("line [0-9]+: cannot unmarshal \\S+ `([^`]*)`")
+)
+
+func loadFile(filename string, out any) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return &LoadError{Filename: filename, Err: err}
+	}
+	return decode(filename, data, out)
+}
+
+func loadReader(r io.Reader, out any) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return &LoadError{Err: err}
+	}
+	return decode("", data, out)
+}
+
+func decode(filename string, data []byte, out any) error {
+	return decodeFormat(filename, data, DetectFormat(filename, data), out)
+}
+
+func decodeFormat(filename string, data []byte, format Format, out any) error {
+	switch format {
+	case FormatJSON:
+		if err := json.Unmarshal(data, out); err != nil {
+			loadErr := &LoadError{Filename: filename, Err: err}
+			loadErr.Line, loadErr.Column = jsonErrorPosition(data, err)
+			return loadErr
+		}
+	case FormatTOML:
+		if err := toml.Unmarshal(data, out); err != nil {
+			loadErr := &LoadError{Filename: filename, Err: err}
+			var parseErr toml.ParseError
+			if errors.As(err, &parseErr) {
+				loadErr.Line, loadErr.Column = offsetPosition(data, int64(parseErr.Position.Start))
+			}
+			return loadErr
+		}
+	case FormatYAML, "":
+		if err := yaml.Unmarshal(data, out); err != nil {
+			loadErr := &LoadError{Filename: filename, Err: err}
+			loadErr.Line, loadErr.Column = yamlErrorPosition(data, err)
+			return loadErr
+		}
+	default:
+		return &LoadError{Filename: filename, Err: fmt.Errorf("unsupported format %q", format)}
+	}
+	return nil
+}
+
+// LoadInvoiceParamsStream reads every invoice of a multi-document YAML stream ("---" separated).
+// JSON and TOML input holds a single document. filename is only used for format detection and errors.
+func LoadInvoiceParamsStream(filename string, r io.Reader) ([]*InvoiceParams, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, &LoadError{Filename: filename, Err: err}
+	}
+
+	format := DetectFormat(filename, data)
+	if format != FormatYAML {
+		params := &InvoiceParams{}
+		if err := decodeFormat(filename, data, format, params); err != nil {
+			return nil, err
+		}
+		return []*InvoiceParams{params}, nil
+	}
+
+	var ret []*InvoiceParams
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		params := &InvoiceParams{}
+		err := decoder.Decode(params)
+		if errors.Is(err, io.EOF) {
+			return ret, nil
+		}
+		if err != nil {
+			loadErr := &LoadError{Filename: filename, Err: err}
+			loadErr.Line, loadErr.Column = yamlErrorPosition(data, err)
+			return ret, loadErr
+		}
+		if reflect.DeepEqual(*params, InvoiceParams{}) {
+			continue // empty document, e.g. a leading or trailing "---"
+		}
+		ret = append(ret, params)
+	}
+}
+
+// jsonErrorPosition converts the byte offset of an encoding/json error into a line and column.
+func jsonErrorPosition(data []byte, err error) (line, column int) {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
+		return 0, 0
+	}
+	return offsetPosition(data, offset)
+}
+
+// offsetPosition converts a byte offset into a 1-based line and column.
+func offsetPosition(data []byte, offset int64) (line, column int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	column = int(offset) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
+
+// yamlErrorPosition extracts the position of the first problem from a yaml.v2 error.
+// yaml.v2 only reports lines; for type errors the column is recovered by locating the
+// offending value on that line.
+func yamlErrorPosition(data []byte, err error) (line, column int) {
+	msg := err.Error()
+	if typeErr, ok := err.(*yaml.TypeError); ok && len(typeErr.Errors) > 0 {
+		msg = typeErr.Errors[0]
+	}
+
+	m := yamlErrorLinePattern.FindStringSubmatch(msg)
+	if m == nil {
+		return 0, 0
+	}
+	line, _ = strconv.Atoi(m[1])
+
+	v := yamlErrorValuePattern.FindStringSubmatch(msg)
+	if v == nil {
+		return line, 0
+	}
+	value := strings.TrimSuffix(v[1], "...")
+	lines := strings.Split(string(data), "\n")
+	if value == "" || line < 1 || line > len(lines) {
+		return line, 0
+	}
+	if ix := strings.Index(lines[line-1], value); ix >= 0 {
+		column = ix + 1
+	}
+	return line, column
+}
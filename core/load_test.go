@@ -0,0 +1,78 @@
+package core
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLoadMissingFileReturnsError(t *testing.T) {
+	err := (&InvoiceParams{}).Load("../samples/does-not-exist.yaml")
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) || loadErr.Filename != "../samples/does-not-exist.yaml" {
+		t.Fatalf("Load() = %v, want *LoadError with filename", err)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Load() = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestLoadReportsYAMLPosition(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		line   int
+		column int
+	}{
+		{
+			name:   "type error",
+			data:   "id: \"1\"\nsummary:\n  total_exclude_tax: [1, 2]\n  tax_rate: 0.1\ndetail_items: nope\n",
+			line:   3,
+			column: 0,
+		},
+		{
+			name:   "scalar type error",
+			data:   "id: \"1\"\ndetail_items: nope\n",
+			line:   2,
+			column: 15,
+		},
+		{
+			name: "syntax error",
+			data: "id: \"1\"\nsummary:\n  title: a: b\n",
+			line: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&InvoiceParams{}).LoadFromBytes([]byte(tt.data))
+			var loadErr *LoadError
+			if !errors.As(err, &loadErr) {
+				t.Fatalf("LoadFromBytes() = %v, want *LoadError", err)
+			}
+			if loadErr.Line != tt.line || loadErr.Column != tt.column {
+				t.Fatalf("position = %d:%d, want %d:%d (%v)", loadErr.Line, loadErr.Column, tt.line, tt.column, err)
+			}
+		})
+	}
+}
+
+func TestLoadFromReaderMatchesLoad(t *testing.T) {
+	fromFile := &SettlementStatementParams{}
+	if err := fromFile.Load("../samples/settlementstatement-1.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	data, err := os.ReadFile("../samples/settlementstatement-1.yaml")
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	fromReader := &SettlementStatementParams{}
+	if err := fromReader.LoadFromReader(strings.NewReader(string(data))); err != nil {
+		t.Fatalf("LoadFromReader: %v", err)
+	}
+	if fromReader.ID != fromFile.ID || !fromReader.Summary.TotalExcludeTax.Equal(fromFile.Summary.TotalExcludeTax) {
+		t.Fatalf("LoadFromReader = %+v, want %+v", fromReader, fromFile)
+	}
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"io"
+	"time"
+)
+
+type (
+	PurchaseOrderSummary    = InvoiceSummary
+	PurchaseOrderDetailItem = InvoiceDetailItem
+	PurchaseOrderDoc        = InvoiceDoc
+)
+
+// PurchaseOrderParams describes a purchase order: the company orders the detail items from the
+// supplier, to be delivered by DeliveryDate.
+type PurchaseOrderParams struct {
+	ID              string    `yaml:"id" json:"id" toml:"id"`
+	TaxNumber       string    `yaml:"tax_number" json:"tax_number" toml:"tax_number"`
+	Date            time.Time `yaml:"date" json:"date" toml:"date" time_format:"2006/01/02"`
+	DeliveryDate    time.Time `yaml:"delivery_date" json:"delivery_date" toml:"delivery_date" time_format:"2006/01/02"`
+	Currency        string    `yaml:"currency" json:"currency" toml:"currency"`
+	CompanyName     string    `yaml:"company_name" json:"company_name" toml:"company_name"`
+	CompanyAddr     string    `yaml:"company_address" json:"company_address" toml:"company_address"`
+	CompanyEmail    string    `yaml:"company_email" json:"company_email" toml:"company_email"`
+	CompanySeal     string    `yaml:"company_seal" json:"company_seal" toml:"company_seal"`
+	CompanyCountry  string    `yaml:"company_country" json:"company_country" toml:"company_country"`
+	CompanyEndpoint string    `yaml:"company_endpoint" json:"company_endpoint" toml:"company_endpoint"`
+
+	SupplierCompany string `yaml:"supplier_company" json:"supplier_company" toml:"supplier_company"`
+	SupplierAddress string `yaml:"supplier_address" json:"supplier_address" toml:"supplier_address"`
+	SupplierCountry string `yaml:"supplier_country" json:"supplier_country" toml:"supplier_country"`
+
+	// Summary
+	Summary PurchaseOrderSummary `yaml:"summary" json:"summary" toml:"summary"`
+
+	// Details
+	DetailItems []PurchaseOrderDetailItem `yaml:"detail_items" json:"detail_items" toml:"detail_items"`
+
+	// Doc related info
+	Doc PurchaseOrderDoc `yaml:"doc" json:"doc" toml:"doc"`
+}
+
+// Load reads params from a YAML, JSON or TOML file; see DetectFormat.
+// Errors are *LoadError values carrying the filename and position.
+func (params *PurchaseOrderParams) Load(filename string) error {
+	return loadFile(filename, params)
+}
+
+// LoadFromReader reads params from a stream, e.g. an HTTP body or an object storage reader.
+// The format is detected from the content.
+func (params *PurchaseOrderParams) LoadFromReader(r io.Reader) error {
+	return loadReader(r, params)
+}
+
+// LoadFromBytes reads params from an in-memory document, detecting its format from the content.
+func (params *PurchaseOrderParams) LoadFromBytes(data []byte) error {
+	return decode("", data, params)
+}
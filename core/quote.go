@@ -0,0 +1,140 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+type (
+	QuoteSummary            = InvoiceSummary
+	QuoteDetailItem         = InvoiceDetailItem
+	QuotePaymentInstruction = InvoicePaymentInstruction
+	QuoteDoc                = InvoiceDoc
+)
+
+type QuotePayment struct {
+	// QuotePaymentInstruction states the payment terms; ToInvoice carries it over to the invoice.
+	QuotePaymentInstruction `yaml:"instruction,omitempty" json:"instruction,omitempty" toml:"instruction,omitempty"`
+}
+
+// QuoteAcceptance is the customer's acceptance of a quote. The document renders it as a signature
+// block; AcceptedBy and AcceptedDate are pre-filled when set, and a non-zero AcceptedDate marks
+// the quote as accepted.
+type QuoteAcceptance struct {
+	Disabled     bool      `yaml:"disabled" json:"disabled" toml:"disabled"`
+	AcceptedBy   string    `yaml:"accepted_by" json:"accepted_by" toml:"accepted_by"`
+	AcceptedDate time.Time `yaml:"accepted_date" json:"accepted_date" toml:"accepted_date" time_format:"2006/01/02"`
+	// Note is printed above the signature lines, e.g. "Please sign and return a copy to accept".
+	Note string `yaml:"note" json:"note" toml:"note"`
+}
+
+// QuoteParams describes a quotation (estimate): the offer sent before invoicing, valid until ValidUntil.
+type QuoteParams struct {
+	ID              string    `yaml:"id" json:"id" toml:"id"`
+	TaxNumber       string    `yaml:"tax_number" json:"tax_number" toml:"tax_number"`
+	Date            time.Time `yaml:"date" json:"date" toml:"date" time_format:"2006/01/02"`
+	ValidUntil      time.Time `yaml:"valid_until" json:"valid_until" toml:"valid_until" time_format:"2006/01/02"`
+	Currency        string    `yaml:"currency" json:"currency" toml:"currency"`
+	CompanyName     string    `yaml:"company_name" json:"company_name" toml:"company_name"`
+	CompanyAddr     string    `yaml:"company_address" json:"company_address" toml:"company_address"`
+	CompanyEmail    string    `yaml:"company_email" json:"company_email" toml:"company_email"`
+	CompanySeal     string    `yaml:"company_seal" json:"company_seal" toml:"company_seal"`
+	CompanyCountry  string    `yaml:"company_country" json:"company_country" toml:"company_country"`
+	CompanyEndpoint string    `yaml:"company_endpoint" json:"company_endpoint" toml:"company_endpoint"`
+
+	BillToCompany   string `yaml:"bill_to_company" json:"bill_to_company" toml:"bill_to_company"`
+	BillToAddress   string `yaml:"bill_to_address" json:"bill_to_address" toml:"bill_to_address"`
+	BillToCountry   string `yaml:"bill_to_country" json:"bill_to_country" toml:"bill_to_country"`
+	BillToEndpoint  string `yaml:"bill_to_endpoint" json:"bill_to_endpoint" toml:"bill_to_endpoint"`
+	BillToTaxNumber string `yaml:"bill_to_tax_number" json:"bill_to_tax_number" toml:"bill_to_tax_number"`
+	BuyerReference  string `yaml:"buyer_reference" json:"buyer_reference" toml:"buyer_reference"`
+
+	// Summary
+	Summary QuoteSummary `yaml:"summary" json:"summary" toml:"summary"`
+
+	// Details
+	DetailItems []QuoteDetailItem `yaml:"detail_items" json:"detail_items" toml:"detail_items"`
+
+	// Payment terms
+	Payment QuotePayment `yaml:"payment" json:"payment" toml:"payment"`
+
+	// Acceptance / signature block
+	Acceptance QuoteAcceptance `yaml:"acceptance" json:"acceptance" toml:"acceptance"`
+
+	// Doc related info
+	Doc QuoteDoc `yaml:"doc" json:"doc" toml:"doc"`
+}
+
+// Load reads params from a YAML, JSON or TOML file; see DetectFormat.
+// Errors are *LoadError values carrying the filename and position.
+func (params *QuoteParams) Load(filename string) error {
+	return loadFile(filename, params)
+}
+
+// LoadFromReader reads params from a stream, e.g. an HTTP body or an object storage reader.
+// The format is detected from the content.
+func (params *QuoteParams) LoadFromReader(r io.Reader) error {
+	return loadReader(r, params)
+}
+
+// LoadFromBytes reads params from an in-memory document, detecting its format from the content.
+func (params *QuoteParams) LoadFromBytes(data []byte) error {
+	return decode("", data, params)
+}
+
+// Accepted reports whether the customer accepted the quote, i.e. acceptance.accepted_date is set.
+func (params *QuoteParams) Accepted() bool {
+	return !params.Acceptance.AcceptedDate.IsZero()
+}
+
+// ToInvoice converts an accepted quote into invoice params with the given invoice ID and date.
+// Parties, summary, detail items and payment terms are copied; the buyer reference defaults to
+// the quote ID so the invoice can be matched to the offer. It fails when the quote has not been
+// accepted or was accepted after ValidUntil.
+func (params *QuoteParams) ToInvoice(id string, date time.Time) (*InvoiceParams, error) {
+	if id == "" {
+		return nil, fmt.Errorf("invoice id is empty")
+	}
+	if date.IsZero() {
+		return nil, fmt.Errorf("invoice date is empty")
+	}
+	if !params.Accepted() {
+		return nil, fmt.Errorf("quote %s has not been accepted", params.ID)
+	}
+	if !params.ValidUntil.IsZero() && params.Acceptance.AcceptedDate.After(params.ValidUntil) {
+		return nil, fmt.Errorf("quote %s was accepted on %s, after it expired on %s", params.ID,
+			params.Acceptance.AcceptedDate.Format("2006/01/02"), params.ValidUntil.Format("2006/01/02"))
+	}
+
+	buyerReference := params.BuyerReference
+	if buyerReference == "" {
+		buyerReference = params.ID
+	}
+	items := make([]InvoiceDetailItem, len(params.DetailItems))
+	copy(items, params.DetailItems)
+	return &InvoiceParams{
+		ID:              id,
+		TaxNumber:       params.TaxNumber,
+		Date:            date,
+		Currency:        params.Currency,
+		CompanyName:     params.CompanyName,
+		CompanyAddr:     params.CompanyAddr,
+		CompanyEmail:    params.CompanyEmail,
+		CompanySeal:     params.CompanySeal,
+		CompanyCountry:  params.CompanyCountry,
+		CompanyEndpoint: params.CompanyEndpoint,
+		BillToCompany:   params.BillToCompany,
+		BillToAddress:   params.BillToAddress,
+		BillToCountry:   params.BillToCountry,
+		BillToEndpoint:  params.BillToEndpoint,
+		BillToTaxNumber: params.BillToTaxNumber,
+		BuyerReference:  buyerReference,
+		Summary:         params.Summary,
+		DetailItems:     items,
+		Payment: InvoicePayment{
+			InvoicePaymentInstruction: params.Payment.QuotePaymentInstruction,
+			InvoicePaymentResult:      InvoicePaymentResult{Disabled: true},
+		},
+	}, nil
+}
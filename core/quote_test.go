@@ -0,0 +1,59 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQuoteToInvoice(t *testing.T) {
+	quote := &QuoteParams{}
+	if err := quote.Load("../samples/quote-1.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	date := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	params, err := quote.ToInvoice("20240401-01", date)
+	if err != nil {
+		t.Fatalf("ToInvoice: %v", err)
+	}
+	if params.ID != "20240401-01" || !params.Date.Equal(date) || params.BuyerReference != quote.ID {
+		t.Fatalf("invoice = %s %s ref %q, want 20240401-01 2024-04-01 ref %q", params.ID, params.Date, params.BuyerReference, quote.ID)
+	}
+	if !params.Summary.TotalExcludeTax.Equal(quote.Summary.TotalExcludeTax) || len(params.DetailItems) != len(quote.DetailItems) {
+		t.Fatal("summary or detail items were not copied")
+	}
+	if !params.Payment.InvoicePaymentResult.Disabled {
+		t.Fatal("payment result should be disabled on a new invoice")
+	}
+	if err := params.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	params.DetailItems[0].Title = "changed"
+	if quote.DetailItems[0].Title == "changed" {
+		t.Fatal("ToInvoice shares detail items with the quote")
+	}
+}
+
+func TestQuoteToInvoiceRequiresAcceptance(t *testing.T) {
+	quote := &QuoteParams{}
+	if err := quote.Load("../samples/quote-1.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	date := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	expired := *quote
+	expired.Acceptance.AcceptedDate = quote.ValidUntil.AddDate(0, 0, 1)
+	if _, err := expired.ToInvoice("1", date); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Fatalf("ToInvoice(expired) = %v, want an expiry error", err)
+	}
+
+	pending := *quote
+	pending.Acceptance.AcceptedDate = time.Time{}
+	if _, err := pending.ToInvoice("1", date); err == nil || !strings.Contains(err.Error(), "not been accepted") {
+		t.Fatalf("ToInvoice(pending) = %v, want an acceptance error", err)
+	}
+	if _, err := quote.ToInvoice("", date); err == nil {
+		t.Fatal("ToInvoice without an id succeeded")
+	}
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Rounding modes.
+const (
+	// RoundHalfUp rounds halves away from zero: 0.125 → 0.13, -0.125 → -0.13.
+	RoundHalfUp = "half_up"
+	// RoundHalfEven rounds halves to the even neighbour (banker's rounding): 0.125 → 0.12.
+	RoundHalfEven = "half_even"
+	// RoundDown truncates towards zero (切り捨て): 0.129 → 0.12, -0.129 → -0.12.
+	RoundDown = "down"
+)
+
+// Rounding rounds amounts to a number of decimals.
+type Rounding struct {
+	// Mode is RoundHalfUp, RoundHalfEven or RoundDown. Empty rounds half up.
+	Mode     string
+	Decimals int32
+}
+
+// CurrencyRounding rounds half up to the AmountDecimals of a currency.
+func CurrencyRounding(code string) Rounding {
+	return Rounding{Mode: RoundHalfUp, Decimals: AmountDecimals(code)}
+}
+
+// Round rounds amount. All modes are symmetric around zero, so a negated amount, as on a credit
+// note, rounds to the negated result.
+func (r Rounding) Round(amount decimal.Decimal) decimal.Decimal {
+	switch r.Mode {
+	case RoundHalfEven:
+		return amount.RoundBank(r.Decimals)
+	case RoundDown:
+		return amount.RoundDown(r.Decimals)
+	default:
+		return amount.Round(r.Decimals)
+	}
+}
+
+// ParseRoundingMode normalizes a rounding mode such as "half-up" or "HALF_EVEN". Empty stays empty.
+func ParseRoundingMode(mode string) (string, error) {
+	normalized := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(mode)), "-", "_")
+	switch normalized {
+	case "", RoundHalfUp, RoundHalfEven, RoundDown:
+		return normalized, nil
+	default:
+		return "", fmt.Errorf("unknown rounding mode %q; use %s, %s or %s", mode, RoundHalfUp, RoundHalfEven, RoundDown)
+	}
+}
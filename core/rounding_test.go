@@ -0,0 +1,61 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestRoundingRound(t *testing.T) {
+	tests := []struct {
+		mode     string
+		decimals int32
+		amount   string
+		want     string
+	}{
+		{mode: "", decimals: 2, amount: "0.125", want: "0.13"},
+		{mode: RoundHalfUp, decimals: 2, amount: "-0.125", want: "-0.13"},
+		{mode: RoundHalfEven, decimals: 2, amount: "0.125", want: "0.12"},
+		{mode: RoundHalfEven, decimals: 2, amount: "0.135", want: "0.14"},
+		{mode: RoundHalfEven, decimals: 2, amount: "-0.125", want: "-0.12"},
+		{mode: RoundDown, decimals: 2, amount: "0.129", want: "0.12"},
+		{mode: RoundDown, decimals: 2, amount: "-0.129", want: "-0.12"},
+		{mode: RoundDown, decimals: 0, amount: "7999.9", want: "7999"},
+		{mode: RoundHalfUp, decimals: 0, amount: "7999.5", want: "8000"},
+	}
+	for _, tt := range tests {
+		r := Rounding{Mode: tt.mode, Decimals: tt.decimals}
+		if got := r.Round(decimal.RequireFromString(tt.amount)); got.String() != tt.want {
+			t.Errorf("Rounding{%q, %d}.Round(%s) = %s, want %s", tt.mode, tt.decimals, tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestParseRoundingMode(t *testing.T) {
+	for input, want := range map[string]string{"": "", "half-up": RoundHalfUp, " HALF_EVEN ": RoundHalfEven, "down": RoundDown} {
+		if got, err := ParseRoundingMode(input); err != nil || got != want {
+			t.Errorf("ParseRoundingMode(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseRoundingMode("ceiling"); err == nil {
+		t.Error("ParseRoundingMode(ceiling) succeeded, want an error")
+	}
+}
+
+func TestNetAmountRoundsComputedLines(t *testing.T) {
+	item := InvoiceDetailItem{
+		Quantity:  decimal.NewFromInt(3),
+		UnitPrice: decimal.RequireFromString("0.125"),
+	}
+	if got := item.NetAmount(); got.String() != "0.38" {
+		t.Errorf("NetAmount() = %s, want 0.38", got)
+	}
+	if got := item.NetAmountRounded(Rounding{Mode: RoundDown, Decimals: 2}); got.String() != "0.37" {
+		t.Errorf("NetAmountRounded(down) = %s, want 0.37", got)
+	}
+	item.TotalExcludeTax = decimal.RequireFromString("0.375")
+	item.UnitPrice = decimal.Zero
+	if got := item.NetAmount(); got.String() != "0.375" {
+		t.Errorf("NetAmount() with an explicit total = %s, want 0.375", got)
+	}
+}
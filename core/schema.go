@@ -0,0 +1,161 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	decimalType = reflect.TypeOf(decimal.Decimal{})
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+type jsonSchemaNode struct {
+	Schema               string                     `json:"$schema,omitempty"`
+	ID                   string                     `json:"$id,omitempty"`
+	Ref                  string                     `json:"$ref,omitempty"`
+	Title                string                     `json:"title,omitempty"`
+	Description          string                     `json:"description,omitempty"`
+	Type                 any                        `json:"type,omitempty"`
+	Format               string                     `json:"format,omitempty"`
+	Pattern              string                     `json:"pattern,omitempty"`
+	Items                *jsonSchemaNode            `json:"items,omitempty"`
+	Properties           map[string]*jsonSchemaNode `json:"properties,omitempty"`
+	Required             []string                   `json:"required,omitempty"`
+	AdditionalProperties *bool                      `json:"additionalProperties,omitempty"`
+	Defs                 map[string]*jsonSchemaNode `json:"$defs,omitempty"`
+}
+
+// InvoiceParamsJSONSchema returns the JSON Schema of InvoiceParams in its JSON form, so that
+// clients can validate documents before submitting them. Dates are RFC 3339 date-times and
+// amounts may be numbers or decimal strings.
+func InvoiceParamsJSONSchema() ([]byte, error) {
+	return buildJSONSchema(reflect.TypeOf(InvoiceParams{}), "invoice",
+		[]string{"id", "date", "currency", "company_name", "bill_to_company"})
+}
+
+// SettlementStatementParamsJSONSchema returns the JSON Schema of SettlementStatementParams in its JSON form.
+func SettlementStatementParamsJSONSchema() ([]byte, error) {
+	return buildJSONSchema(reflect.TypeOf(SettlementStatementParams{}), "settlementstatement",
+		[]string{"id", "date", "currency", "company_name", "recipient_company"})
+}
+
+// CreditNoteParamsJSONSchema returns the JSON Schema of CreditNoteParams in its JSON form.
+func CreditNoteParamsJSONSchema() ([]byte, error) {
+	return buildJSONSchema(reflect.TypeOf(CreditNoteParams{}), "creditnote",
+		[]string{"id", "date", "currency", "company_name", "bill_to_company", "original_invoice"})
+}
+
+// QuoteParamsJSONSchema returns the JSON Schema of QuoteParams in its JSON form.
+func QuoteParamsJSONSchema() ([]byte, error) {
+	return buildJSONSchema(reflect.TypeOf(QuoteParams{}), "quote",
+		[]string{"id", "date", "valid_until", "currency", "company_name", "bill_to_company"})
+}
+
+// PurchaseOrderParamsJSONSchema returns the JSON Schema of PurchaseOrderParams in its JSON form.
+func PurchaseOrderParamsJSONSchema() ([]byte, error) {
+	return buildJSONSchema(reflect.TypeOf(PurchaseOrderParams{}), "purchaseorder",
+		[]string{"id", "date", "currency", "company_name", "supplier_company"})
+}
+
+// DeliveryNoteParamsJSONSchema returns the JSON Schema of DeliveryNoteParams in its JSON form.
+// Currency is not listed as required because notes with hide_prices may omit it.
+func DeliveryNoteParamsJSONSchema() ([]byte, error) {
+	return buildJSONSchema(reflect.TypeOf(DeliveryNoteParams{}), "deliverynote",
+		[]string{"id", "date", "company_name", "deliver_to_company"})
+}
+
+func buildJSONSchema(t reflect.Type, name string, required []string) ([]byte, error) {
+	defs := make(map[string]*jsonSchemaNode)
+	root := structJSONSchema(t, defs)
+	root.Schema = jsonSchemaDraft
+	root.ID = fmt.Sprintf("https://github.com/quailyquaily/bizdocgen/schema/%s.schema.json", name)
+	root.Title = t.Name()
+	root.Required = required
+	root.Defs = defs
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func typeJSONSchema(t reflect.Type, defs map[string]*jsonSchemaNode) *jsonSchemaNode {
+	switch {
+	case t == decimalType:
+		if _, ok := defs["Decimal"]; !ok {
+			defs["Decimal"] = &jsonSchemaNode{
+				Description: "A decimal amount, as a JSON number or a string such as \"1234.50\".",
+				Type:        []string{"number", "string"},
+				Pattern:     `^-?[0-9]+(\.[0-9]+)?$`,
+			}
+		}
+		return &jsonSchemaNode{Ref: "#/$defs/Decimal"}
+	case t == timeType:
+		return &jsonSchemaNode{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &jsonSchemaNode{Type: "string"}
+	case reflect.Bool:
+		return &jsonSchemaNode{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &jsonSchemaNode{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchemaNode{Type: "number"}
+	case reflect.Slice:
+		// encoding/json writes nil slices as null.
+		return &jsonSchemaNode{Type: []string{"array", "null"}, Items: typeJSONSchema(t.Elem(), defs)}
+	case reflect.Array:
+		return &jsonSchemaNode{Type: "array", Items: typeJSONSchema(t.Elem(), defs)}
+	case reflect.Pointer:
+		return typeJSONSchema(t.Elem(), defs)
+	case reflect.Struct:
+		if _, ok := defs[t.Name()]; !ok {
+			defs[t.Name()] = nil // reserve the name to stop recursion
+			defs[t.Name()] = structJSONSchema(t, defs)
+		}
+		return &jsonSchemaNode{Ref: "#/$defs/" + t.Name()}
+	default:
+		return &jsonSchemaNode{}
+	}
+}
+
+func structJSONSchema(t reflect.Type, defs map[string]*jsonSchemaNode) *jsonSchemaNode {
+	closed := false
+	node := &jsonSchemaNode{
+		Type:                 "object",
+		Properties:           make(map[string]*jsonSchemaNode),
+		AdditionalProperties: &closed,
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				for key, prop := range structJSONSchema(field.Type, defs).Properties {
+					node.Properties[key] = prop
+				}
+				continue
+			}
+			name = field.Name
+		}
+		node.Properties[name] = typeJSONSchema(field.Type, defs)
+	}
+	return node
+}
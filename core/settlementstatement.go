@@ -1,11 +1,8 @@
 package core
 
 import (
-	"os"
+	"io"
 	"time"
-
-	"github.com/sirupsen/logrus"
-	"gopkg.in/yaml.v2"
 )
 
 type (
@@ -16,46 +13,48 @@ type (
 )
 
 type SettlementStatementPayment struct {
-	SettlementStatementPaymentResult `yaml:"result,omitempty"`
+	SettlementStatementPaymentResult `yaml:"result,omitempty" json:"result,omitempty" toml:"result,omitempty"`
 }
 
 type SettlementStatementParams struct {
-	ID           string    `yaml:"id"`
-	TaxNumber    string    `yaml:"tax_number"`
-	Date         time.Time `yaml:"date" time_format:"2006/01/02"`
-	Currency     string    `yaml:"currency"`
-	CompanyName  string    `yaml:"company_name"`
-	CompanyAddr  string    `yaml:"company_address"`
-	CompanyEmail string    `yaml:"company_email"`
-	CompanySeal  string    `yaml:"company_seal"`
-
-	RecipientCompany string `yaml:"recipient_company"`
-	RecipientAddress string `yaml:"recipient_address"`
+	ID           string    `yaml:"id" json:"id" toml:"id"`
+	TaxNumber    string    `yaml:"tax_number" json:"tax_number" toml:"tax_number"`
+	Date         time.Time `yaml:"date" json:"date" toml:"date" time_format:"2006/01/02"`
+	Currency     string    `yaml:"currency" json:"currency" toml:"currency"`
+	CompanyName  string    `yaml:"company_name" json:"company_name" toml:"company_name"`
+	CompanyAddr  string    `yaml:"company_address" json:"company_address" toml:"company_address"`
+	CompanyEmail string    `yaml:"company_email" json:"company_email" toml:"company_email"`
+	CompanySeal  string    `yaml:"company_seal" json:"company_seal" toml:"company_seal"`
+
+	RecipientCompany string `yaml:"recipient_company" json:"recipient_company" toml:"recipient_company"`
+	RecipientAddress string `yaml:"recipient_address" json:"recipient_address" toml:"recipient_address"`
 
 	// Summary
-	Summary SettlementStatementSummary `yaml:"summary"`
+	Summary SettlementStatementSummary `yaml:"summary" json:"summary" toml:"summary"`
 
 	// Details
-	DetailItems []SettlementStatementDetailItem `yaml:"detail_items"`
+	DetailItems []SettlementStatementDetailItem `yaml:"detail_items" json:"detail_items" toml:"detail_items"`
 
 	// Payment Instructions
-	Payment SettlementStatementPayment `yaml:"payment"`
+	Payment SettlementStatementPayment `yaml:"payment" json:"payment" toml:"payment"`
 
 	// Doc related info
-	Doc SettlementStatementDoc `yaml:"doc"`
+	Doc SettlementStatementDoc `yaml:"doc" json:"doc" toml:"doc"`
 }
 
+// Load reads params from a YAML, JSON or TOML file; see DetectFormat.
+// Errors are *LoadError values carrying the filename and position.
 func (params *SettlementStatementParams) Load(filename string) error {
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		logrus.WithError(err).Fatalf("failed to read YAML file")
-		return err
-	}
-
-	if err := yaml.Unmarshal(data, params); err != nil {
-		logrus.WithError(err).Fatalf("failed to unmarshal YAML")
-		return err
-	}
-
-	return nil
+	return loadFile(filename, params)
+}
+
+// LoadFromReader reads params from a stream, e.g. an HTTP body or an object storage reader.
+// The format is detected from the content.
+func (params *SettlementStatementParams) LoadFromReader(r io.Reader) error {
+	return loadReader(r, params)
+}
+
+// LoadFromBytes reads params from an in-memory document, detecting its format from the content.
+func (params *SettlementStatementParams) LoadFromBytes(data []byte) error {
+	return decode("", data, params)
 }
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PaymentTerms is the parsed form of InvoiceParams.PaymentTerms. The due date is Days days after
+// the issue date or, with EndOfMonth, Days days after the end of the month MonthsAfter months
+// after the issue month.
+type PaymentTerms struct {
+	Days        int
+	EndOfMonth  bool
+	MonthsAfter int
+}
+
+var (
+	netTermsPattern    = regexp.MustCompile(`^(?:net|n)\s*(\d+)(\s*eom)?$`)
+	jpDayTermsPattern  = regexp.MustCompile(`^(\d+)日以内$`)
+	termsSpacePattern  = regexp.MustCompile(`[\s_-]+`)
+	jpMonthEndTermsMap = map[string]int{"当月末": 0, "月末": 0, "翌月末": 1, "翌々月末": 2}
+)
+
+// ParsePaymentTerms understands "due on receipt", "Net 30" (also "net30", "n30"), "Net 30 EOM",
+// "EOM" / "end of month", "end of next month" and the Japanese 当月末, 翌月末, 翌々月末 and
+// "30日以内", each optionally followed by 払い. Matching ignores case, spaces, "-" and "_".
+func ParsePaymentTerms(s string) (PaymentTerms, error) {
+	terms := strings.ToLower(strings.TrimSpace(s))
+	terms = termsSpacePattern.ReplaceAllString(terms, " ")
+	terms = strings.TrimSuffix(terms, "払い")
+
+	switch terms {
+	case "due on receipt", "on receipt", "receipt", "immediate":
+		return PaymentTerms{}, nil
+	case "eom", "end of month":
+		return PaymentTerms{EndOfMonth: true}, nil
+	case "eonm", "end of next month":
+		return PaymentTerms{EndOfMonth: true, MonthsAfter: 1}, nil
+	}
+	if months, ok := jpMonthEndTermsMap[terms]; ok {
+		return PaymentTerms{EndOfMonth: true, MonthsAfter: months}, nil
+	}
+	if m := netTermsPattern.FindStringSubmatch(terms); m != nil {
+		days, err := strconv.Atoi(m[1])
+		if err != nil {
+			return PaymentTerms{}, fmt.Errorf("invalid payment terms %q: %w", s, err)
+		}
+		return PaymentTerms{Days: days, EndOfMonth: m[2] != ""}, nil
+	}
+	if m := jpDayTermsPattern.FindStringSubmatch(terms); m != nil {
+		days, err := strconv.Atoi(m[1])
+		if err != nil {
+			return PaymentTerms{}, fmt.Errorf("invalid payment terms %q: %w", s, err)
+		}
+		return PaymentTerms{Days: days}, nil
+	}
+	return PaymentTerms{}, fmt.Errorf("unknown payment terms %q (want e.g. \"Net 30\", \"EOM\", \"end of next month\" or \"due on receipt\")", s)
+}
+
+// DueDate returns the due date of an invoice issued on issued.
+func (t PaymentTerms) DueDate(issued time.Time) time.Time {
+	if !t.EndOfMonth {
+		return issued.AddDate(0, 0, t.Days)
+	}
+	firstOfMonth := time.Date(issued.Year(), issued.Month(), 1, 0, 0, 0, 0, issued.Location())
+	endOfMonth := firstOfMonth.AddDate(0, t.MonthsAfter+1, -1)
+	return endOfMonth.AddDate(0, 0, t.Days)
+}
+
+// EffectiveDueDate returns DueDate when set, otherwise the due date derived from Date and
+// PaymentTerms. It is zero when neither is given.
+func (params *InvoiceParams) EffectiveDueDate() (time.Time, error) {
+	if !params.DueDate.IsZero() {
+		return params.DueDate, nil
+	}
+	if strings.TrimSpace(params.PaymentTerms) == "" || params.Date.IsZero() {
+		return time.Time{}, nil
+	}
+	terms, err := ParsePaymentTerms(params.PaymentTerms)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return terms.DueDate(params.Date), nil
+}
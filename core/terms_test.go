@@ -0,0 +1,56 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPaymentTermsDueDate(t *testing.T) {
+	issued := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		terms string
+		want  string
+	}{
+		{terms: "Net 30", want: "2024-03-01"},
+		{terms: "net15", want: "2024-02-15"},
+		{terms: "N-60", want: "2024-03-31"},
+		{terms: "Due on receipt", want: "2024-01-31"},
+		{terms: "EOM", want: "2024-01-31"},
+		{terms: "end-of-next-month", want: "2024-02-29"},
+		{terms: "Net 10 EOM", want: "2024-02-10"},
+		{terms: "翌月末払い", want: "2024-02-29"},
+		{terms: "翌々月末", want: "2024-03-31"},
+		{terms: "30日以内", want: "2024-03-01"},
+	}
+	for _, tt := range tests {
+		terms, err := ParsePaymentTerms(tt.terms)
+		if err != nil {
+			t.Errorf("ParsePaymentTerms(%q): %v", tt.terms, err)
+			continue
+		}
+		if got := terms.DueDate(issued).Format(time.DateOnly); got != tt.want {
+			t.Errorf("%q: due date = %s, want %s", tt.terms, got, tt.want)
+		}
+	}
+
+	if _, err := ParsePaymentTerms("whenever"); err == nil {
+		t.Error("ParsePaymentTerms(whenever) succeeded")
+	}
+}
+
+func TestEffectiveDueDate(t *testing.T) {
+	params := &InvoiceParams{Date: time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), PaymentTerms: "net 30"}
+	if got, err := params.EffectiveDueDate(); err != nil || got.Format(time.DateOnly) != "2024-04-09" {
+		t.Fatalf("EffectiveDueDate() = %v, %v; want 2024-04-09", got, err)
+	}
+
+	params.DueDate = time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	if got, _ := params.EffectiveDueDate(); !got.Equal(params.DueDate) {
+		t.Fatalf("explicit due date ignored: %v", got)
+	}
+
+	params.DueDate, params.PaymentTerms = time.Time{}, ""
+	if got, err := params.EffectiveDueDate(); err != nil || !got.IsZero() {
+		t.Fatalf("EffectiveDueDate() without terms = %v, %v", got, err)
+	}
+}
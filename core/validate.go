@@ -0,0 +1,528 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// FieldError reports a problem with a single field. Field is the YAML path of the field,
+// e.g. "detail_items[1].total_exclude_tax".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationErrors is returned by Validate when one or more fields are invalid.
+type ValidationErrors []FieldError
+
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("invalid params: %s", strings.Join(msgs, "; "))
+}
+
+// Validate checks the invoice for missing or inconsistent data. It returns nil or ValidationErrors.
+func (params *InvoiceParams) Validate() error {
+	return params.validate(&validator{})
+}
+
+// ValidateWithoutSummaryCheck is Validate without comparing the summary totals with the detail
+// items, for callers that cross-check them on their own terms, e.g. with a tolerance.
+func (params *InvoiceParams) ValidateWithoutSummaryCheck() error {
+	return params.validate(&validator{skipSummaryCheck: true})
+}
+
+func (params *InvoiceParams) validate(v *validator) error {
+	v.required("id", params.ID)
+	v.requiredDate("date", params.Date.IsZero())
+	v.currency("currency", params.Currency, true)
+	v.required("company_name", params.CompanyName)
+	v.required("bill_to_company", params.BillToCompany)
+	v.country("company_country", params.CompanyCountry)
+	v.country("bill_to_country", params.BillToCountry)
+	v.endpoint("company_endpoint", params.CompanyEndpoint)
+	v.endpoint("bill_to_endpoint", params.BillToEndpoint)
+	if strings.TrimSpace(params.PaymentTerms) != "" {
+		if _, err := ParsePaymentTerms(params.PaymentTerms); err != nil {
+			v.add("payment_terms", "%v", err)
+		}
+	}
+	if !params.DueDate.IsZero() && params.DueDate.Before(params.Date) {
+		v.add("due_date", "is before date")
+	}
+	v.summary("summary", params.Summary, params.Currency, params.DetailItems)
+	v.detailItems("detail_items", params.DetailItems)
+	v.reverseCharge(params.TaxNumber, params.BillToTaxNumber, params.Summary, params.DetailItems)
+	v.paymentInstruction("payment.instruction", params.Payment.InvoicePaymentInstruction)
+	v.paymentResult("payment.result", params.Payment.InvoicePaymentResult)
+	for ix, result := range params.Payment.Results {
+		v.paymentResult(fmt.Sprintf("payment.results[%d]", ix), result)
+	}
+	return v.err()
+}
+
+// Validate checks the settlement statement for missing or inconsistent data. It returns nil or ValidationErrors.
+func (params *SettlementStatementParams) Validate() error {
+	return params.validate(&validator{})
+}
+
+// ValidateWithoutSummaryCheck is Validate without comparing the summary totals with the detail items.
+func (params *SettlementStatementParams) ValidateWithoutSummaryCheck() error {
+	return params.validate(&validator{skipSummaryCheck: true})
+}
+
+func (params *SettlementStatementParams) validate(v *validator) error {
+	v.required("id", params.ID)
+	v.requiredDate("date", params.Date.IsZero())
+	v.currency("currency", params.Currency, true)
+	v.required("company_name", params.CompanyName)
+	v.required("recipient_company", params.RecipientCompany)
+	v.summary("summary", params.Summary, params.Currency, params.DetailItems)
+	v.detailItems("detail_items", params.DetailItems)
+	v.paymentResult("payment.result", params.Payment.SettlementStatementPaymentResult)
+	return v.err()
+}
+
+// Validate checks the credit note for missing or inconsistent data. It returns nil or ValidationErrors.
+func (params *CreditNoteParams) Validate() error {
+	return params.validate(&validator{})
+}
+
+// ValidateWithoutSummaryCheck is Validate without comparing the summary totals with the detail items.
+func (params *CreditNoteParams) ValidateWithoutSummaryCheck() error {
+	return params.validate(&validator{skipSummaryCheck: true})
+}
+
+func (params *CreditNoteParams) validate(v *validator) error {
+	v.required("id", params.ID)
+	v.requiredDate("date", params.Date.IsZero())
+	v.currency("currency", params.Currency, true)
+	v.required("company_name", params.CompanyName)
+	v.required("bill_to_company", params.BillToCompany)
+	v.country("company_country", params.CompanyCountry)
+	v.country("bill_to_country", params.BillToCountry)
+	v.endpoint("company_endpoint", params.CompanyEndpoint)
+	v.endpoint("bill_to_endpoint", params.BillToEndpoint)
+	v.required("original_invoice.id", params.OriginalInvoice.ID)
+	if params.OriginalInvoice.ID != "" && params.OriginalInvoice.ID == params.ID {
+		v.add("original_invoice.id", "must differ from the credit note id")
+	}
+	if !params.OriginalInvoice.Date.IsZero() && params.OriginalInvoice.Date.After(params.Date) {
+		v.add("original_invoice.date", "is after the credit note date")
+	}
+	v.summary("summary", params.Summary, params.Currency, params.DetailItems)
+	v.detailItems("detail_items", params.DetailItems)
+	v.reverseCharge(params.TaxNumber, params.BillToTaxNumber, params.Summary, params.DetailItems)
+	v.paymentResult("payment.result", params.Payment.CreditNotePaymentResult)
+	return v.err()
+}
+
+// Validate checks the quote for missing or inconsistent data. It returns nil or ValidationErrors.
+func (params *QuoteParams) Validate() error {
+	return params.validate(&validator{})
+}
+
+// ValidateWithoutSummaryCheck is Validate without comparing the summary totals with the detail items.
+func (params *QuoteParams) ValidateWithoutSummaryCheck() error {
+	return params.validate(&validator{skipSummaryCheck: true})
+}
+
+func (params *QuoteParams) validate(v *validator) error {
+	v.required("id", params.ID)
+	v.requiredDate("date", params.Date.IsZero())
+	v.requiredDate("valid_until", params.ValidUntil.IsZero())
+	if !params.ValidUntil.IsZero() && params.ValidUntil.Before(params.Date) {
+		v.add("valid_until", "is before date")
+	}
+	v.currency("currency", params.Currency, true)
+	v.required("company_name", params.CompanyName)
+	v.required("bill_to_company", params.BillToCompany)
+	v.country("company_country", params.CompanyCountry)
+	v.country("bill_to_country", params.BillToCountry)
+	v.endpoint("company_endpoint", params.CompanyEndpoint)
+	v.endpoint("bill_to_endpoint", params.BillToEndpoint)
+	v.summary("summary", params.Summary, params.Currency, params.DetailItems)
+	v.detailItems("detail_items", params.DetailItems)
+	v.reverseCharge(params.TaxNumber, params.BillToTaxNumber, params.Summary, params.DetailItems)
+	v.paymentInstruction("payment.instruction", params.Payment.QuotePaymentInstruction)
+	if params.Accepted() && params.Acceptance.AcceptedDate.Before(params.Date) {
+		v.add("acceptance.accepted_date", "is before date")
+	}
+	return v.err()
+}
+
+// Validate checks the purchase order for missing or inconsistent data. It returns nil or ValidationErrors.
+func (params *PurchaseOrderParams) Validate() error {
+	return params.validate(&validator{})
+}
+
+// ValidateWithoutSummaryCheck is Validate without comparing the summary totals with the detail items.
+func (params *PurchaseOrderParams) ValidateWithoutSummaryCheck() error {
+	return params.validate(&validator{skipSummaryCheck: true})
+}
+
+func (params *PurchaseOrderParams) validate(v *validator) error {
+	v.required("id", params.ID)
+	v.requiredDate("date", params.Date.IsZero())
+	if !params.DeliveryDate.IsZero() && params.DeliveryDate.Before(params.Date) {
+		v.add("delivery_date", "is before date")
+	}
+	v.currency("currency", params.Currency, true)
+	v.required("company_name", params.CompanyName)
+	v.required("supplier_company", params.SupplierCompany)
+	v.country("company_country", params.CompanyCountry)
+	v.country("supplier_country", params.SupplierCountry)
+	v.endpoint("company_endpoint", params.CompanyEndpoint)
+	v.summary("summary", params.Summary, params.Currency, params.DetailItems)
+	v.detailItems("detail_items", params.DetailItems)
+	return v.err()
+}
+
+// Validate checks the delivery note for missing or inconsistent data. It returns nil or ValidationErrors.
+// Currency and amounts are only checked when the note shows prices.
+func (params *DeliveryNoteParams) Validate() error {
+	return params.validate(&validator{})
+}
+
+// ValidateWithoutSummaryCheck is Validate without comparing the summary totals with the detail items.
+func (params *DeliveryNoteParams) ValidateWithoutSummaryCheck() error {
+	return params.validate(&validator{skipSummaryCheck: true})
+}
+
+func (params *DeliveryNoteParams) validate(v *validator) error {
+	v.required("id", params.ID)
+	v.requiredDate("date", params.Date.IsZero())
+	v.currency("currency", params.Currency, !params.HidePrices)
+	v.required("company_name", params.CompanyName)
+	v.required("deliver_to_company", params.DeliverToCompany)
+	v.country("company_country", params.CompanyCountry)
+	v.country("deliver_to_country", params.DeliverToCountry)
+	v.endpoint("company_endpoint", params.CompanyEndpoint)
+	if !params.HidePrices {
+		v.summary("summary", params.Summary, params.Currency, params.DetailItems)
+	}
+	v.detailItems("detail_items", params.DetailItems)
+	for ix, item := range params.DetailItems {
+		if !item.Quantity.IsPositive() {
+			v.add(fmt.Sprintf("detail_items[%d].quantity", ix), "is required on a delivery note")
+		}
+	}
+	return v.err()
+}
+
+var (
+	countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+	endpointPattern    = regexp.MustCompile(`^[0-9A-Z]{2,4}:\S+$`)
+)
+
+type validator struct {
+	errs             ValidationErrors
+	skipSummaryCheck bool
+}
+
+func (v *validator) add(field, format string, args ...any) {
+	v.errs = append(v.errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+func (v *validator) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, "is required")
+	}
+}
+
+func (v *validator) requiredDate(field string, missing bool) {
+	if missing {
+		v.add(field, "is required")
+	}
+}
+
+func (v *validator) currency(field, code string, required bool) {
+	if strings.TrimSpace(code) == "" {
+		if required {
+			v.add(field, "is required")
+		}
+		return
+	}
+	if _, ok := LookupCurrency(code); !ok {
+		v.add(field, "unknown currency %q", code)
+	}
+}
+
+func (v *validator) country(field, code string) {
+	if code != "" && !countryCodePattern.MatchString(code) {
+		v.add(field, "must be an ISO 3166-1 alpha-2 code such as \"DE\", got %q", code)
+	}
+}
+
+func (v *validator) endpoint(field, endpoint string) {
+	if endpoint != "" && !endpointPattern.MatchString(endpoint) {
+		v.add(field, "must be \"<scheme>:<identifier>\" such as \"0088:4035811991014\", got %q", endpoint)
+	}
+}
+
+func (v *validator) nonNegative(field string, value decimal.Decimal) {
+	if value.IsNegative() {
+		v.add(field, "must not be negative, got %s", value)
+	}
+}
+
+func (v *validator) summary(field string, summary InvoiceSummary, currency string, items []InvoiceDetailItem) {
+	v.currency(field+".currency", summary.Currency, false)
+	v.nonNegative(field+".total_exclude_tax", summary.TotalExcludeTax)
+	v.nonNegative(field+".total_include_tax", summary.TotalIncludeTax)
+	v.nonNegative(field+".tax", summary.Tax)
+	v.nonNegative(field+".tax_rate", summary.TaxRate)
+	v.nonNegative(field+".total_include_tax_quote_amount", summary.TotalIncludeTaxQuoteAmount)
+	if !summary.PeriodStart.IsZero() && !summary.PeriodEnd.IsZero() && summary.PeriodEnd.Before(summary.PeriodStart) {
+		v.add(field+".period_end", "is before period_start")
+	}
+	for ix, adj := range summary.Adjustments {
+		v.adjustment(fmt.Sprintf("%s.adjustments[%d]", field, ix), adj, false)
+	}
+	v.withholding(field+".withholding", summary, currency)
+
+	if summary.TotalExcludeTax.IsZero() && summary.TotalIncludeTax.IsZero() {
+		for _, item := range items {
+			if !item.NetAmount().IsZero() || !item.TotalIncludeTax.IsZero() {
+				return
+			}
+		}
+		v.add(field, "total_exclude_tax or total_include_tax is required when detail items have no amounts")
+		return
+	}
+	for ix, adj := range summary.Adjustments {
+		if adj.Amount.IsZero() && !adj.Percent.IsZero() && !itemsHaveAmounts(items) {
+			v.add(fmt.Sprintf("%s.adjustments[%d].percent", field, ix), "needs detail items with amounts to apply to")
+		}
+	}
+
+	summaryCurrency := strings.TrimSpace(summary.Currency)
+	if summaryCurrency == "" {
+		summaryCurrency = strings.TrimSpace(currency)
+	}
+	if !v.skipSummaryCheck {
+		v.summaryMatchesItems(field, summary, summaryCurrency, currency, items)
+	}
+}
+
+// summaryMatchesItems compares the summary with the sum of the detail items and the summary
+// adjustments. The check only runs when every item is in the summary currency and carries the
+// compared total, so documents whose items are informational (no amounts) are left alone. The
+// total including tax is not checked against taxed adjustments, whose tax is not known here.
+func (v *validator) summaryMatchesItems(field string, summary InvoiceSummary, summaryCurrency, currency string, items []InvoiceDetailItem) {
+	if len(items) == 0 {
+		return
+	}
+	var sumExclude, sumInclude decimal.Decimal
+	allExclude, allInclude := true, true
+	for _, item := range items {
+		itemCurrency := strings.TrimSpace(item.Currency)
+		if itemCurrency == "" {
+			itemCurrency = strings.TrimSpace(currency)
+		}
+		if itemCurrency != summaryCurrency {
+			return
+		}
+		net := item.NetAmountRounded(CurrencyRounding(itemCurrency))
+		if net.IsZero() {
+			allExclude = false
+		}
+		if item.TotalIncludeTax.IsZero() {
+			allInclude = false
+		}
+		sumExclude = sumExclude.Add(net)
+		sumInclude = sumInclude.Add(item.TotalIncludeTax)
+	}
+	rounding := CurrencyRounding(summaryCurrency)
+	amounts := ApplyAdjustments(sumExclude, summary.Adjustments, rounding)
+	for ix, adj := range summary.Adjustments {
+		if adj.Untaxed {
+			sumInclude = sumInclude.Add(amounts[ix])
+		} else {
+			sumExclude = sumExclude.Add(amounts[ix])
+			allInclude = false
+		}
+	}
+
+	if allExclude && !summary.TotalExcludeTax.IsZero() && !rounding.Round(sumExclude).Equal(rounding.Round(summary.TotalExcludeTax)) {
+		v.add(field+".total_exclude_tax", "is %s but detail items sum to %s", summary.TotalExcludeTax, sumExclude)
+	}
+	if allInclude && !summary.TotalIncludeTax.IsZero() && !rounding.Round(sumInclude).Equal(rounding.Round(summary.TotalIncludeTax)) {
+		v.add(field+".total_include_tax", "is %s but detail items sum to %s", summary.TotalIncludeTax, sumInclude)
+	}
+}
+
+func (v *validator) detailItems(field string, items []InvoiceDetailItem) {
+	for ix, item := range items {
+		prefix := fmt.Sprintf("%s[%d]", field, ix)
+		v.required(prefix+".title", item.Title)
+		v.currency(prefix+".currency", item.Currency, false)
+		v.nonNegative(prefix+".quantity", item.Quantity)
+		v.nonNegative(prefix+".unit_price", item.UnitPrice)
+		v.nonNegative(prefix+".discount", item.Discount)
+		v.nonNegative(prefix+".total_exclude_tax", item.TotalExcludeTax)
+		v.nonNegative(prefix+".total_include_tax", item.TotalIncludeTax)
+		v.nonNegative(prefix+".tax", item.Tax)
+		v.nonNegative(prefix+".tax_rate", item.TaxRate)
+		v.nonNegative(prefix+".total_include_tax_quote_amount", item.TotalIncludeTaxQuoteAmount)
+		v.untaxedCategory(prefix, item)
+		if !item.UnitPrice.IsZero() && item.EffectiveQuantity().Mul(item.UnitPrice).LessThan(item.Discount) {
+			v.add(prefix+".discount", "exceeds quantity × unit_price")
+		} else if len(item.Adjustments) > 0 && item.NetAmount().IsNegative() {
+			v.add(prefix+".adjustments", "bring the line total below zero")
+		}
+		for jx, adj := range item.Adjustments {
+			v.adjustment(fmt.Sprintf("%s.adjustments[%d]", prefix, jx), adj, true)
+		}
+		if len(item.Adjustments) > 0 && !item.Priced() {
+			v.add(prefix+".adjustments", "need unit_price and no total_exclude_tax or total_include_tax")
+		}
+		if !item.Discount.IsZero() && !item.Priced() {
+			v.add(prefix+".discount", "needs unit_price and no total_exclude_tax or total_include_tax")
+		}
+	}
+}
+
+// untaxedCategory checks that exempt and reverse-charge items carry no tax and that only they
+// state an exemption reason.
+func (v *validator) untaxedCategory(field string, item InvoiceDetailItem) {
+	category := strings.ToUpper(strings.TrimSpace(item.TaxCategory))
+	if category != TaxCategoryExempt && category != TaxCategoryReverseCharge {
+		if strings.TrimSpace(item.TaxExemptionReason) != "" {
+			v.add(field+".tax_exemption_reason", "only applies to tax categories %q and %q", TaxCategoryExempt, TaxCategoryReverseCharge)
+		}
+		return
+	}
+	if !item.TaxRate.IsZero() {
+		v.add(field+".tax_rate", "must be zero for tax category %q", category)
+	}
+	if !item.Tax.IsZero() {
+		v.add(field+".tax", "must be zero for tax category %q", category)
+	}
+}
+
+// reverseCharge checks that a document with reverse-charge items or adjustments identifies both
+// the seller and the buyer for VAT.
+func (v *validator) reverseCharge(taxNumber, billToTaxNumber string, summary InvoiceSummary, items []InvoiceDetailItem) {
+	if !reverseCharged(summary.Adjustments, items) {
+		return
+	}
+	if strings.TrimSpace(taxNumber) == "" {
+		v.add("tax_number", "is required on reverse-charge documents")
+	}
+	if strings.TrimSpace(billToTaxNumber) == "" {
+		v.add("bill_to_tax_number", "is required on reverse-charge documents")
+	}
+}
+
+// adjustment checks a discount or surcharge; line adjustments are always taxed with their line.
+func (v *validator) adjustment(field string, adj InvoiceAdjustment, line bool) {
+	switch strings.ToLower(strings.TrimSpace(adj.Kind)) {
+	case AdjustmentDiscount, AdjustmentSurcharge:
+	case "":
+		v.add(field+".kind", "is required")
+	default:
+		v.add(field+".kind", "must be %q or %q, got %q", AdjustmentDiscount, AdjustmentSurcharge, adj.Kind)
+	}
+	v.nonNegative(field+".amount", adj.Amount)
+	v.nonNegative(field+".percent", adj.Percent)
+	v.nonNegative(field+".tax_rate", adj.TaxRate)
+	switch {
+	case adj.Amount.IsZero() && adj.Percent.IsZero():
+		v.add(field, "amount or percent is required")
+	case !adj.Amount.IsZero() && !adj.Percent.IsZero():
+		v.add(field, "set either amount or percent, not both")
+	}
+	hasTax := strings.TrimSpace(adj.TaxCategory) != "" || !adj.TaxRate.IsZero()
+	switch {
+	case line && adj.Untaxed:
+		v.add(field+".untaxed", "line adjustments are taxed with their line; use a summary adjustment")
+	case line && hasTax:
+		v.add(field+".tax_rate", "line adjustments are taxed with their line")
+	case adj.Untaxed && hasTax:
+		v.add(field+".tax_rate", "cannot be set on an untaxed adjustment")
+	}
+	if category := strings.ToUpper(strings.TrimSpace(adj.TaxCategory)); (category == TaxCategoryExempt || category == TaxCategoryReverseCharge) && !adj.TaxRate.IsZero() {
+		v.add(field+".tax_rate", "must be zero for tax category %q", category)
+	}
+}
+
+// withholding checks that 源泉徴収税 is only configured on yen documents.
+func (v *validator) withholding(field string, summary InvoiceSummary, currency string) {
+	withholding := summary.Withholding
+	v.nonNegative(field+".amount", withholding.Amount)
+	if !withholding.Enabled {
+		if !withholding.Amount.IsZero() || withholding.IncludeTax {
+			v.add(field+".enabled", "must be set to apply the withholding settings")
+		}
+		return
+	}
+	code := strings.TrimSpace(summary.Currency)
+	if code == "" {
+		code = currency
+	}
+	if cur, ok := LookupCurrency(code); ok && cur.Code != "JPY" {
+		v.add(field, "applies to JPY amounts only, got %q", code)
+	}
+}
+
+func reverseCharged(adjustments []InvoiceAdjustment, items []InvoiceDetailItem) bool {
+	for _, item := range items {
+		if strings.EqualFold(strings.TrimSpace(item.TaxCategory), TaxCategoryReverseCharge) {
+			return true
+		}
+	}
+	for _, adj := range adjustments {
+		if !adj.Untaxed && strings.EqualFold(strings.TrimSpace(adj.TaxCategory), TaxCategoryReverseCharge) {
+			return true
+		}
+	}
+	return false
+}
+
+func itemsHaveAmounts(items []InvoiceDetailItem) bool {
+	for _, item := range items {
+		if !item.NetAmount().IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *validator) paymentInstruction(field string, instruction InvoicePaymentInstruction) {
+	if instruction.Disabled {
+		return
+	}
+	hasBank := strings.TrimSpace(instruction.ReceiveAccountNumber) != ""
+	hasCrypto := strings.TrimSpace(instruction.ReceiveCryptoAddress) != ""
+	if !hasBank && !hasCrypto {
+		v.add(field, "receive_account_number or receive_crypto_address is required unless disabled")
+	}
+	if hasBank && strings.TrimSpace(instruction.ReceiveAccountBank) == "" {
+		v.add(field+".receive_account_bank", "is required with receive_account_number")
+	}
+}
+
+func (v *validator) paymentResult(field string, result InvoicePaymentResult) {
+	if result.Disabled {
+		return
+	}
+	v.currency(field+".currency", result.Currency, false)
+	v.nonNegative(field+".amount_paid", result.Amount)
+}
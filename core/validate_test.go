@@ -0,0 +1,324 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestSamplesValidate(t *testing.T) {
+	for _, filename := range []string{"../samples/invoice-1.yaml", "../samples/invoice-2.yaml", "../samples/invoice-3.yaml", "../samples/invoice-4.yaml", "../samples/invoice-5.yaml", "../samples/invoice-6.yaml", "../samples/invoice-7.yaml", "../samples/invoice-8.yaml", "../samples/invoice-9.yaml"} {
+		params := &InvoiceParams{}
+		if err := params.Load(filename); err != nil {
+			t.Fatalf("Load(%s): %v", filename, err)
+		}
+		if err := params.Validate(); err != nil {
+			t.Fatalf("Validate(%s): %v", filename, err)
+		}
+	}
+
+	statement := &SettlementStatementParams{}
+	if err := statement.Load("../samples/settlementstatement-1.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := statement.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	quote := &QuoteParams{}
+	if err := quote.Load("../samples/quote-1.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := quote.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	creditNote := &CreditNoteParams{}
+	if err := creditNote.Load("../samples/creditnote-1.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := creditNote.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	purchaseOrder := &PurchaseOrderParams{}
+	if err := purchaseOrder.Load("../samples/purchaseorder-1.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := purchaseOrder.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	deliveryNote := &DeliveryNoteParams{}
+	if err := deliveryNote.Load("../samples/deliverynote-1.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := deliveryNote.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+}
+
+func TestCreditNoteValidateChecksOriginalInvoice(t *testing.T) {
+	params := &CreditNoteParams{}
+	if err := params.Load("../samples/creditnote-1.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	params.OriginalInvoice.Date = params.Date.AddDate(0, 0, 1)
+	params.DetailItems[0].UnitPrice = decimal.NewFromInt(-450)
+
+	err := params.Validate()
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Validate() = %v, want ValidationErrors", err)
+	}
+	got := make(map[string]bool)
+	for _, fieldErr := range errs {
+		got[fieldErr.Field] = true
+	}
+	for _, field := range []string{"original_invoice.date", "detail_items[0].unit_price"} {
+		if !got[field] {
+			t.Errorf("missing error for %s in %v", field, errs)
+		}
+	}
+}
+
+func TestInvoiceValidateReportsFieldPaths(t *testing.T) {
+	params := &InvoiceParams{
+		Date:           time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC),
+		Currency:       "XYZ",
+		CompanyName:    "ABC Inc",
+		CompanyCountry: "de",
+		BillToCompany:  "XYZ LLC",
+		BillToEndpoint: "DE123456789",
+		Summary: InvoiceSummary{
+			PeriodStart:     time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			PeriodEnd:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Currency:        "USD",
+			TotalExcludeTax: decimal.NewFromInt(500),
+		},
+		DetailItems: []InvoiceDetailItem{
+			{Title: "A", Currency: "USD", TotalExcludeTax: decimal.NewFromInt(300)},
+			{Title: "B", Currency: "USD", TotalExcludeTax: decimal.NewFromInt(100), Tax: decimal.NewFromInt(-1)},
+		},
+	}
+
+	err := params.Validate()
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Validate() = %v, want ValidationErrors", err)
+	}
+
+	got := make(map[string]bool)
+	for _, fieldErr := range errs {
+		got[fieldErr.Field] = true
+	}
+	for _, field := range []string{
+		"id",
+		"currency",
+		"company_country",
+		"bill_to_endpoint",
+		"summary.period_end",
+		"summary.total_exclude_tax",
+		"detail_items[1].tax",
+		"payment.instruction",
+	} {
+		if !got[field] {
+			t.Errorf("missing error for %s in %v", field, errs)
+		}
+	}
+}
+
+func TestInvoiceValidateRequiresAmounts(t *testing.T) {
+	params := &InvoiceParams{
+		ID:            "1",
+		Date:          time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC),
+		Currency:      "JPY",
+		CompanyName:   "ABC Inc",
+		BillToCompany: "XYZ LLC",
+		DetailItems:   []InvoiceDetailItem{{Title: "A"}},
+		Payment:       InvoicePayment{InvoicePaymentInstruction: InvoicePaymentInstruction{Disabled: true}},
+	}
+	err := params.Validate()
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Field != "summary" {
+		t.Fatalf("Validate() = %v, want a single summary error", err)
+	}
+
+	params.DetailItems[0].UnitPrice = decimal.NewFromInt(1000)
+	if err := params.Validate(); err != nil {
+		t.Fatalf("Validate() with priced item = %v, want nil", err)
+	}
+}
+
+func TestInvoiceValidateComparesSummaryInCurrencyDecimals(t *testing.T) {
+	params := &InvoiceParams{
+		ID:            "1",
+		Date:          time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC),
+		Currency:      "JPY",
+		CompanyName:   "ABC Inc",
+		BillToCompany: "XYZ LLC",
+		Summary:       InvoiceSummary{TotalExcludeTax: decimal.NewFromInt(1000)},
+		DetailItems: []InvoiceDetailItem{
+			{Title: "A", TotalExcludeTax: decimal.RequireFromString("600.4")},
+			{Title: "B", TotalExcludeTax: decimal.NewFromInt(400)},
+		},
+		Payment: InvoicePayment{InvoicePaymentInstruction: InvoicePaymentInstruction{Disabled: true}},
+	}
+	if err := params.Validate(); err != nil {
+		t.Fatalf("Validate() = %v, want the items to round to the yen total", err)
+	}
+
+	params.DetailItems[0].TotalExcludeTax = decimal.RequireFromString("600.5")
+	err := params.Validate()
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Field != "summary.total_exclude_tax" {
+		t.Fatalf("Validate() = %v, want a summary.total_exclude_tax error", err)
+	}
+	if err := params.ValidateWithoutSummaryCheck(); err != nil {
+		t.Fatalf("ValidateWithoutSummaryCheck() = %v, want no summary comparison", err)
+	}
+}
+
+func TestQuoteValidateChecksDates(t *testing.T) {
+	params := &QuoteParams{}
+	if err := params.Load("../samples/quote-1.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	params.ValidUntil = params.Date.AddDate(0, 0, -1)
+	params.Acceptance.AcceptedDate = params.Date.AddDate(0, 0, -2)
+
+	err := params.Validate()
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Validate() = %v, want ValidationErrors", err)
+	}
+	got := make(map[string]bool)
+	for _, fieldErr := range errs {
+		got[fieldErr.Field] = true
+	}
+	for _, field := range []string{"valid_until", "acceptance.accepted_date"} {
+		if !got[field] {
+			t.Errorf("missing error for %s in %v", field, errs)
+		}
+	}
+}
+
+func TestDeliveryNoteValidateDependsOnHidePrices(t *testing.T) {
+	params := &DeliveryNoteParams{}
+	if err := params.Load("../samples/deliverynote-1.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	params.DetailItems[1].Quantity = decimal.Zero
+	params.HidePrices = false
+
+	err := params.Validate()
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Validate() = %v, want ValidationErrors", err)
+	}
+	got := make(map[string]bool)
+	for _, fieldErr := range errs {
+		got[fieldErr.Field] = true
+	}
+	for _, field := range []string{"currency", "summary", "detail_items[1].quantity"} {
+		if !got[field] {
+			t.Errorf("missing error for %s in %v", field, errs)
+		}
+	}
+}
+
+func TestInvoiceValidateChecksAdjustments(t *testing.T) {
+	params := &InvoiceParams{}
+	if err := params.Load("../samples/invoice-7.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	params.Summary.TotalExcludeTax = decimal.NewFromInt(8650)
+	params.Summary.Adjustments = append(params.Summary.Adjustments,
+		InvoiceAdjustment{Kind: "rebate", Amount: decimal.NewFromInt(10)},
+		InvoiceAdjustment{Kind: AdjustmentSurcharge, Amount: decimal.NewFromInt(10), Percent: decimal.NewFromInt(1)},
+	)
+	params.DetailItems[0].Adjustments[0].Untaxed = true
+	params.DetailItems[1].TotalExcludeTax = decimal.NewFromInt(4150)
+	params.DetailItems[1].Discount = decimal.NewFromInt(50)
+
+	err := params.Validate()
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Validate() = %v, want ValidationErrors", err)
+	}
+	got := make(map[string]bool)
+	for _, fieldErr := range errs {
+		got[fieldErr.Field] = true
+	}
+	for _, field := range []string{
+		"summary.total_exclude_tax",
+		"summary.adjustments[3].kind",
+		"summary.adjustments[4]",
+		"detail_items[0].adjustments[0].untaxed",
+		"detail_items[1].adjustments",
+		"detail_items[1].discount",
+	} {
+		if !got[field] {
+			t.Errorf("missing error for %s in %v", field, errs)
+		}
+	}
+}
+
+func TestInvoiceValidateChecksWithholding(t *testing.T) {
+	params := &InvoiceParams{}
+	if err := params.Load("../samples/invoice-6.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	params.Summary.Withholding = InvoiceWithholding{Enabled: true, Amount: decimal.NewFromInt(-1)}
+
+	err := params.Validate()
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Validate() = %v, want ValidationErrors", err)
+	}
+	got := make(map[string]bool)
+	for _, fieldErr := range errs {
+		got[fieldErr.Field] = true
+	}
+	for _, field := range []string{"summary.withholding", "summary.withholding.amount"} {
+		if !got[field] {
+			t.Errorf("missing error for %s in %v", field, errs)
+		}
+	}
+}
+
+func TestInvoiceValidateChecksReverseCharge(t *testing.T) {
+	params := &InvoiceParams{}
+	if err := params.Load("../samples/invoice-9.yaml"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	params.BillToTaxNumber = ""
+	params.DetailItems[0].TaxRate = decimal.RequireFromString("0.19")
+	params.DetailItems[1].Tax = decimal.NewFromInt(171)
+	params.Summary.Adjustments = []InvoiceAdjustment{{Kind: AdjustmentSurcharge, Amount: decimal.NewFromInt(50), TaxCategory: "E", TaxRate: decimal.RequireFromString("0.19")}}
+	params.Summary.TotalExcludeTax = decimal.NewFromInt(5750)
+	params.DetailItems = append(params.DetailItems, InvoiceDetailItem{Title: "Travel", TaxExemptionReason: "Out of scope"})
+
+	err := params.Validate()
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Validate() = %v, want ValidationErrors", err)
+	}
+	got := make(map[string]bool)
+	for _, fieldErr := range errs {
+		got[fieldErr.Field] = true
+	}
+	for _, field := range []string{
+		"bill_to_tax_number",
+		"detail_items[0].tax_rate",
+		"detail_items[1].tax",
+		"detail_items[2].tax_exemption_reason",
+		"summary.adjustments[0].tax_rate",
+	} {
+		if !got[field] {
+			t.Errorf("missing error for %s in %v", field, errs)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package core
+
+import "github.com/shopspring/decimal"
+
+var (
+	withholdingThreshold = decimal.NewFromInt(1000000)
+	withholdingRate      = decimal.RequireFromString("0.1021")
+	withholdingRateAbove = decimal.RequireFromString("0.2042")
+)
+
+// InvoiceWithholding configures the Japanese withholding income tax (源泉徴収税) the client deducts
+// from a freelancer's fee and pays to the tax office on the freelancer's behalf.
+type InvoiceWithholding struct {
+	Enabled bool `yaml:"enabled" json:"enabled" toml:"enabled"`
+	// IncludeTax computes the tax on the total including consumption tax, for invoices that do
+	// not state the consumption tax separately.
+	IncludeTax bool `yaml:"include_tax" json:"include_tax" toml:"include_tax"`
+	// Amount overrides the computed tax, e.g. for fees the tiered rule does not apply to.
+	Amount decimal.Decimal `yaml:"amount" json:"amount" toml:"amount"`
+}
+
+// WithholdingTax returns the withholding tax on a fee of amount yen: 10.21% up to 1,000,000 yen
+// and 20.42% of the excess, rounded down to the yen. The rates include the special income tax
+// for reconstruction (復興特別所得税).
+func WithholdingTax(amount decimal.Decimal) decimal.Decimal {
+	if !amount.IsPositive() {
+		return decimal.Zero
+	}
+	if amount.LessThanOrEqual(withholdingThreshold) {
+		return amount.Mul(withholdingRate).Floor()
+	}
+	above := amount.Sub(withholdingThreshold).Mul(withholdingRateAbove)
+	return withholdingThreshold.Mul(withholdingRate).Add(above).Floor()
+}
@@ -0,0 +1,26 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestWithholdingTax(t *testing.T) {
+	tests := []struct {
+		amount string
+		want   string
+	}{
+		{amount: "0", want: "0"},
+		{amount: "-50000", want: "0"},
+		{amount: "55555", want: "5672"},
+		{amount: "1000000", want: "102100"},
+		{amount: "1200000", want: "142940"},
+		{amount: "1000001", want: "102100"},
+	}
+	for _, tt := range tests {
+		if got := WithholdingTax(decimal.RequireFromString(tt.amount)); got.String() != tt.want {
+			t.Errorf("WithholdingTax(%s) = %s, want %s", tt.amount, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Package einvoice holds a syntax-neutral model of an invoice following the EN 16931 semantic
+// data model. The builder package fills it from core params and its computed totals; the syntax
+// packages (facturx for CII, ubl for UBL 2.1) serialize it.
+//
+// Field comments name the EN 16931 business terms (BT-n) they carry.
+package einvoice
+
+import (
+	"strings"
+	"time"
+
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+// Invoice type codes (UNTDID 1001).
+const (
+	TypeCodeInvoice    = "380"
+	TypeCodeCreditNote = "381"
+)
+
+// Payment means codes (UNTDID 4461).
+const (
+	PaymentMeansCreditTransfer     = "30"
+	PaymentMeansSEPACreditTransfer = "58"
+	PaymentMeansMutuallyDefined    = "ZZZ"
+)
+
+type (
+	Invoice struct {
+		ID        string    // BT-1
+		IssueDate time.Time // BT-2
+		TypeCode  string    // BT-3
+		Currency  string    // BT-5
+		DueDate   time.Time // BT-9
+		// PaymentTerms describes the terms of payment, e.g. "Net 30" (BT-20).
+		PaymentTerms string
+		// BuyerReference is the buyer's reference or order number (BT-10).
+		BuyerReference string
+		Notes          []string // BT-22
+
+		PeriodStart time.Time // BT-73
+		PeriodEnd   time.Time // BT-74
+
+		Seller Party // BG-4
+		Buyer  Party // BG-7
+
+		Payment *PaymentMeans // BG-16
+
+		Lines []Line // BG-25
+		// AllowanceCharges are the document-level allowances (BG-20) and charges (BG-21).
+		AllowanceCharges []AllowanceCharge
+		TaxBreakdown     []TaxSubtotal // BG-23
+		Totals           Totals        // BG-22
+	}
+
+	// AllowanceCharge is a document-level allowance or, with Charge set, a charge.
+	AllowanceCharge struct {
+		Charge      bool
+		Amount      decimal.Decimal // BT-92, BT-99
+		Reason      string          // BT-97, BT-104
+		TaxCategory string          // BT-95, BT-102
+		TaxRate     decimal.Decimal // BT-96, BT-103, as a fraction
+	}
+
+	Party struct {
+		Name    string
+		Address Address
+		// VATID is the VAT identifier prefixed with the country code, e.g. "DE123456789" (BT-31, BT-48).
+		VATID string
+		// TaxRegistrationID is a local tax registration such as a Japanese "T" number (BT-32).
+		TaxRegistrationID string
+		// EndpointID is the electronic address in EndpointScheme, an EAS code such as "0088" or
+		// "EM" for e-mail (BT-34, BT-49).
+		EndpointID     string
+		EndpointScheme string
+		Email          string // BT-43, BT-58
+	}
+
+	Address struct {
+		Lines       []string // BT-35..BT-37, BT-50..BT-52
+		CountryCode string   // BT-40, BT-55
+	}
+
+	PaymentMeans struct {
+		TypeCode string // BT-81
+		// Information is free text, e.g. the crypto network and address (BT-82).
+		Information string
+		// AccountID is the IBAN for SEPA transfers, otherwise the proprietary account number (BT-84).
+		AccountID   string
+		AccountName string // BT-85
+		BIC         string // BT-86
+	}
+
+	Line struct {
+		ID          string          // BT-126
+		Name        string          // BT-153
+		Description string          // BT-154
+		Quantity    decimal.Decimal // BT-129
+		UnitCode    string          // BT-130
+		NetPrice    decimal.Decimal // BT-146
+		// Allowance is the line discount (BT-136).
+		Allowance decimal.Decimal
+		// Charge is the line surcharge (BT-141) with its reason (BT-144).
+		Charge       decimal.Decimal
+		ChargeReason string
+		NetAmount    decimal.Decimal // BT-131
+		TaxCategory  string          // BT-151
+		TaxRate      decimal.Decimal // BT-152, as a fraction (0.1 for 10%)
+	}
+
+	TaxSubtotal struct {
+		Category string          // BT-118
+		Rate     decimal.Decimal // BT-119, as a fraction
+		Base     decimal.Decimal // BT-116
+		Tax      decimal.Decimal // BT-117
+		// ExemptionReason is required for exempt and reverse-charge categories (BT-120).
+		ExemptionReason string
+	}
+
+	Totals struct {
+		LineTotal      decimal.Decimal // BT-106
+		AllowanceTotal decimal.Decimal // BT-107
+		ChargeTotal    decimal.Decimal // BT-108
+		TaxBasis       decimal.Decimal // BT-109
+		TaxTotal       decimal.Decimal // BT-110
+		GrandTotal     decimal.Decimal // BT-112
+		Prepaid        decimal.Decimal // BT-113
+		DuePayable     decimal.Decimal // BT-115
+	}
+)
+
+// Decimals returns the number of decimals amounts are stated with: the currency's minor units,
+// capped at the two decimals EN 16931 allows.
+func (inv *Invoice) Decimals() int32 {
+	cur, ok := core.LookupCurrency(inv.Currency)
+	if !ok || cur.MinorUnits > 2 {
+		return 2
+	}
+	return cur.MinorUnits
+}
+
+// FormatAmount renders an amount with the invoice's number of decimals, e.g. "1500.00" or "1500".
+func (inv *Invoice) FormatAmount(amount decimal.Decimal) string {
+	return amount.StringFixed(inv.Decimals())
+}
+
+// FormatPercent renders a fractional rate as a percentage, e.g. 0.08 as "8".
+func FormatPercent(rate decimal.Decimal) string {
+	return rate.Shift(2).String()
+}
+
+// FormatDate renders a date in the compact CCYYMMDD form (UNTDID 2379 code 102).
+func FormatDate(t time.Time) string {
+	return t.Format("20060102")
+}
+
+// unitCodes maps common free-text units to UN/ECE Recommendation 20 codes.
+var unitCodes = map[string]string{
+	"h": "HUR", "hr": "HUR", "hrs": "HUR", "hour": "HUR", "hours": "HUR", "時間": "HUR",
+	"min": "MIN", "minute": "MIN", "minutes": "MIN",
+	"day": "DAY", "days": "DAY", "日": "DAY",
+	"week": "WEE", "weeks": "WEE",
+	"month": "MON", "months": "MON", "ヶ月": "MON", "か月": "MON",
+	"year": "ANN", "years": "ANN",
+	"pc": "H87", "pcs": "H87", "piece": "H87", "pieces": "H87", "個": "H87",
+	"kg": "KGM", "g": "GRM", "m": "MTR", "km": "KMT", "l": "LTR",
+	"set": "SET", "sets": "SET", "式": "SET",
+}
+
+// UnitCode maps a free-text unit ("hours", "pcs") to its UN/ECE Recommendation 20 code.
+// Three-letter codes are passed through; anything else becomes "C62" (one).
+func UnitCode(unit string) string {
+	unit = strings.TrimSpace(unit)
+	if code, ok := unitCodes[strings.ToLower(unit)]; ok {
+		return code
+	}
+	if len(unit) == 3 && strings.ToUpper(unit) == unit {
+		return unit
+	}
+	return "C62"
+}
@@ -0,0 +1,281 @@
+package einvoice
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+// RuleError reports a violated business rule, e.g. "BR-CO-10".
+type RuleError struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (e RuleError) Error() string {
+	return fmt.Sprintf("[%s] %s", e.Rule, e.Message)
+}
+
+// RuleErrors is returned by the Validate methods when one or more rules are violated.
+type RuleErrors []RuleError
+
+func (errs RuleErrors) Error() string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("e-invoice rules violated: %s", strings.Join(msgs, "; "))
+}
+
+// Validate checks the EN 16931 core business rules that can be decided from the model alone
+// (mandatory terms, line and document totals, tax breakdown consistency). It returns nil or RuleErrors.
+func (inv *Invoice) Validate() error {
+	v := &validator{inv: inv}
+	v.header()
+	v.parties(true)
+	v.payment()
+	v.lines()
+	v.totals()
+	v.breakdown()
+	return v.err()
+}
+
+// ValidateDocumentLevel checks the subset of rules that applies to summary-only documents without
+// lines or postal addresses, such as the Factur-X MINIMUM profile.
+func (inv *Invoice) ValidateDocumentLevel() error {
+	v := &validator{inv: inv}
+	v.header()
+	v.parties(false)
+	v.documentTotals()
+	return v.err()
+}
+
+type validator struct {
+	inv  *Invoice
+	errs RuleErrors
+}
+
+func (v *validator) add(rule, format string, args ...any) {
+	v.errs = append(v.errs, RuleError{Rule: rule, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+func (v *validator) header() {
+	inv := v.inv
+	if strings.TrimSpace(inv.ID) == "" {
+		v.add("BR-2", "invoice number (BT-1) is required")
+	}
+	if inv.IssueDate.IsZero() {
+		v.add("BR-3", "issue date (BT-2) is required")
+	}
+	if inv.TypeCode == "" {
+		v.add("BR-4", "invoice type code (BT-3) is required")
+	}
+	if _, ok := core.LookupCurrency(inv.Currency); !ok || len(inv.Currency) != 3 {
+		v.add("BR-5", "invoice currency (BT-5) must be an ISO 4217 code, got %q", inv.Currency)
+	}
+	if !inv.PeriodStart.IsZero() && !inv.PeriodEnd.IsZero() && inv.PeriodEnd.Before(inv.PeriodStart) {
+		v.add("BR-29", "invoicing period end (BT-74) is before its start (BT-73)")
+	}
+}
+
+func (v *validator) parties(full bool) {
+	inv := v.inv
+	if strings.TrimSpace(inv.Seller.Name) == "" {
+		v.add("BR-6", "seller name (BT-27) is required")
+	}
+	if strings.TrimSpace(inv.Buyer.Name) == "" {
+		v.add("BR-7", "buyer name (BT-44) is required")
+	}
+	if inv.Seller.Address.CountryCode == "" {
+		v.add("BR-9", "seller country code (BT-40) is required")
+	}
+	if full && inv.Buyer.Address.CountryCode == "" {
+		v.add("BR-11", "buyer country code (BT-55) is required")
+	}
+	if vat := inv.Seller.VATID; vat != "" && (len(vat) < 3 || strings.ToUpper(vat[:2]) != vat[:2]) {
+		v.add("BR-CO-9", "seller VAT identifier (BT-31) must start with a country prefix, got %q", vat)
+	}
+	if vat := inv.Buyer.VATID; vat != "" && (len(vat) < 3 || strings.ToUpper(vat[:2]) != vat[:2]) {
+		v.add("BR-CO-9", "buyer VAT identifier (BT-48) must start with a country prefix, got %q", vat)
+	}
+}
+
+func (v *validator) payment() {
+	means := v.inv.Payment
+	if means == nil {
+		return
+	}
+	if means.TypeCode == "" {
+		v.add("BR-49", "payment means type code (BT-81) is required")
+	}
+	if (means.TypeCode == PaymentMeansCreditTransfer || means.TypeCode == PaymentMeansSEPACreditTransfer) && means.AccountID == "" {
+		v.add("BR-61", "credit transfers need the payment account identifier (BT-84)")
+	}
+}
+
+func (v *validator) lines() {
+	inv := v.inv
+	if len(inv.Lines) == 0 {
+		v.add("BR-16", "at least one invoice line (BG-25) is required")
+	}
+	lineTotal := decimal.Zero
+	for ix, line := range inv.Lines {
+		ref := fmt.Sprintf("line %d", ix+1)
+		if line.ID == "" {
+			v.add("BR-21", "%s: line identifier (BT-126) is required", ref)
+		}
+		if line.Quantity.IsZero() {
+			v.add("BR-22", "%s: invoiced quantity (BT-129) is required", ref)
+		}
+		if line.UnitCode == "" {
+			v.add("BR-23", "%s: unit of measure (BT-130) is required", ref)
+		}
+		if strings.TrimSpace(line.Name) == "" {
+			v.add("BR-25", "%s: item name (BT-153) is required", ref)
+		}
+		if line.NetPrice.IsNegative() {
+			v.add("BR-27", "%s: item net price (BT-146) must not be negative", ref)
+		}
+		if line.TaxCategory == "" {
+			v.add("BR-CO-4", "%s: VAT category code (BT-151) is required", ref)
+		}
+		lineTotal = lineTotal.Add(line.NetAmount)
+	}
+	if !v.equalAmounts(lineTotal, inv.Totals.LineTotal) {
+		v.add("BR-CO-10", "sum of line net amounts %s differs from the line total (BT-106) %s",
+			inv.FormatAmount(lineTotal), inv.FormatAmount(inv.Totals.LineTotal))
+	}
+}
+
+func (v *validator) totals() {
+	inv := v.inv
+	allowances, charges := decimal.Zero, decimal.Zero
+	for ix, ac := range inv.AllowanceCharges {
+		if ac.Charge {
+			charges = charges.Add(ac.Amount)
+			ref := fmt.Sprintf("document charge %d", ix+1)
+			if ac.TaxCategory == "" {
+				v.add("BR-37", "%s: VAT category code (BT-102) is required", ref)
+			}
+			if strings.TrimSpace(ac.Reason) == "" {
+				v.add("BR-38", "%s: reason (BT-104) is required", ref)
+			}
+			continue
+		}
+		allowances = allowances.Add(ac.Amount)
+		ref := fmt.Sprintf("document allowance %d", ix+1)
+		if ac.TaxCategory == "" {
+			v.add("BR-32", "%s: VAT category code (BT-95) is required", ref)
+		}
+		if strings.TrimSpace(ac.Reason) == "" {
+			v.add("BR-33", "%s: reason (BT-97) is required", ref)
+		}
+	}
+	if !v.equalAmounts(allowances, inv.Totals.AllowanceTotal) {
+		v.add("BR-CO-11", "sum of document allowances %s differs from the allowance total (BT-107) %s",
+			inv.FormatAmount(allowances), inv.FormatAmount(inv.Totals.AllowanceTotal))
+	}
+	if !v.equalAmounts(charges, inv.Totals.ChargeTotal) {
+		v.add("BR-CO-12", "sum of document charges %s differs from the charge total (BT-108) %s",
+			inv.FormatAmount(charges), inv.FormatAmount(inv.Totals.ChargeTotal))
+	}
+	basis := inv.Totals.LineTotal.Sub(inv.Totals.AllowanceTotal).Add(inv.Totals.ChargeTotal)
+	if !v.equalAmounts(basis, inv.Totals.TaxBasis) {
+		v.add("BR-CO-13", "tax basis (BT-109) %s differs from the line total − allowances + charges %s",
+			inv.FormatAmount(inv.Totals.TaxBasis), inv.FormatAmount(basis))
+	}
+	v.documentTotals()
+}
+
+func (v *validator) documentTotals() {
+	inv := v.inv
+	t := inv.Totals
+	if !v.equalAmounts(t.TaxBasis.Add(t.TaxTotal), t.GrandTotal) {
+		v.add("BR-CO-15", "grand total (BT-112) %s differs from tax basis + tax %s",
+			inv.FormatAmount(t.GrandTotal), inv.FormatAmount(t.TaxBasis.Add(t.TaxTotal)))
+	}
+	if !v.equalAmounts(t.GrandTotal.Sub(t.Prepaid), t.DuePayable) {
+		v.add("BR-CO-16", "amount due (BT-115) %s differs from grand total − prepaid %s",
+			inv.FormatAmount(t.DuePayable), inv.FormatAmount(t.GrandTotal.Sub(t.Prepaid)))
+	}
+}
+
+func (v *validator) breakdown() {
+	inv := v.inv
+	if len(inv.TaxBreakdown) == 0 {
+		v.add("BR-CO-18", "at least one VAT breakdown (BG-23) is required")
+		return
+	}
+
+	tax := decimal.Zero
+	for _, group := range inv.TaxBreakdown {
+		tax = tax.Add(group.Tax)
+		label := fmt.Sprintf("VAT breakdown %s %s%%", group.Category, FormatPercent(group.Rate))
+
+		switch group.Category {
+		case "S":
+			if !group.Rate.IsPositive() {
+				v.add("BR-S-5", "%s: standard rated groups need a rate above zero", label)
+			}
+		case "Z", "E", "AE", "O", "K", "G":
+			if !group.Rate.IsZero() || !group.Tax.IsZero() {
+				v.add("BR-"+group.Category+"-9", "%s: category %s must have a zero rate and tax", label, group.Category)
+			}
+		}
+		if group.Category == "AE" && (inv.Seller.VATID == "" || inv.Buyer.VATID == "") {
+			v.add("BR-AE-2", "%s: reverse charge needs the seller (BT-31) and buyer (BT-48) VAT identifiers", label)
+		}
+		if (group.Category == "E" || group.Category == "AE" || group.Category == "O") && strings.TrimSpace(group.ExemptionReason) == "" {
+			v.add("BR-"+group.Category+"-10", "%s: an exemption reason (BT-120) is required", label)
+		}
+
+		// The calculated tax may deviate from base × rate by one currency unit (rounding per rate).
+		calculated := group.Base.Mul(group.Rate).Round(2)
+		if group.Tax.Sub(calculated).Abs().GreaterThan(decimal.NewFromInt(1)) {
+			v.add("BR-CO-17", "%s: tax %s differs from base × rate %s",
+				label, inv.FormatAmount(group.Tax), inv.FormatAmount(calculated))
+		}
+
+		if len(inv.Lines) > 0 {
+			base := decimal.Zero
+			for _, line := range inv.Lines {
+				if line.TaxCategory == group.Category && line.TaxRate.Equal(group.Rate) {
+					base = base.Add(line.NetAmount)
+				}
+			}
+			for _, ac := range inv.AllowanceCharges {
+				if ac.TaxCategory != group.Category || !ac.TaxRate.Equal(group.Rate) {
+					continue
+				}
+				if ac.Charge {
+					base = base.Add(ac.Amount)
+				} else {
+					base = base.Sub(ac.Amount)
+				}
+			}
+			if !v.equalAmounts(base, group.Base) {
+				v.add("BR-"+group.Category+"-8", "%s: taxable amount %s differs from the sum of its lines and document allowances and charges %s",
+					label, inv.FormatAmount(group.Base), inv.FormatAmount(base))
+			}
+		}
+	}
+	if !v.equalAmounts(tax, inv.Totals.TaxTotal) {
+		v.add("BR-CO-14", "tax total (BT-110) %s differs from the sum of the VAT breakdown %s",
+			inv.FormatAmount(inv.Totals.TaxTotal), inv.FormatAmount(tax))
+	}
+}
+
+// equalAmounts compares two amounts as they are serialized.
+func (v *validator) equalAmounts(a, b decimal.Decimal) bool {
+	decimals := v.inv.Decimals()
+	return a.Round(decimals).Equal(b.Round(decimals))
+}
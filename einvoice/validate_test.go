@@ -0,0 +1,115 @@
+package einvoice
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func sampleInvoice() *Invoice {
+	d := decimal.RequireFromString
+	return &Invoice{
+		ID:        "INV-1",
+		IssueDate: time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC),
+		TypeCode:  TypeCodeInvoice,
+		Currency:  "EUR",
+		Seller: Party{
+			Name:    "ABC GmbH",
+			Address: Address{Lines: []string{"Friedrichstraße 123", "10117 Berlin"}, CountryCode: "DE"},
+			VATID:   "DE123456789",
+		},
+		Buyer: Party{
+			Name:    "XYZ SARL",
+			Address: Address{Lines: []string{"12 Rue de Rivoli"}, CountryCode: "FR"},
+		},
+		Lines: []Line{
+			{ID: "1", Name: "Development", Quantity: d("40"), UnitCode: "HUR", NetPrice: d("95"), NetAmount: d("3800"), TaxCategory: "S", TaxRate: d("0.19")},
+			{ID: "2", Name: "License", Quantity: d("2"), UnitCode: "C62", NetPrice: d("450"), Allowance: d("100"), NetAmount: d("800"), TaxCategory: "S", TaxRate: d("0.19")},
+		},
+		TaxBreakdown: []TaxSubtotal{{Category: "S", Rate: d("0.19"), Base: d("4600"), Tax: d("874")}},
+		Totals: Totals{
+			LineTotal:  d("4600"),
+			TaxBasis:   d("4600"),
+			TaxTotal:   d("874"),
+			GrandTotal: d("5474"),
+			DuePayable: d("5474"),
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := sampleInvoice().Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Invoice)
+		rule   string
+	}{
+		{"missing seller country", func(inv *Invoice) { inv.Seller.Address.CountryCode = "" }, "BR-9"},
+		{"no lines", func(inv *Invoice) { inv.Lines = nil }, "BR-16"},
+		{"line total", func(inv *Invoice) { inv.Totals.LineTotal = decimal.NewFromInt(4500) }, "BR-CO-10"},
+		{"grand total", func(inv *Invoice) { inv.Totals.GrandTotal = decimal.NewFromInt(5000) }, "BR-CO-15"},
+		{"breakdown tax", func(inv *Invoice) { inv.TaxBreakdown[0].Tax = decimal.NewFromInt(900) }, "BR-CO-17"},
+		{"reverse charge without buyer VAT ID", reverseCharge, "BR-AE-2"},
+		{"reverse charge without reason", func(inv *Invoice) {
+			reverseCharge(inv)
+			inv.Buyer.VATID = "FR12345678901"
+			inv.TaxBreakdown[0].ExemptionReason = ""
+		}, "BR-AE-10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := sampleInvoice()
+			tt.modify(inv)
+			var errs RuleErrors
+			if err := inv.Validate(); !errors.As(err, &errs) || !hasRule(errs, tt.rule) {
+				t.Fatalf("Validate() = %v, want %s", err, tt.rule)
+			}
+		})
+	}
+}
+
+// reverseCharge turns the sample into a reverse-charge invoice without a buyer VAT identifier.
+func reverseCharge(inv *Invoice) {
+	for ix := range inv.Lines {
+		inv.Lines[ix].TaxCategory, inv.Lines[ix].TaxRate = "AE", decimal.Zero
+	}
+	inv.TaxBreakdown = []TaxSubtotal{{Category: "AE", Base: inv.Totals.TaxBasis, ExemptionReason: "Reverse charge"}}
+	inv.Totals.TaxTotal = decimal.Zero
+	inv.Totals.GrandTotal = inv.Totals.TaxBasis
+	inv.Totals.DuePayable = inv.Totals.TaxBasis
+}
+
+func TestValidateDocumentLevel(t *testing.T) {
+	inv := sampleInvoice()
+	inv.Lines = nil
+	inv.Buyer.Address = Address{}
+	if err := inv.ValidateDocumentLevel(); err != nil {
+		t.Fatalf("ValidateDocumentLevel: %v", err)
+	}
+	inv.Totals.DuePayable = decimal.NewFromInt(1)
+	if err := inv.ValidateDocumentLevel(); err == nil {
+		t.Fatal("inconsistent amount due was accepted")
+	}
+}
+
+func TestUnitCode(t *testing.T) {
+	for unit, want := range map[string]string{"hours": "HUR", " Days ": "DAY", "KGM": "KGM", "seats": "C62", "": "C62"} {
+		if got := UnitCode(unit); got != want {
+			t.Errorf("UnitCode(%q) = %q, want %q", unit, got, want)
+		}
+	}
+}
+
+func hasRule(errs RuleErrors, rule string) bool {
+	for _, err := range errs {
+		if err.Rule == rule {
+			return true
+		}
+	}
+	return false
+}
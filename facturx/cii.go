@@ -0,0 +1,380 @@
+package facturx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"time"
+
+	"github.com/quailyquaily/bizdocgen/einvoice"
+	"github.com/shopspring/decimal"
+)
+
+// The CII types below mirror the UN/CEFACT Cross Industry Invoice D16B schema. Element order
+// matters to schema validation, so fields follow the sequence defined by the XSD.
+
+type ciiInvoice struct {
+	XMLName     xml.Name       `xml:"rsm:CrossIndustryInvoice"`
+	XmlnsRSM    string         `xml:"xmlns:rsm,attr"`
+	XmlnsQDT    string         `xml:"xmlns:qdt,attr"`
+	XmlnsRAM    string         `xml:"xmlns:ram,attr"`
+	XmlnsUDT    string         `xml:"xmlns:udt,attr"`
+	Context     ciiContext     `xml:"rsm:ExchangedDocumentContext"`
+	Document    ciiDocument    `xml:"rsm:ExchangedDocument"`
+	Transaction ciiTransaction `xml:"rsm:SupplyChainTradeTransaction"`
+}
+
+type ciiContext struct {
+	GuidelineID string `xml:"ram:GuidelineSpecifiedDocumentContextParameter>ram:ID"`
+}
+
+type ciiDocument struct {
+	ID        string    `xml:"ram:ID"`
+	TypeCode  string    `xml:"ram:TypeCode"`
+	IssueDate ciiDate   `xml:"ram:IssueDateTime"`
+	Notes     []ciiNote `xml:"ram:IncludedNote,omitempty"`
+}
+
+type ciiNote struct {
+	Content string `xml:"ram:Content"`
+}
+
+type ciiDate struct {
+	Value ciiDateString `xml:"udt:DateTimeString"`
+}
+
+type ciiDateString struct {
+	Format string `xml:"format,attr"`
+	Value  string `xml:",chardata"`
+}
+
+type ciiTransaction struct {
+	Lines      []ciiLine     `xml:"ram:IncludedSupplyChainTradeLineItem,omitempty"`
+	Agreement  ciiAgreement  `xml:"ram:ApplicableHeaderTradeAgreement"`
+	Delivery   struct{}      `xml:"ram:ApplicableHeaderTradeDelivery"`
+	Settlement ciiSettlement `xml:"ram:ApplicableHeaderTradeSettlement"`
+}
+
+type ciiLine struct {
+	LineID     string            `xml:"ram:AssociatedDocumentLineDocument>ram:LineID"`
+	Product    ciiProduct        `xml:"ram:SpecifiedTradeProduct"`
+	NetPrice   string            `xml:"ram:SpecifiedLineTradeAgreement>ram:NetPriceProductTradePrice>ram:ChargeAmount"`
+	Quantity   ciiQuantity       `xml:"ram:SpecifiedLineTradeDelivery>ram:BilledQuantity"`
+	Settlement ciiLineSettlement `xml:"ram:SpecifiedLineTradeSettlement"`
+}
+
+type ciiProduct struct {
+	Name        string `xml:"ram:Name"`
+	Description string `xml:"ram:Description,omitempty"`
+}
+
+type ciiQuantity struct {
+	UnitCode string `xml:"unitCode,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type ciiLineSettlement struct {
+	Tax        ciiLineTax           `xml:"ram:ApplicableTradeTax"`
+	Allowances []ciiAllowanceCharge `xml:"ram:SpecifiedTradeAllowanceCharge,omitempty"`
+	LineTotal  string               `xml:"ram:SpecifiedTradeSettlementLineMonetarySummation>ram:LineTotalAmount"`
+}
+
+type ciiLineTax struct {
+	TypeCode     string `xml:"ram:TypeCode"`
+	CategoryCode string `xml:"ram:CategoryCode"`
+	RatePercent  string `xml:"ram:RateApplicablePercent,omitempty"`
+}
+
+type ciiAllowanceCharge struct {
+	ChargeIndicator bool        `xml:"ram:ChargeIndicator>udt:Indicator"`
+	ActualAmount    string      `xml:"ram:ActualAmount"`
+	ReasonCode      string      `xml:"ram:ReasonCode,omitempty"`
+	Reason          string      `xml:"ram:Reason,omitempty"`
+	Tax             *ciiLineTax `xml:"ram:CategoryTradeTax,omitempty"`
+}
+
+type ciiAgreement struct {
+	BuyerReference string   `xml:"ram:BuyerReference,omitempty"`
+	Seller         ciiParty `xml:"ram:SellerTradeParty"`
+	Buyer          ciiParty `xml:"ram:BuyerTradeParty"`
+}
+
+type ciiParty struct {
+	Name             string               `xml:"ram:Name"`
+	Address          *ciiAddress          `xml:"ram:PostalTradeAddress,omitempty"`
+	Endpoint         *ciiURI              `xml:"ram:URIUniversalCommunication,omitempty"`
+	TaxRegistrations []ciiTaxRegistration `xml:"ram:SpecifiedTaxRegistration,omitempty"`
+}
+
+type ciiAddress struct {
+	LineOne   string `xml:"ram:LineOne,omitempty"`
+	LineTwo   string `xml:"ram:LineTwo,omitempty"`
+	LineThree string `xml:"ram:LineThree,omitempty"`
+	CountryID string `xml:"ram:CountryID"`
+}
+
+type ciiURI struct {
+	ID ciiSchemeID `xml:"ram:URIID"`
+}
+
+type ciiTaxRegistration struct {
+	ID ciiSchemeID `xml:"ram:ID"`
+}
+
+type ciiSchemeID struct {
+	SchemeID string `xml:"schemeID,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type ciiSettlement struct {
+	Currency     string               `xml:"ram:InvoiceCurrencyCode"`
+	PaymentMeans *ciiPaymentMeans     `xml:"ram:SpecifiedTradeSettlementPaymentMeans,omitempty"`
+	Taxes        []ciiHeaderTax       `xml:"ram:ApplicableTradeTax,omitempty"`
+	Period       *ciiPeriod           `xml:"ram:BillingSpecifiedPeriod,omitempty"`
+	Allowances   []ciiAllowanceCharge `xml:"ram:SpecifiedTradeAllowanceCharge,omitempty"`
+	PaymentTerms *ciiPaymentTerms     `xml:"ram:SpecifiedTradePaymentTerms,omitempty"`
+	Summation    ciiSummation         `xml:"ram:SpecifiedTradeSettlementHeaderMonetarySummation"`
+}
+
+type ciiPaymentTerms struct {
+	Description string   `xml:"ram:Description,omitempty"`
+	DueDate     *ciiDate `xml:"ram:DueDateDateTime,omitempty"`
+}
+
+type ciiPaymentMeans struct {
+	TypeCode    string      `xml:"ram:TypeCode"`
+	Information string      `xml:"ram:Information,omitempty"`
+	Account     *ciiAccount `xml:"ram:PayeePartyCreditorFinancialAccount,omitempty"`
+	BIC         string      `xml:"ram:PayeeSpecifiedCreditorFinancialInstitution>ram:BICID,omitempty"`
+}
+
+type ciiAccount struct {
+	IBAN          string `xml:"ram:IBANID,omitempty"`
+	AccountName   string `xml:"ram:AccountName,omitempty"`
+	ProprietaryID string `xml:"ram:ProprietaryID,omitempty"`
+}
+
+type ciiHeaderTax struct {
+	CalculatedAmount string `xml:"ram:CalculatedAmount"`
+	TypeCode         string `xml:"ram:TypeCode"`
+	ExemptionReason  string `xml:"ram:ExemptionReason,omitempty"`
+	BasisAmount      string `xml:"ram:BasisAmount"`
+	CategoryCode     string `xml:"ram:CategoryCode"`
+	RatePercent      string `xml:"ram:RateApplicablePercent"`
+}
+
+type ciiPeriod struct {
+	Start *ciiDate `xml:"ram:StartDateTime,omitempty"`
+	End   *ciiDate `xml:"ram:EndDateTime,omitempty"`
+}
+
+type ciiSummation struct {
+	LineTotal      string    `xml:"ram:LineTotalAmount,omitempty"`
+	ChargeTotal    string    `xml:"ram:ChargeTotalAmount,omitempty"`
+	AllowanceTotal string    `xml:"ram:AllowanceTotalAmount,omitempty"`
+	TaxBasis       string    `xml:"ram:TaxBasisTotalAmount"`
+	TaxTotal       ciiAmount `xml:"ram:TaxTotalAmount"`
+	GrandTotal     string    `xml:"ram:GrandTotalAmount"`
+	Prepaid        string    `xml:"ram:TotalPrepaidAmount,omitempty"`
+	DuePayable     string    `xml:"ram:DuePayableAmount"`
+}
+
+type ciiAmount struct {
+	Currency string `xml:"currencyID,attr"`
+	Value    string `xml:",chardata"`
+}
+
+func newCIIDate(t time.Time) ciiDate {
+	return ciiDate{Value: ciiDateString{Format: "102", Value: einvoice.FormatDate(t)}}
+}
+
+// marshalCII serializes inv for profile. MINIMUM carries the document-level data only.
+func marshalCII(inv *einvoice.Invoice, profile Profile) ([]byte, error) {
+	full := profile == ProfileEN16931
+
+	doc := ciiInvoice{
+		XmlnsRSM: "urn:un:unece:uncefact:data:standard:CrossIndustryInvoice:100",
+		XmlnsQDT: "urn:un:unece:uncefact:data:standard:QualifiedDataType:100",
+		XmlnsRAM: "urn:un:unece:uncefact:data:standard:ReusableAggregateBusinessInformationEntity:100",
+		XmlnsUDT: "urn:un:unece:uncefact:data:standard:UnqualifiedDataType:100",
+		Context:  ciiContext{GuidelineID: profile.guidelineID()},
+		Document: ciiDocument{
+			ID:        inv.ID,
+			TypeCode:  inv.TypeCode,
+			IssueDate: newCIIDate(inv.IssueDate),
+		},
+	}
+
+	tx := &doc.Transaction
+	tx.Agreement = ciiAgreement{
+		BuyerReference: inv.BuyerReference,
+		Seller:         ciiTradeParty(inv.Seller, full),
+		Buyer:          ciiTradeParty(inv.Buyer, full),
+	}
+	// MINIMUM requires the seller's country but no other address data, and no buyer address.
+	if !full {
+		tx.Agreement.Seller.Address = &ciiAddress{CountryID: inv.Seller.Address.CountryCode}
+		tx.Agreement.Buyer.Address = nil
+		tx.Agreement.Buyer.TaxRegistrations = nil
+	}
+
+	tx.Settlement = ciiSettlement{
+		Currency: inv.Currency,
+		Summation: ciiSummation{
+			TaxBasis:   inv.FormatAmount(inv.Totals.TaxBasis),
+			TaxTotal:   ciiAmount{Currency: inv.Currency, Value: inv.FormatAmount(inv.Totals.TaxTotal)},
+			GrandTotal: inv.FormatAmount(inv.Totals.GrandTotal),
+			DuePayable: inv.FormatAmount(inv.Totals.DuePayable),
+		},
+	}
+	if !inv.Totals.Prepaid.IsZero() && full {
+		tx.Settlement.Summation.Prepaid = inv.FormatAmount(inv.Totals.Prepaid)
+	}
+
+	if full {
+		for _, note := range inv.Notes {
+			doc.Document.Notes = append(doc.Document.Notes, ciiNote{Content: note})
+		}
+		for _, line := range inv.Lines {
+			tx.Lines = append(tx.Lines, ciiTradeLine(inv, line))
+		}
+		tx.Settlement.PaymentMeans = ciiPayment(inv.Payment)
+		if inv.PaymentTerms != "" || !inv.DueDate.IsZero() {
+			tx.Settlement.PaymentTerms = &ciiPaymentTerms{Description: inv.PaymentTerms}
+			if !inv.DueDate.IsZero() {
+				dueDate := newCIIDate(inv.DueDate)
+				tx.Settlement.PaymentTerms.DueDate = &dueDate
+			}
+		}
+		for _, group := range inv.TaxBreakdown {
+			tx.Settlement.Taxes = append(tx.Settlement.Taxes, ciiHeaderTax{
+				CalculatedAmount: inv.FormatAmount(group.Tax),
+				TypeCode:         "VAT",
+				ExemptionReason:  group.ExemptionReason,
+				BasisAmount:      inv.FormatAmount(group.Base),
+				CategoryCode:     group.Category,
+				RatePercent:      einvoice.FormatPercent(group.Rate),
+			})
+		}
+		if !inv.PeriodStart.IsZero() || !inv.PeriodEnd.IsZero() {
+			period := &ciiPeriod{}
+			if !inv.PeriodStart.IsZero() {
+				start := newCIIDate(inv.PeriodStart)
+				period.Start = &start
+			}
+			if !inv.PeriodEnd.IsZero() {
+				end := newCIIDate(inv.PeriodEnd)
+				period.End = &end
+			}
+			tx.Settlement.Period = period
+		}
+		for _, ac := range inv.AllowanceCharges {
+			tx.Settlement.Allowances = append(tx.Settlement.Allowances, ciiAllowanceCharge{
+				ChargeIndicator: ac.Charge,
+				ActualAmount:    inv.FormatAmount(ac.Amount),
+				Reason:          ac.Reason,
+				Tax: &ciiLineTax{
+					TypeCode:     "VAT",
+					CategoryCode: ac.TaxCategory,
+					RatePercent:  einvoice.FormatPercent(ac.TaxRate),
+				},
+			})
+		}
+		tx.Settlement.Summation.LineTotal = inv.FormatAmount(inv.Totals.LineTotal)
+		if len(inv.AllowanceCharges) > 0 {
+			tx.Settlement.Summation.ChargeTotal = inv.FormatAmount(inv.Totals.ChargeTotal)
+			tx.Settlement.Summation.AllowanceTotal = inv.FormatAmount(inv.Totals.AllowanceTotal)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+func ciiTradeParty(party einvoice.Party, full bool) ciiParty {
+	p := ciiParty{Name: party.Name}
+	if full {
+		addr := &ciiAddress{CountryID: party.Address.CountryCode}
+		lines := party.Address.Lines
+		// CII has three address lines; overflow is folded into the last one.
+		if len(lines) > 3 {
+			lines = append(lines[:2:2], strings.Join(lines[2:], ", "))
+		}
+		for ix, line := range lines {
+			switch ix {
+			case 0:
+				addr.LineOne = line
+			case 1:
+				addr.LineTwo = line
+			case 2:
+				addr.LineThree = line
+			}
+		}
+		p.Address = addr
+		if party.EndpointID != "" {
+			p.Endpoint = &ciiURI{ID: ciiSchemeID{SchemeID: party.EndpointScheme, Value: party.EndpointID}}
+		}
+	}
+	if party.VATID != "" {
+		p.TaxRegistrations = append(p.TaxRegistrations, ciiTaxRegistration{ID: ciiSchemeID{SchemeID: "VA", Value: party.VATID}})
+	}
+	if party.TaxRegistrationID != "" {
+		p.TaxRegistrations = append(p.TaxRegistrations, ciiTaxRegistration{ID: ciiSchemeID{SchemeID: "FC", Value: party.TaxRegistrationID}})
+	}
+	return p
+}
+
+func ciiTradeLine(inv *einvoice.Invoice, line einvoice.Line) ciiLine {
+	l := ciiLine{
+		LineID:   line.ID,
+		Product:  ciiProduct{Name: line.Name, Description: line.Description},
+		NetPrice: line.NetPrice.String(),
+		Quantity: ciiQuantity{UnitCode: line.UnitCode, Value: line.Quantity.String()},
+		Settlement: ciiLineSettlement{
+			Tax: ciiLineTax{
+				TypeCode:     "VAT",
+				CategoryCode: line.TaxCategory,
+				RatePercent:  einvoice.FormatPercent(line.TaxRate),
+			},
+			LineTotal: inv.FormatAmount(line.NetAmount),
+		},
+	}
+	if line.Allowance.GreaterThan(decimal.Zero) {
+		l.Settlement.Allowances = []ciiAllowanceCharge{{
+			ChargeIndicator: false,
+			ActualAmount:    inv.FormatAmount(line.Allowance),
+			ReasonCode:      "95", // UNTDID 5189: discount
+		}}
+	}
+	if line.Charge.GreaterThan(decimal.Zero) {
+		l.Settlement.Allowances = append(l.Settlement.Allowances, ciiAllowanceCharge{
+			ChargeIndicator: true,
+			ActualAmount:    inv.FormatAmount(line.Charge),
+			Reason:          line.ChargeReason,
+		})
+	}
+	return l
+}
+
+func ciiPayment(means *einvoice.PaymentMeans) *ciiPaymentMeans {
+	if means == nil {
+		return nil
+	}
+	pm := &ciiPaymentMeans{TypeCode: means.TypeCode, Information: means.Information, BIC: means.BIC}
+	if means.AccountID != "" {
+		pm.Account = &ciiAccount{AccountName: means.AccountName}
+		if means.TypeCode == einvoice.PaymentMeansSEPACreditTransfer {
+			pm.Account.IBAN = means.AccountID
+		} else {
+			pm.Account.ProprietaryID = means.AccountID
+		}
+	}
+	return pm
+}
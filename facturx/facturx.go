@@ -0,0 +1,95 @@
+// Package facturx produces Factur-X / ZUGFeRD hybrid invoices: a PDF/A-3 document carrying the
+// Cross Industry Invoice (CII) XML of the same invoice as an associated file.
+//
+// The XML is generated from an einvoice.Invoice; Embed turns an existing PDF into the PDF/A-3
+// container by adding the attachment, the XMP metadata with the Factur-X extension schema and an
+// sRGB output intent.
+package facturx
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/quailyquaily/bizdocgen/einvoice"
+)
+
+// XMLFilename is the name the CII XML is embedded under, as required by the specification.
+const XMLFilename = "factur-x.xml"
+
+// Profile is a Factur-X conformance level.
+type Profile string
+
+const (
+	// ProfileMinimum carries the document-level data only. It is not a full invoice in
+	// Germany and France, so the XML is attached as supplementary data.
+	ProfileMinimum Profile = "MINIMUM"
+	// ProfileEN16931 carries the complete EN 16931 core invoice, lines included.
+	ProfileEN16931 Profile = "EN 16931"
+)
+
+// ParseProfile accepts "minimum", "en16931", "en 16931" and the ZUGFeRD alias "comfort",
+// case-insensitively.
+func ParseProfile(name string) (Profile, error) {
+	switch strings.ToLower(strings.Join(strings.Fields(name), "")) {
+	case "minimum":
+		return ProfileMinimum, nil
+	case "en16931", "comfort":
+		return ProfileEN16931, nil
+	default:
+		return "", fmt.Errorf("unknown Factur-X profile %q (supported: minimum, en16931)", name)
+	}
+}
+
+func (p Profile) guidelineID() string {
+	if p == ProfileMinimum {
+		return "urn:factur-x.eu:1p0:minimum"
+	}
+	return "urn:cen.eu:en16931:2017"
+}
+
+// afRelationship is the PDF/A-3 relationship between the PDF and the attached XML.
+func (p Profile) afRelationship() string {
+	if p == ProfileMinimum {
+		return "Data"
+	}
+	return "Alternative"
+}
+
+// Validate checks inv against the business rules the profile requires.
+func Validate(inv *einvoice.Invoice, profile Profile) error {
+	switch profile {
+	case ProfileMinimum:
+		return inv.ValidateDocumentLevel()
+	case ProfileEN16931:
+		return inv.Validate()
+	default:
+		return fmt.Errorf("unknown Factur-X profile %q", profile)
+	}
+}
+
+// CII validates inv for profile and returns its Cross Industry Invoice XML.
+func CII(inv *einvoice.Invoice, profile Profile) ([]byte, error) {
+	if err := Validate(inv, profile); err != nil {
+		return nil, err
+	}
+	return marshalCII(inv, profile)
+}
+
+// Metadata describes the document in the PDF info dictionary and the XMP packet.
+type Metadata struct {
+	Title   string
+	Author  string
+	Subject string
+	// Date is used as creation and modification date; the zero value means now.
+	Date time.Time
+}
+
+// Generate builds the CII XML for inv and embeds it into pdf.
+func Generate(pdf []byte, inv *einvoice.Invoice, profile Profile, meta Metadata) ([]byte, error) {
+	xml, err := CII(inv, profile)
+	if err != nil {
+		return nil, err
+	}
+	return Embed(pdf, xml, profile, meta)
+}
@@ -0,0 +1,131 @@
+package facturx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"github.com/quailyquaily/bizdocgen/einvoice"
+	"github.com/shopspring/decimal"
+)
+
+func TestParseProfile(t *testing.T) {
+	for name, want := range map[string]Profile{"minimum": ProfileMinimum, "EN16931": ProfileEN16931, "en 16931": ProfileEN16931, "Comfort": ProfileEN16931} {
+		if got, err := ParseProfile(name); err != nil || got != want {
+			t.Errorf("ParseProfile(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseProfile("extended"); err == nil {
+		t.Error("ParseProfile(extended) succeeded")
+	}
+}
+
+func TestCII(t *testing.T) {
+	d := decimal.RequireFromString
+	inv := &einvoice.Invoice{
+		ID:        "INV-1",
+		IssueDate: time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC),
+		TypeCode:  einvoice.TypeCodeInvoice,
+		Currency:  "EUR",
+		Seller:    einvoice.Party{Name: "ABC GmbH", Address: einvoice.Address{CountryCode: "DE"}, VATID: "DE123456789"},
+		Buyer:     einvoice.Party{Name: "XYZ SARL"},
+		TaxBreakdown: []einvoice.TaxSubtotal{
+			{Category: "S", Rate: d("0.19"), Base: d("100"), Tax: d("19")},
+		},
+		Totals: einvoice.Totals{LineTotal: d("100"), TaxBasis: d("100"), TaxTotal: d("19"), GrandTotal: d("119"), DuePayable: d("119")},
+	}
+
+	out, err := CII(inv, ProfileMinimum)
+	if err != nil {
+		t.Fatalf("CII(MINIMUM): %v", err)
+	}
+	if err := xml.Unmarshal(out, new(struct{})); err != nil {
+		t.Fatalf("MINIMUM XML is not well-formed: %v", err)
+	}
+	for _, want := range []string{
+		"<ram:ID>urn:factur-x.eu:1p0:minimum</ram:ID>",
+		`<udt:DateTimeString format="102">20240415</udt:DateTimeString>`,
+		`<ram:TaxTotalAmount currencyID="EUR">19.00</ram:TaxTotalAmount>`,
+		"<ram:DuePayableAmount>119.00</ram:DuePayableAmount>",
+	} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Errorf("MINIMUM XML lacks %s:\n%s", want, out)
+		}
+	}
+
+	// The full profile requires lines and the buyer's address.
+	if _, err := CII(inv, ProfileEN16931); err == nil {
+		t.Fatal("CII(EN 16931) accepted an invoice without lines")
+	}
+}
+
+func TestPDFString(t *testing.T) {
+	for in, want := range map[string]string{
+		"Invoice (1)": `(Invoice \(1\))`,
+		`a\b`:         `(a\\b)`,
+		"請求書":         "<FEFF8ACB6C4266F8>",
+	} {
+		if got := pdfString(in); got != want {
+			t.Errorf("pdfString(%q) = %s, want %s", in, got, want)
+		}
+	}
+	date := time.Date(2024, 2, 10, 12, 0, 0, 0, time.FixedZone("JST", 9*3600))
+	if got := pdfDate(date); got != "D:20240210120000+09'00'" {
+		t.Errorf("pdfDate = %s", got)
+	}
+}
+
+// testPDF assembles a one-page PDF whose content stream holds data, with an indirect /Length.
+func testPDF(data string) []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 100 100] /Contents 4 0 R >>",
+		"<< /Length 5 0 R >>\nstream\n" + data + "\nendstream",
+		fmt.Sprint(len(data)),
+	}
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	// The reader looks for the trailer in 512-byte blocks from the end of the file.
+	buf.WriteString("%" + strings.Repeat("-", 512) + "\n")
+	offsets := make([]int, len(objects))
+	for ix, obj := range objects {
+		offsets[ix] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", ix+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f\r\n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n\r\n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+func TestEmbedCopiesStreamsByLength(t *testing.T) {
+	data := "% endstream endobj\nBT ET"
+	meta := Metadata{Title: "Invoice 1", Date: time.Date(2024, 4, 15, 9, 0, 0, 0, time.UTC)}
+	out, err := Embed(testPDF(data), []byte("<rsm:CrossIndustryInvoice/>"), ProfileMinimum, meta)
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	ctx, err := pdfcpu.Read(bytes.NewReader(out), &model.Configuration{Reader15: true, ValidationMode: model.ValidationRelaxed})
+	if err != nil {
+		t.Fatalf("pdfcpu.Read: %v", err)
+	}
+	sd, ok := ctx.Table[4].Object.(types.StreamDict)
+	if !ok || string(sd.Raw) != data {
+		t.Fatalf("content stream = %q, want %q", sd.Raw, data)
+	}
+
+	again, err := Embed(testPDF(data), []byte("<rsm:CrossIndustryInvoice/>"), ProfileMinimum, meta)
+	if err != nil || !bytes.Equal(out, again) {
+		t.Fatalf("Embed with the same date is not reproducible (err = %v)", err)
+	}
+}
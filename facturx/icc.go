@@ -0,0 +1,118 @@
+package facturx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// srgbProfileDescription identifies the output intent profile.
+const srgbProfileDescription = "sRGB IEC61966-2.1"
+
+// srgbICCProfile builds a compact ICC v2 display profile for sRGB: D65 white point, the
+// Bradford-adapted sRGB primaries and a sampled sRGB tone curve shared by all three channels.
+// PDF/A requires an output intent for documents using device RGB colours, which maroto does.
+func srgbICCProfile() []byte {
+	type tag struct {
+		sig  string
+		data []byte
+	}
+	tags := []tag{
+		{"desc", iccTextDescription(srgbProfileDescription)},
+		{"cprt", iccText("No copyright, use freely")},
+		{"wtpt", iccXYZ(0.9505, 1.0, 1.0891)},
+		{"rXYZ", iccXYZ(0.4361, 0.2225, 0.0139)},
+		{"gXYZ", iccXYZ(0.3851, 0.7169, 0.0971)},
+		{"bXYZ", iccXYZ(0.1431, 0.0606, 0.7141)},
+		{"rTRC", iccSRGBCurve()},
+	}
+
+	headerSize := 128
+	tableSize := 4 + 12*(len(tags)+2) // gTRC and bTRC share the rTRC data
+	offsets := make([]int, len(tags))
+	offset := headerSize + tableSize
+	for ix, t := range tags {
+		offsets[ix] = offset
+		offset += (len(t.data) + 3) &^ 3
+	}
+	size := offset
+
+	var buf bytes.Buffer
+	be := func(v any) { _ = binary.Write(&buf, binary.BigEndian, v) }
+
+	// Header.
+	be(uint32(size))
+	buf.WriteString("\x00\x00\x00\x00") // preferred CMM
+	be(uint32(0x02100000))              // version 2.1
+	buf.WriteString("mntrRGB XYZ ")
+	be([6]uint16{2024, 1, 1, 0, 0, 0})
+	buf.WriteString("acsp")
+	buf.Write(make([]byte, 4+4+4+4+8))                                    // platform, flags, manufacturer, model, attributes
+	be(uint32(0))                                                         // rendering intent: perceptual
+	be([3]int32{s15Fixed16(0.9642), s15Fixed16(1.0), s15Fixed16(0.8249)}) // D50 PCS illuminant
+	buf.Write(make([]byte, headerSize-buf.Len()))
+
+	// Tag table.
+	be(uint32(len(tags) + 2))
+	for ix, t := range tags {
+		buf.WriteString(t.sig)
+		be([2]uint32{uint32(offsets[ix]), uint32(len(t.data))})
+	}
+	trc := len(tags) - 1
+	for _, sig := range []string{"gTRC", "bTRC"} {
+		buf.WriteString(sig)
+		be([2]uint32{uint32(offsets[trc]), uint32(len(tags[trc].data))})
+	}
+
+	// Tag data, 4-byte aligned.
+	for _, t := range tags {
+		buf.Write(t.data)
+		buf.Write(make([]byte, ((len(t.data)+3)&^3)-len(t.data)))
+	}
+	return buf.Bytes()
+}
+
+func s15Fixed16(v float64) int32 {
+	return int32(math.Round(v * 65536))
+}
+
+func iccXYZ(x, y, z float64) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("XYZ \x00\x00\x00\x00")
+	_ = binary.Write(&buf, binary.BigEndian, [3]int32{s15Fixed16(x), s15Fixed16(y), s15Fixed16(z)})
+	return buf.Bytes()
+}
+
+func iccText(s string) []byte {
+	return append([]byte("text\x00\x00\x00\x00"+s), 0)
+}
+
+// iccTextDescription encodes a v2 textDescriptionType with an ASCII description and empty
+// Unicode and ScriptCode parts.
+func iccTextDescription(s string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("desc\x00\x00\x00\x00")
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(s)+1))
+	buf.WriteString(s)
+	buf.WriteByte(0)
+	buf.Write(make([]byte, 4+4+2+1+67))
+	return buf.Bytes()
+}
+
+// iccSRGBCurve samples the sRGB transfer function at 1024 points.
+func iccSRGBCurve() []byte {
+	const points = 1024
+	var buf bytes.Buffer
+	buf.WriteString("curv\x00\x00\x00\x00")
+	_ = binary.Write(&buf, binary.BigEndian, uint32(points))
+	for ix := 0; ix < points; ix++ {
+		v := float64(ix) / (points - 1)
+		if v <= 0.04045 {
+			v /= 12.92
+		} else {
+			v = math.Pow((v+0.055)/1.055, 2.4)
+		}
+		_ = binary.Write(&buf, binary.BigEndian, uint16(math.Round(v*65535)))
+	}
+	return buf.Bytes()
+}
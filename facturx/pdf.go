@@ -0,0 +1,263 @@
+package facturx
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// producer is written to the info dictionary and the XMP packet.
+const producer = "bizdocgen"
+
+// Embed rewrites pdf as a Factur-X PDF/A-3 document with xml attached as factur-x.xml.
+//
+// The source objects are copied unchanged; a new catalog, info dictionary, XMP metadata stream,
+// sRGB output intent and the associated file are appended and a single cross-reference table is
+// written. Full PDF/A-3 conformance additionally requires embedded fonts, i.e. the document must
+// be rendered with TTF fonts rather than the PDF core fonts; Embed does not check them.
+func Embed(pdf, xml []byte, profile Profile, meta Metadata) ([]byte, error) {
+	if profile != ProfileMinimum && profile != ProfileEN16931 {
+		return nil, fmt.Errorf("unknown Factur-X profile %q", profile)
+	}
+	ctx, err := pdfcpu.Read(bytes.NewReader(pdf), &model.Configuration{Reader15: true, ValidationMode: model.ValidationRelaxed})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+	if ctx.Encrypt != nil {
+		return nil, fmt.Errorf("encrypted PDFs cannot be converted to PDF/A")
+	}
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF catalog: %w", err)
+	}
+
+	date := meta.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	date = date.Truncate(time.Second)
+
+	w := &pdfWriter{}
+	// %PDF-1.7 plus a binary comment, as PDF/A requires.
+	w.buf.WriteString("%PDF-1.7\n%\xE2\xE3\xCF\xD3\n")
+
+	// Copy the source objects, recording their new offsets.
+	offsets := make(map[int]int64, len(ctx.Table))
+	objNrs := make([]int, 0, len(ctx.Table))
+	for objNr, entry := range ctx.Table {
+		if objNr == 0 || entry == nil || entry.Free {
+			continue
+		}
+		if entry.Compressed || entry.Offset == nil {
+			return nil, fmt.Errorf("PDF object %d is stored in an object stream, which is not supported", objNr)
+		}
+		objNrs = append(objNrs, objNr)
+	}
+	sort.Ints(objNrs)
+	for _, objNr := range objNrs {
+		entry := ctx.Table[objNr]
+		obj, err := sourceObject(pdf, *entry.Offset, entry.Object)
+		if err != nil {
+			return nil, fmt.Errorf("PDF object %d: %w", objNr, err)
+		}
+		offsets[objNr] = int64(w.buf.Len())
+		w.buf.Write(obj)
+	}
+	w.next = *ctx.Size
+	if w.next <= objNrs[len(objNrs)-1] {
+		w.next = objNrs[len(objNrs)-1] + 1
+	}
+	w.offsets = offsets
+
+	// Associated file: the embedded XML stream and its file specification.
+	fileRef := w.addStream(fmt.Sprintf("/Type /EmbeddedFile /Subtype /text#2Fxml /Params << /Size %d /ModDate %s >>",
+		len(xml), pdfString(pdfDate(date))), xml, true)
+	specRef := w.addObject(fmt.Sprintf("<< /Type /Filespec /F %s /UF %s /Desc %s /AFRelationship /%s /EF << /F %s /UF %s >> >>",
+		pdfString(XMLFilename), pdfString(XMLFilename), pdfString("Factur-X invoice"), profile.afRelationship(), fileRef, fileRef))
+	namesRef := w.addObject(fmt.Sprintf("<< /Names [%s %s] >>", pdfString(XMLFilename), specRef))
+
+	xmp, err := buildXMP(meta, producer, date, profile)
+	if err != nil {
+		return nil, err
+	}
+	metadataRef := w.addStream("/Type /Metadata /Subtype /XML", xmp, false)
+	iccRef := w.addStream("/N 3", srgbICCProfile(), true)
+	intentRef := w.addObject(fmt.Sprintf("<< /Type /OutputIntent /S /GTS_PDFA1 /OutputConditionIdentifier %s /Info %s /DestOutputProfile %s >>",
+		pdfString(srgbProfileDescription), pdfString(srgbProfileDescription), iccRef))
+
+	info := []string{"/Title " + pdfString(meta.Title)}
+	if meta.Author != "" {
+		info = append(info, "/Author "+pdfString(meta.Author))
+	}
+	if meta.Subject != "" {
+		info = append(info, "/Subject "+pdfString(meta.Subject))
+	}
+	info = append(info,
+		"/Creator "+pdfString(producer),
+		"/Producer "+pdfString(producer),
+		"/CreationDate "+pdfString(pdfDate(date)),
+		"/ModDate "+pdfString(pdfDate(date)),
+	)
+	infoRef := w.addObject("<< " + strings.Join(info, " ") + " >>")
+
+	// The new catalog keeps the source entries (pages, open action, ...) and replaces the ones
+	// PDF/A and Factur-X define.
+	root := types.NewDict()
+	for key, value := range catalog {
+		switch key {
+		case "Names", "Metadata", "OutputIntents", "AF", "Version":
+			continue
+		}
+		root.Insert(key, value)
+	}
+	names := fmt.Sprintf("/EmbeddedFiles %s", namesRef)
+	if o, found := catalog.Find("Names"); found {
+		if d, err := ctx.DereferenceDict(o); err == nil {
+			for key, value := range d {
+				if key != "EmbeddedFiles" {
+					names += fmt.Sprintf(" /%s %s", key, value.PDFString())
+				}
+			}
+		}
+	}
+	rootEntries := strings.TrimSuffix(strings.TrimPrefix(root.PDFString(), "<<"), ">>")
+	rootRef := w.addObject(fmt.Sprintf("<<%s /Names << %s >> /Metadata %s /OutputIntents [%s] /AF [%s] >>",
+		rootEntries, names, metadataRef, intentRef, specRef))
+
+	w.writeTrailer(rootRef, infoRef, fileID(ctx, pdf))
+	return w.buf.Bytes(), nil
+}
+
+type pdfWriter struct {
+	buf     bytes.Buffer
+	offsets map[int]int64
+	next    int
+}
+
+func (w *pdfWriter) addObject(body string) string {
+	objNr := w.next
+	w.next++
+	w.offsets[objNr] = int64(w.buf.Len())
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", objNr, body)
+	return fmt.Sprintf("%d 0 R", objNr)
+}
+
+func (w *pdfWriter) addStream(dict string, data []byte, compress bool) string {
+	if compress {
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		_, _ = zw.Write(data)
+		_ = zw.Close()
+		data = z.Bytes()
+		dict += " /Filter /FlateDecode"
+	}
+	objNr := w.next
+	w.next++
+	w.offsets[objNr] = int64(w.buf.Len())
+	fmt.Fprintf(&w.buf, "%d 0 obj\n<< %s /Length %d >>\nstream\n", objNr, dict, len(data))
+	w.buf.Write(data)
+	w.buf.WriteString("\nendstream\nendobj\n")
+	return fmt.Sprintf("%d 0 R", objNr)
+}
+
+func (w *pdfWriter) writeTrailer(rootRef, infoRef string, id [2]string) {
+	xrefOffset := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n", w.next)
+	w.buf.WriteString("0000000000 65535 f\r\n")
+	for objNr := 1; objNr < w.next; objNr++ {
+		if offset, ok := w.offsets[objNr]; ok {
+			fmt.Fprintf(&w.buf, "%010d 00000 n\r\n", offset)
+		} else {
+			w.buf.WriteString("0000000000 00000 f\r\n")
+		}
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %s /Info %s /ID [<%s> <%s>] >>\nstartxref\n%d\n%%%%EOF\n",
+		w.next, rootRef, infoRef, id[0], id[1], xrefOffset)
+}
+
+// sourceObject returns the bytes of the indirect object starting at offset, up to and including
+// its "endobj" keyword. The data of a stream, which may contain any bytes including the keywords,
+// is skipped by the stream's /Length as resolved by the reader.
+func sourceObject(pdf []byte, offset int64, obj types.Object) ([]byte, error) {
+	if offset < 0 || offset >= int64(len(pdf)) {
+		return nil, fmt.Errorf("offset %d is out of range", offset)
+	}
+	from := offset
+	if sd, ok := obj.(types.StreamDict); ok {
+		if sd.StreamLength == nil {
+			return nil, fmt.Errorf("stream has no length")
+		}
+		from = sd.StreamOffset + *sd.StreamLength
+		if sd.StreamOffset < offset || from > int64(len(pdf)) {
+			return nil, fmt.Errorf("stream length %d is out of range", *sd.StreamLength)
+		}
+	}
+	end := bytes.Index(pdf[from:], []byte("endobj"))
+	if end < 0 {
+		return nil, fmt.Errorf("missing endobj")
+	}
+	out := append([]byte{}, pdf[offset:from+int64(end)+int64(len("endobj"))]...)
+	return append(out, '\n'), nil
+}
+
+// fileID keeps the permanent identifier of the source document, if any, and derives a new
+// changing identifier from the content.
+func fileID(ctx *model.Context, pdf []byte) [2]string {
+	sum := md5.Sum(pdf)
+	changing := hex.EncodeToString(sum[:])
+	permanent := changing
+	if len(ctx.ID) > 0 {
+		switch id := ctx.ID[0].(type) {
+		case types.HexLiteral:
+			permanent = string(id)
+		case types.StringLiteral:
+			if s, err := types.Unescape(string(id), false); err == nil {
+				permanent = hex.EncodeToString(s)
+			}
+		}
+	}
+	return [2]string{permanent, changing}
+}
+
+// pdfString encodes a text string: a literal for ASCII text, otherwise UTF-16BE with a BOM.
+func pdfString(s string) string {
+	ascii := true
+	for _, r := range s {
+		if r > 0x7E || r < 0x20 {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		r := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`)
+		return "(" + r.Replace(s) + ")"
+	}
+	var buf strings.Builder
+	buf.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&buf, "%04X", u)
+	}
+	buf.WriteString(">")
+	return buf.String()
+}
+
+// pdfDate formats t as a PDF date string, e.g. D:20240210120000+09'00'.
+func pdfDate(t time.Time) string {
+	_, offset := t.Zone()
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	return fmt.Sprintf("D:%s%s%02d'%02d'", t.Format("20060102150405"), sign, offset/3600, offset%3600/60)
+}
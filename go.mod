@@ -9,22 +9,20 @@ require (
 	github.com/f-amaral/go-async v0.3.0 // indirect
 	github.com/google/uuid v1.5.0 // indirect
 	github.com/jung-kurt/gofpdf v1.16.2 // indirect
-	golang.org/x/sys v0.5.0 // indirect
 )
 
 require (
 	github.com/BurntSushi/toml v1.4.0
 	github.com/hhrutter/lzw v1.0.0 // indirect
 	github.com/hhrutter/tiff v1.0.1 // indirect
-	github.com/johnfercher/go-tree v1.0.5 // indirect
+	github.com/johnfercher/go-tree v1.0.5
 	github.com/mattn/go-runewidth v0.0.15 // indirect
 	github.com/nicksnyder/go-i18n/v2 v2.4.0
-	github.com/pdfcpu/pdfcpu v0.6.0 // indirect
+	github.com/pdfcpu/pdfcpu v0.6.0
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/rivo/uniseg v0.4.4 // indirect
 	github.com/shopspring/decimal v1.3.1
-	github.com/sirupsen/logrus v1.9.3
-	golang.org/x/image v0.18.0 // indirect
+	golang.org/x/image v0.18.0
 	golang.org/x/text v0.16.0
 	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,160 @@
+// Package money formats amounts for display: digits grouped and separated the way the document
+// locale writes them, rounded to the currency's ISO 4217 minor units and marked with the
+// currency symbol, e.g. "$500,000.00", "¥550,000" or "500.000,00 €".
+package money
+
+import (
+	"strings"
+
+	"github.com/quailyquaily/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+// Locale describes how a locale writes amounts.
+type Locale struct {
+	Decimal string
+	Group   string
+	// SymbolAfter places the symbol after the number ("500,00 €") instead of before it ("$500.00").
+	SymbolAfter bool
+	// SymbolSpace separates a leading symbol from the number ("€ 500,00"). Trailing symbols and
+	// symbols made of letters, such as "CHF", are always separated.
+	SymbolSpace bool
+	// Symbols overrides the default symbols of some currencies, e.g. "¥" for CNY in Chinese.
+	Symbols map[string]string
+}
+
+var (
+	localeEnglish = Locale{Decimal: ".", Group: ","}
+
+	// locales are keyed by lower-case language or language-region tags.
+	locales = map[string]Locale{
+		"en":    localeEnglish,
+		"ja":    localeEnglish,
+		"zh":    {Decimal: ".", Group: ",", Symbols: map[string]string{"CNY": "¥", "JPY": "JP¥"}},
+		"zh-tw": localeEnglish,
+		"de":    {Decimal: ",", Group: ".", SymbolAfter: true},
+		"de-ch": {Decimal: ".", Group: "'", SymbolSpace: true},
+		"es":    {Decimal: ",", Group: ".", SymbolAfter: true},
+		"fr":    {Decimal: ",", Group: " ", SymbolAfter: true},
+		"it":    {Decimal: ",", Group: ".", SymbolAfter: true},
+		"nl":    {Decimal: ",", Group: ".", SymbolSpace: true},
+		"pt":    {Decimal: ",", Group: ".", SymbolAfter: true},
+		"pt-br": {Decimal: ",", Group: ".", SymbolSpace: true},
+	}
+
+	// symbols only uses characters the PDF core fonts can render; other currencies are shown
+	// with their ISO code.
+	symbols = map[string]string{
+		"USD": "$", "EUR": "€", "GBP": "£", "JPY": "¥", "CNY": "CN¥",
+		"AUD": "A$", "BRL": "R$", "CAD": "CA$", "HKD": "HK$", "MXN": "MX$",
+		"NZD": "NZ$", "SGD": "S$", "TWD": "NT$",
+	}
+)
+
+// LookupLocale resolves a BCP 47 tag such as "de-DE", "pt_BR" or "zh-Hant-TW", first with its
+// region, then by language. Unknown locales format like English.
+func LookupLocale(tag string) Locale {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	parts := strings.Split(tag, "-")
+	if len(parts) > 1 {
+		region := parts[len(parts)-1]
+		if parts[0] == "zh" && (region == "hant" || region == "hk") {
+			region = "tw"
+		}
+		if locale, ok := locales[parts[0]+"-"+region]; ok {
+			return locale
+		}
+	}
+	if locale, ok := locales[parts[0]]; ok {
+		return locale
+	}
+	return localeEnglish
+}
+
+// Symbol returns the symbol of currency in the locale, or its code when it has none.
+func (l Locale) Symbol(currency string) string {
+	code := strings.TrimSpace(currency)
+	if cur, ok := core.LookupCurrency(code); ok {
+		code = cur.Code
+	}
+	if symbol, ok := l.Symbols[code]; ok {
+		return symbol
+	}
+	if symbol, ok := symbols[code]; ok {
+		return symbol
+	}
+	return code
+}
+
+// Format formats amount in currency with the number of decimals given by Decimals.
+func (l Locale) Format(amount decimal.Decimal, currency string) string {
+	return l.FormatDecimals(amount, currency, Decimals(amount, currency))
+}
+
+// FormatDecimals formats amount in currency, rounded to decimals.
+func (l Locale) FormatDecimals(amount decimal.Decimal, currency string, decimals int32) string {
+	number := l.FormatNumber(amount.Abs(), decimals)
+	sign := ""
+	if amount.Round(decimals).IsNegative() {
+		sign = "-"
+	}
+	symbol := l.Symbol(currency)
+	switch {
+	case symbol == "":
+		return sign + number
+	case l.SymbolAfter:
+		return sign + number + " " + symbol
+	case l.SymbolSpace || isLetters(symbol):
+		return sign + symbol + " " + number
+	default:
+		return sign + symbol + number
+	}
+}
+
+// FormatNumber formats n with the locale's separators, rounded to decimals.
+func (l Locale) FormatNumber(n decimal.Decimal, decimals int32) string {
+	s := n.StringFixed(decimals)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	integer, fraction, _ := strings.Cut(s, ".")
+
+	var sb strings.Builder
+	sb.WriteString(sign)
+	for ix, digit := range integer {
+		if ix > 0 && (len(integer)-ix)%3 == 0 {
+			sb.WriteString(l.Group)
+		}
+		sb.WriteRune(digit)
+	}
+	if fraction != "" {
+		sb.WriteString(l.Decimal)
+		sb.WriteString(fraction)
+	}
+	return sb.String()
+}
+
+// Decimals returns the number of decimals amount is shown with in currency: the currency's minor
+// units, or for unknown currencies the amount's significant decimals, between two and eight.
+func Decimals(amount decimal.Decimal, currency string) int32 {
+	if cur, ok := core.LookupCurrency(currency); ok {
+		return cur.MinorUnits
+	}
+	_, fraction, _ := strings.Cut(amount.String(), ".")
+	return min(max(int32(len(fraction)), 2), 8)
+}
+
+// Format formats amount in currency for the locale tag; see Locale.Format.
+func Format(amount decimal.Decimal, currency, locale string) string {
+	return LookupLocale(locale).Format(amount, currency)
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,50 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		locale   string
+		want     string
+	}{
+		{amount: "500000", currency: "USD", locale: "en", want: "$500,000.00"},
+		{amount: "550000", currency: "JPY", locale: "ja", want: "¥550,000"},
+		{amount: "550000.4", currency: "円", locale: "ja", want: "¥550,000"},
+		{amount: "500000", currency: "EUR", locale: "de-DE", want: "500.000,00 €"},
+		{amount: "1234.5", currency: "EUR", locale: "fr_FR", want: "1 234,50 €"},
+		{amount: "1234.5", currency: "EUR", locale: "nl", want: "€ 1.234,50"},
+		{amount: "1234.5", currency: "CHF", locale: "de-CH", want: "CHF 1'234.50"},
+		{amount: "1234.5", currency: "CHF", locale: "en", want: "CHF 1,234.50"},
+		{amount: "-432.5", currency: "EUR", locale: "en", want: "-€432.50"},
+		{amount: "-0.001", currency: "USD", locale: "en", want: "$0.00"},
+		{amount: "88000", currency: "CNY", locale: "zh_cn", want: "¥88,000.00"},
+		{amount: "88000", currency: "JPY", locale: "zh-Hans", want: "JP¥88,000"},
+		{amount: "88000", currency: "CNY", locale: "zh-Hant-TW", want: "CN¥88,000.00"},
+		{amount: "1500", currency: "KRW", locale: "en", want: "KRW 1,500"},
+		{amount: "1.234", currency: "KWD", locale: "en", want: "KWD 1.234"},
+		{amount: "0.5", currency: "USDT", locale: "en", want: "USDT 0.50"},
+		{amount: "0.00012345", currency: "BTC", locale: "de", want: "0,00012345 BTC"},
+		{amount: "999.999", currency: "USD", locale: "xx", want: "$1,000.00"},
+	}
+	for _, tt := range tests {
+		if got := Format(decimal.RequireFromString(tt.amount), tt.currency, tt.locale); got != tt.want {
+			t.Errorf("Format(%s, %s, %s) = %q, want %q", tt.amount, tt.currency, tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	locale := LookupLocale("de")
+	if got := locale.FormatNumber(decimal.RequireFromString("-1234567.891"), 2); got != "-1.234.567,89" {
+		t.Fatalf("FormatNumber = %q", got)
+	}
+	if got := locale.FormatNumber(decimal.NewFromInt(100), 0); got != "100" {
+		t.Fatalf("FormatNumber(100) = %q", got)
+	}
+}
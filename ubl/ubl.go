@@ -0,0 +1,333 @@
+// Package ubl exports invoices as UBL 2.1 Invoice documents following PEPPOL BIS Billing 3.0.
+//
+// The document is generated from an einvoice.Invoice; Validate runs the EN 16931 core rules plus
+// the PEPPOL rules that can be checked offline, without the Schematron artefacts.
+package ubl
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"time"
+
+	"github.com/quailyquaily/bizdocgen/einvoice"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// CustomizationID identifies the PEPPOL BIS Billing 3.0 specification (BT-24).
+	CustomizationID = "urn:cen.eu:en16931:2017#compliant#urn:fdc:peppol.eu:2017:poacc:billing:3.0"
+	// ProfileID identifies the PEPPOL billing business process (BT-23).
+	ProfileID = "urn:fdc:peppol.eu:2017:poacc:billing:01:1.0"
+)
+
+type ublInvoice struct {
+	XMLName         xml.Name `xml:"Invoice"`
+	Xmlns           string   `xml:"xmlns,attr"`
+	XmlnsCAC        string   `xml:"xmlns:cac,attr"`
+	XmlnsCBC        string   `xml:"xmlns:cbc,attr"`
+	CustomizationID string   `xml:"cbc:CustomizationID"`
+	ProfileID       string   `xml:"cbc:ProfileID"`
+	ID              string   `xml:"cbc:ID"`
+	IssueDate       string   `xml:"cbc:IssueDate"`
+	DueDate         string   `xml:"cbc:DueDate,omitempty"`
+	TypeCode        string   `xml:"cbc:InvoiceTypeCode"`
+	Note            string   `xml:"cbc:Note,omitempty"`
+	Currency        string   `xml:"cbc:DocumentCurrencyCode"`
+	BuyerReference  string   `xml:"cbc:BuyerReference,omitempty"`
+
+	Period       *ublPeriod           `xml:"cac:InvoicePeriod,omitempty"`
+	Supplier     ublParty             `xml:"cac:AccountingSupplierParty>cac:Party"`
+	Customer     ublParty             `xml:"cac:AccountingCustomerParty>cac:Party"`
+	PaymentMeans *ublPayment          `xml:"cac:PaymentMeans,omitempty"`
+	PaymentTerms *ublPaymentTerms     `xml:"cac:PaymentTerms,omitempty"`
+	Allowances   []ublAllowanceCharge `xml:"cac:AllowanceCharge,omitempty"`
+	TaxTotal     ublTaxTotal          `xml:"cac:TaxTotal"`
+	Totals       ublMonetaryTotal     `xml:"cac:LegalMonetaryTotal"`
+	Lines        []ublLine            `xml:"cac:InvoiceLine"`
+}
+
+type ublPaymentTerms struct {
+	Note string `xml:"cbc:Note"`
+}
+
+type ublPeriod struct {
+	StartDate string `xml:"cbc:StartDate,omitempty"`
+	EndDate   string `xml:"cbc:EndDate,omitempty"`
+}
+
+type ublParty struct {
+	Endpoint     *ublID        `xml:"cbc:EndpointID,omitempty"`
+	Address      ublAddress    `xml:"cac:PostalAddress"`
+	TaxSchemes   []ublPartyTax `xml:"cac:PartyTaxScheme,omitempty"`
+	Registration string        `xml:"cac:PartyLegalEntity>cbc:RegistrationName"`
+	Contact      *ublContact   `xml:"cac:Contact,omitempty"`
+}
+
+type ublID struct {
+	SchemeID string `xml:"schemeID,attr,omitempty"`
+	Value    string `xml:",chardata"`
+}
+
+type ublAddress struct {
+	StreetName           string          `xml:"cbc:StreetName,omitempty"`
+	AdditionalStreetName string          `xml:"cbc:AdditionalStreetName,omitempty"`
+	AddressLine          *ublAddressLine `xml:"cac:AddressLine,omitempty"`
+	Country              string          `xml:"cac:Country>cbc:IdentificationCode"`
+}
+
+type ublAddressLine struct {
+	Line string `xml:"cbc:Line"`
+}
+
+type ublPartyTax struct {
+	CompanyID string `xml:"cbc:CompanyID"`
+	TaxScheme string `xml:"cac:TaxScheme>cbc:ID"`
+}
+
+type ublContact struct {
+	Email string `xml:"cbc:ElectronicMail"`
+}
+
+type ublPayment struct {
+	Code    ublCode          `xml:"cbc:PaymentMeansCode"`
+	Account *ublPayeeAccount `xml:"cac:PayeeFinancialAccount,omitempty"`
+}
+
+type ublCode struct {
+	Name  string `xml:"name,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type ublPayeeAccount struct {
+	ID   string `xml:"cbc:ID"`
+	Name string `xml:"cbc:Name,omitempty"`
+	BIC  string `xml:"cac:FinancialInstitutionBranch>cbc:ID,omitempty"`
+}
+
+type ublTaxTotal struct {
+	TaxAmount ublAmount        `xml:"cbc:TaxAmount"`
+	Subtotals []ublTaxSubtotal `xml:"cac:TaxSubtotal"`
+}
+
+type ublTaxSubtotal struct {
+	TaxableAmount ublAmount      `xml:"cbc:TaxableAmount"`
+	TaxAmount     ublAmount      `xml:"cbc:TaxAmount"`
+	Category      ublTaxCategory `xml:"cac:TaxCategory"`
+}
+
+type ublTaxCategory struct {
+	ID              string `xml:"cbc:ID"`
+	Percent         string `xml:"cbc:Percent,omitempty"`
+	ExemptionReason string `xml:"cbc:TaxExemptionReason,omitempty"`
+	TaxScheme       string `xml:"cac:TaxScheme>cbc:ID"`
+}
+
+type ublAmount struct {
+	Currency string `xml:"currencyID,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type ublMonetaryTotal struct {
+	LineExtension ublAmount  `xml:"cbc:LineExtensionAmount"`
+	TaxExclusive  ublAmount  `xml:"cbc:TaxExclusiveAmount"`
+	TaxInclusive  ublAmount  `xml:"cbc:TaxInclusiveAmount"`
+	Allowances    *ublAmount `xml:"cbc:AllowanceTotalAmount,omitempty"`
+	Charges       *ublAmount `xml:"cbc:ChargeTotalAmount,omitempty"`
+	Prepaid       *ublAmount `xml:"cbc:PrepaidAmount,omitempty"`
+	Payable       ublAmount  `xml:"cbc:PayableAmount"`
+}
+
+type ublLine struct {
+	ID            string               `xml:"cbc:ID"`
+	Quantity      ublQuantity          `xml:"cbc:InvoicedQuantity"`
+	LineExtension ublAmount            `xml:"cbc:LineExtensionAmount"`
+	Allowances    []ublAllowanceCharge `xml:"cac:AllowanceCharge,omitempty"`
+	Item          ublItem              `xml:"cac:Item"`
+	Price         ublAmount            `xml:"cac:Price>cbc:PriceAmount"`
+}
+
+type ublQuantity struct {
+	UnitCode string `xml:"unitCode,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type ublAllowanceCharge struct {
+	ChargeIndicator bool            `xml:"cbc:ChargeIndicator"`
+	ReasonCode      string          `xml:"cbc:AllowanceChargeReasonCode,omitempty"`
+	Reason          string          `xml:"cbc:AllowanceChargeReason,omitempty"`
+	Amount          ublAmount       `xml:"cbc:Amount"`
+	TaxCategory     *ublTaxCategory `xml:"cac:TaxCategory,omitempty"`
+}
+
+type ublItem struct {
+	Description string         `xml:"cbc:Description,omitempty"`
+	Name        string         `xml:"cbc:Name"`
+	TaxCategory ublTaxCategory `xml:"cac:ClassifiedTaxCategory"`
+}
+
+// Marshal validates inv and returns its UBL 2.1 Invoice XML.
+func Marshal(inv *einvoice.Invoice) ([]byte, error) {
+	if err := Validate(inv); err != nil {
+		return nil, err
+	}
+	return marshalInvoice(inv)
+}
+
+func marshalInvoice(inv *einvoice.Invoice) ([]byte, error) {
+	amount := func(value decimal.Decimal) ublAmount {
+		return ublAmount{Currency: inv.Currency, Value: inv.FormatAmount(value)}
+	}
+
+	doc := ublInvoice{
+		Xmlns:           "urn:oasis:names:specification:ubl:schema:xsd:Invoice-2",
+		XmlnsCAC:        "urn:oasis:names:specification:ubl:schema:xsd:CommonAggregateComponents-2",
+		XmlnsCBC:        "urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2",
+		CustomizationID: CustomizationID,
+		ProfileID:       ProfileID,
+		ID:              inv.ID,
+		IssueDate:       formatDate(inv.IssueDate),
+		DueDate:         formatDate(inv.DueDate),
+		TypeCode:        inv.TypeCode,
+		// PEPPOL allows a single document-level note (PEPPOL-EN16931-R002).
+		Note:           strings.Join(inv.Notes, "\n"),
+		Currency:       inv.Currency,
+		BuyerReference: inv.BuyerReference,
+		Supplier:       ublTradeParty(inv.Seller, true),
+		Customer:       ublTradeParty(inv.Buyer, false),
+		PaymentMeans:   ublPaymentMeans(inv.Payment),
+		TaxTotal:       ublTaxTotal{TaxAmount: amount(inv.Totals.TaxTotal)},
+		Totals: ublMonetaryTotal{
+			LineExtension: amount(inv.Totals.LineTotal),
+			TaxExclusive:  amount(inv.Totals.TaxBasis),
+			TaxInclusive:  amount(inv.Totals.GrandTotal),
+			Payable:       amount(inv.Totals.DuePayable),
+		},
+	}
+	if inv.PaymentTerms != "" {
+		doc.PaymentTerms = &ublPaymentTerms{Note: inv.PaymentTerms}
+	}
+	if !inv.PeriodStart.IsZero() || !inv.PeriodEnd.IsZero() {
+		doc.Period = &ublPeriod{StartDate: formatDate(inv.PeriodStart), EndDate: formatDate(inv.PeriodEnd)}
+	}
+	if !inv.Totals.Prepaid.IsZero() {
+		prepaid := amount(inv.Totals.Prepaid)
+		doc.Totals.Prepaid = &prepaid
+	}
+	if len(inv.AllowanceCharges) > 0 {
+		allowances, charges := amount(inv.Totals.AllowanceTotal), amount(inv.Totals.ChargeTotal)
+		doc.Totals.Allowances, doc.Totals.Charges = &allowances, &charges
+	}
+	for _, ac := range inv.AllowanceCharges {
+		category := newTaxCategory(ac.TaxCategory, ac.TaxRate, "")
+		doc.Allowances = append(doc.Allowances, ublAllowanceCharge{
+			ChargeIndicator: ac.Charge,
+			Reason:          ac.Reason,
+			Amount:          amount(ac.Amount),
+			TaxCategory:     &category,
+		})
+	}
+	for _, group := range inv.TaxBreakdown {
+		doc.TaxTotal.Subtotals = append(doc.TaxTotal.Subtotals, ublTaxSubtotal{
+			TaxableAmount: amount(group.Base),
+			TaxAmount:     amount(group.Tax),
+			Category:      newTaxCategory(group.Category, group.Rate, group.ExemptionReason),
+		})
+	}
+	for _, line := range inv.Lines {
+		l := ublLine{
+			ID:            line.ID,
+			Quantity:      ublQuantity{UnitCode: line.UnitCode, Value: line.Quantity.String()},
+			LineExtension: amount(line.NetAmount),
+			Item: ublItem{
+				Description: line.Description,
+				Name:        line.Name,
+				TaxCategory: newTaxCategory(line.TaxCategory, line.TaxRate, ""),
+			},
+			Price: ublAmount{Currency: inv.Currency, Value: line.NetPrice.String()},
+		}
+		if line.Allowance.GreaterThan(decimal.Zero) {
+			l.Allowances = []ublAllowanceCharge{{
+				ChargeIndicator: false,
+				ReasonCode:      "95", // UNTDID 5189: discount
+				Amount:          amount(line.Allowance),
+			}}
+		}
+		if line.Charge.GreaterThan(decimal.Zero) {
+			l.Allowances = append(l.Allowances, ublAllowanceCharge{
+				ChargeIndicator: true,
+				Reason:          line.ChargeReason,
+				Amount:          amount(line.Charge),
+			})
+		}
+		doc.Lines = append(doc.Lines, l)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+func ublTradeParty(party einvoice.Party, seller bool) ublParty {
+	p := ublParty{Registration: party.Name}
+	if party.EndpointID != "" {
+		p.Endpoint = &ublID{SchemeID: party.EndpointScheme, Value: party.EndpointID}
+	}
+	p.Address = ublAddress{Country: party.Address.CountryCode}
+	lines := party.Address.Lines
+	if len(lines) > 0 {
+		p.Address.StreetName = lines[0]
+	}
+	if len(lines) > 1 {
+		p.Address.AdditionalStreetName = lines[1]
+	}
+	// UBL has a single address line element (BT-162); further lines are folded into it.
+	if len(lines) > 2 {
+		p.Address.AddressLine = &ublAddressLine{Line: strings.Join(lines[2:], ", ")}
+	}
+	if party.VATID != "" {
+		p.TaxSchemes = append(p.TaxSchemes, ublPartyTax{CompanyID: party.VATID, TaxScheme: "VAT"})
+	}
+	// Only the seller has a tax registration identifier besides the VAT ID (BT-32).
+	if seller && party.TaxRegistrationID != "" {
+		p.TaxSchemes = append(p.TaxSchemes, ublPartyTax{CompanyID: party.TaxRegistrationID, TaxScheme: "TAX"})
+	}
+	if party.Email != "" {
+		p.Contact = &ublContact{Email: party.Email}
+	}
+	return p
+}
+
+func ublPaymentMeans(means *einvoice.PaymentMeans) *ublPayment {
+	if means == nil {
+		return nil
+	}
+	pm := &ublPayment{Code: ublCode{Name: means.Information, Value: means.TypeCode}}
+	if means.AccountID != "" {
+		pm.Account = &ublPayeeAccount{ID: means.AccountID, Name: means.AccountName, BIC: means.BIC}
+	}
+	return pm
+}
+
+// newTaxCategory builds a VAT category. Outside the scope of VAT ("O") carries no rate.
+func newTaxCategory(category string, rate decimal.Decimal, exemptionReason string) ublTaxCategory {
+	c := ublTaxCategory{ID: category, ExemptionReason: exemptionReason, TaxScheme: "VAT"}
+	if category != "O" {
+		c.Percent = einvoice.FormatPercent(rate)
+	}
+	return c
+}
+
+// formatDate renders an ISO 8601 calendar date; the zero time yields "" so the element is omitted.
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
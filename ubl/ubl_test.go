@@ -0,0 +1,162 @@
+package ubl
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/quailyquaily/bizdocgen/einvoice"
+	"github.com/shopspring/decimal"
+)
+
+func sampleInvoice() *einvoice.Invoice {
+	d := decimal.RequireFromString
+	return &einvoice.Invoice{
+		ID:             "INV-1",
+		IssueDate:      time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC),
+		TypeCode:       einvoice.TypeCodeInvoice,
+		Currency:       "EUR",
+		BuyerReference: "PO-1",
+		Notes:          []string{"Development", "Thank you"},
+		Seller: einvoice.Party{
+			Name:           "ABC GmbH",
+			Address:        einvoice.Address{Lines: []string{"Friedrichstraße 123", "10117 Berlin"}, CountryCode: "DE"},
+			VATID:          "DE123456789",
+			EndpointScheme: "0088",
+			EndpointID:     "4035811991014",
+		},
+		Buyer: einvoice.Party{
+			Name:           "XYZ SARL",
+			Address:        einvoice.Address{Lines: []string{"12 Rue de Rivoli", "75001 Paris", "Bâtiment B", "3e étage"}, CountryCode: "FR"},
+			EndpointScheme: "EM",
+			EndpointID:     "ap@xyz.example",
+		},
+		Payment: &einvoice.PaymentMeans{TypeCode: einvoice.PaymentMeansSEPACreditTransfer, AccountID: "DE89370400440532013000"},
+		Lines: []einvoice.Line{
+			{ID: "1", Name: "Development", Quantity: d("40"), UnitCode: "HUR", NetPrice: d("95"), NetAmount: d("3800"), TaxCategory: "S", TaxRate: d("0.19")},
+			{ID: "2", Name: "License", Quantity: d("2"), UnitCode: "C62", NetPrice: d("450"), Allowance: d("100"), NetAmount: d("800"), TaxCategory: "S", TaxRate: d("0.19")},
+		},
+		TaxBreakdown: []einvoice.TaxSubtotal{{Category: "S", Rate: d("0.19"), Base: d("4600"), Tax: d("874")}},
+		Totals: einvoice.Totals{
+			LineTotal:  d("4600"),
+			TaxBasis:   d("4600"),
+			TaxTotal:   d("874"),
+			GrandTotal: d("5474"),
+			DuePayable: d("5474"),
+		},
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	out, err := Marshal(sampleInvoice())
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var doc struct {
+		XMLName xml.Name
+		Notes   []string `xml:"Note"`
+		Lines   []struct {
+			ID string `xml:"ID"`
+		} `xml:"InvoiceLine"`
+	}
+	if err := xml.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("XML is not well-formed: %v", err)
+	}
+	if doc.XMLName.Space != "urn:oasis:names:specification:ubl:schema:xsd:Invoice-2" || doc.XMLName.Local != "Invoice" {
+		t.Fatalf("root = %v", doc.XMLName)
+	}
+	if len(doc.Notes) != 1 || len(doc.Lines) != 2 {
+		t.Fatalf("notes = %q, lines = %d", doc.Notes, len(doc.Lines))
+	}
+	for _, want := range []string{
+		"<cbc:CustomizationID>" + CustomizationID + "</cbc:CustomizationID>",
+		"<cbc:IssueDate>2024-04-15</cbc:IssueDate>",
+		`<cbc:EndpointID schemeID="0088">4035811991014</cbc:EndpointID>`,
+		"<cbc:Line>Bâtiment B, 3e étage</cbc:Line>",
+		`<cbc:TaxAmount currencyID="EUR">874.00</cbc:TaxAmount>`,
+		"<cbc:PaymentMeansCode>58</cbc:PaymentMeansCode>",
+		`<cbc:InvoicedQuantity unitCode="HUR">40</cbc:InvoicedQuantity>`,
+		`<cbc:PayableAmount currencyID="EUR">5474.00</cbc:PayableAmount>`,
+	} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Errorf("XML lacks %s", want)
+		}
+	}
+}
+
+func TestMarshalAllowancesAndCharges(t *testing.T) {
+	d := decimal.RequireFromString
+	inv := sampleInvoice()
+	inv.Lines[0].Charge, inv.Lines[0].ChargeReason, inv.Lines[0].NetAmount = d("50"), "Travel", d("3850")
+	inv.AllowanceCharges = []einvoice.AllowanceCharge{
+		{Amount: d("385"), Reason: "Loyalty discount (10%)", TaxCategory: "S", TaxRate: d("0.19")},
+		{Charge: true, Amount: d("35"), Reason: "Shipping", TaxCategory: "S", TaxRate: d("0.19")},
+	}
+	inv.TaxBreakdown[0].Base, inv.TaxBreakdown[0].Tax = d("4300"), d("817")
+	inv.Totals = einvoice.Totals{
+		LineTotal:      d("4650"),
+		AllowanceTotal: d("385"),
+		ChargeTotal:    d("35"),
+		TaxBasis:       d("4300"),
+		TaxTotal:       d("817"),
+		GrandTotal:     d("5117"),
+		DuePayable:     d("5117"),
+	}
+	out, err := Marshal(inv)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	for _, want := range []string{
+		"<cbc:AllowanceChargeReason>Loyalty discount (10%)</cbc:AllowanceChargeReason>",
+		`<cbc:AllowanceTotalAmount currencyID="EUR">385.00</cbc:AllowanceTotalAmount>`,
+		`<cbc:ChargeTotalAmount currencyID="EUR">35.00</cbc:ChargeTotalAmount>`,
+		"<cbc:AllowanceChargeReason>Travel</cbc:AllowanceChargeReason>",
+	} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Errorf("XML lacks %s", want)
+		}
+	}
+
+	inv.Totals.AllowanceTotal = d("300")
+	var errs einvoice.RuleErrors
+	if _, err := Marshal(inv); !errors.As(err, &errs) {
+		t.Fatalf("Marshal accepted a wrong allowance total: %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*einvoice.Invoice)
+		rule   string
+	}{
+		{"EN 16931 rule", func(inv *einvoice.Invoice) { inv.Seller.Address.CountryCode = "" }, "BR-9"},
+		{"type code", func(inv *einvoice.Invoice) { inv.TypeCode = "999" }, "PEPPOL-EN16931-P0100"},
+		{"buyer reference", func(inv *einvoice.Invoice) { inv.BuyerReference = "" }, "PEPPOL-EN16931-R003"},
+		{"buyer endpoint", func(inv *einvoice.Invoice) { inv.Buyer.EndpointID = "" }, "PEPPOL-EN16931-R010"},
+		{"seller endpoint", func(inv *einvoice.Invoice) { inv.Seller.EndpointID = "" }, "PEPPOL-EN16931-R020"},
+		{"endpoint scheme", func(inv *einvoice.Invoice) { inv.Buyer.EndpointScheme = "XX" }, "PEPPOL-EN16931-CL008"},
+		{"GLN check digit", func(inv *einvoice.Invoice) { inv.Seller.EndpointID = "4035811991015" }, "PEPPOL-COMMON-R040"},
+		{"line calculation", func(inv *einvoice.Invoice) { inv.Lines[1].NetPrice = decimal.NewFromInt(400) }, "PEPPOL-EN16931-R120"},
+		{"credit transfer account", func(inv *einvoice.Invoice) { inv.Payment.AccountID = "" }, "BR-61"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := sampleInvoice()
+			tt.modify(inv)
+			err := Validate(inv)
+			var errs einvoice.RuleErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("Validate() = %v, want RuleErrors", err)
+			}
+			for _, ruleErr := range errs {
+				if ruleErr.Rule == tt.rule {
+					return
+				}
+			}
+			t.Fatalf("Validate() = %v, want %s", err, tt.rule)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package ubl
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/quailyquaily/bizdocgen/einvoice"
+	"github.com/shopspring/decimal"
+)
+
+// invoiceTypeCodes are the UNTDID 1001 codes PEPPOL accepts for invoices (PEPPOL-EN16931-P0100).
+var invoiceTypeCodes = map[string]bool{
+	"71": true, "80": true, "82": true, "84": true, "102": true, "218": true, "219": true, "331": true,
+	"380": true, "382": true, "383": true, "386": true, "388": true, "393": true, "395": true, "553": true,
+	"575": true, "623": true, "780": true, "817": true, "870": true, "875": true, "876": true, "877": true,
+}
+
+// easCodes is the Electronic Address Scheme code list used for endpoint identifiers (PEPPOL-EN16931-CL008).
+var easCodes = map[string]bool{}
+
+func init() {
+	for _, code := range strings.Fields(`
+		0002 0007 0009 0037 0060 0088 0096 0097 0106 0130 0135 0142 0147 0151 0170 0183 0184 0188
+		0190 0191 0192 0193 0194 0195 0196 0198 0199 0200 0201 0202 0203 0204 0205 0208 0209 0210
+		0211 0212 0213 0215 0216 0217 0218 0221 0225 0230 0235 0240
+		9901 9910 9913 9914 9915 9918 9919 9920 9922 9923 9924 9925 9926 9927 9928 9929 9930 9931
+		9932 9933 9934 9935 9936 9937 9938 9939 9940 9941 9942 9943 9944 9945 9946 9947 9948 9949
+		9950 9951 9952 9953 9957 9959
+		AN AQ AS AU EM`) {
+		easCodes[code] = true
+	}
+}
+
+// Validate checks inv against the EN 16931 core rules (einvoice.Invoice.Validate) and the PEPPOL BIS
+// Billing 3.0 rules that can be decided from the data alone: references, electronic addresses, code
+// lists, identifier check digits and line calculations. It returns nil or einvoice.RuleErrors.
+func Validate(inv *einvoice.Invoice) error {
+	var errs einvoice.RuleErrors
+	if err := inv.Validate(); err != nil && !errors.As(err, &errs) {
+		return err
+	}
+	add := func(rule, format string, args ...any) {
+		errs = append(errs, einvoice.RuleError{Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if !invoiceTypeCodes[inv.TypeCode] {
+		add("PEPPOL-EN16931-P0100", "invoice type code %q is not allowed", inv.TypeCode)
+	}
+	if strings.TrimSpace(inv.BuyerReference) == "" {
+		add("PEPPOL-EN16931-R003", "a buyer reference (BT-10) is required")
+	}
+	for _, p := range []struct {
+		party einvoice.Party
+		rule  string
+		term  string
+	}{
+		{inv.Seller, "PEPPOL-EN16931-R020", "seller electronic address (BT-34)"},
+		{inv.Buyer, "PEPPOL-EN16931-R010", "buyer electronic address (BT-49)"},
+	} {
+		if p.party.EndpointID == "" {
+			add(p.rule, "%s is required", p.term)
+			continue
+		}
+		if !easCodes[p.party.EndpointScheme] {
+			add("PEPPOL-EN16931-CL008", "%s scheme %q is not an EAS code", p.term, p.party.EndpointScheme)
+		}
+		if p.party.EndpointScheme == "0088" && !validGLN(p.party.EndpointID) {
+			add("PEPPOL-COMMON-R040", "%s %q is not a valid GLN", p.term, p.party.EndpointID)
+		}
+	}
+
+	for ix, line := range inv.Lines {
+		// Line net amount = quantity × net price − allowances + charges, stated with the invoice's decimals.
+		calculated := line.Quantity.Mul(line.NetPrice).Sub(line.Allowance).Add(line.Charge)
+		if !roundAmount(inv, calculated).Equal(roundAmount(inv, line.NetAmount)) {
+			add("PEPPOL-EN16931-R120", "line %d: net amount %s differs from quantity × price − allowances + charges %s",
+				ix+1, inv.FormatAmount(line.NetAmount), inv.FormatAmount(calculated))
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func roundAmount(inv *einvoice.Invoice, amount decimal.Decimal) decimal.Decimal {
+	return amount.Round(inv.Decimals())
+}
+
+// validGLN checks the length and GS1 mod-10 check digit of a Global Location Number.
+func validGLN(gln string) bool {
+	if len(gln) != 13 {
+		return false
+	}
+	sum := 0
+	for ix := 0; ix < 12; ix++ {
+		d := int(gln[ix] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		if ix%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return int(gln[12]-'0') == (10-sum%10)%10
+}